@@ -11,6 +11,7 @@ import (
 	scoreWorker "github.com/bitmark-inc/autonomy-api/background/score"
 	"github.com/bitmark-inc/autonomy-api/schema"
 	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
 // accountRegister is the API for register a new account
@@ -141,16 +142,24 @@ func (s *Server) getProfileFormula(c *gin.Context) {
 		return
 	}
 
+	behaviors, err := s.mongoStore.ListOfficialBehavior(lang)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	formula := score.AccountFormula(accountNumber)
+
 	if coefficient == nil {
 		isDefaultFormula = true
-		coefficient = &schema.ScoreCoefficient{
-			Symptoms:       score.DefaultScoreV1SymptomCoefficient,
-			Behaviors:      score.DefaultScoreV1BehaviorCoefficient,
-			Confirms:       score.DefaultScoreV1ConfirmCoefficient,
-			SymptomWeights: schema.DefaultSymptomWeights,
-		}
+		defaultCoefficient := formula.DefaultCoefficient()
+		coefficient = &defaultCoefficient
 	}
 
+	// customized weights are applied over the default weights
+	symptomWeights := score.MergeSymptomWeights(coefficient.SymptomWeights)
+	behaviorWeights := score.MergeBehaviorWeights(coefficient.BehaviorWeights)
+
 	type SymptomWeightsRepresentation struct {
 		Symptom schema.Symptom `json:"symptom"`
 		Weight  float64        `json:"weight"`
@@ -159,7 +168,7 @@ func (s *Server) getProfileFormula(c *gin.Context) {
 	SymptomWeightsRepresentationList := make([]SymptomWeightsRepresentation, 0)
 
 	for _, s := range symptoms {
-		if weight, ok := coefficient.SymptomWeights[s.ID]; ok {
+		if weight, ok := symptomWeights[s.ID]; ok {
 			SymptomWeightsRepresentationList = append(SymptomWeightsRepresentationList, SymptomWeightsRepresentation{
 				Symptom: s,
 				Weight:  weight,
@@ -168,13 +177,32 @@ func (s *Server) getProfileFormula(c *gin.Context) {
 
 	}
 
+	type BehaviorWeightsRepresentation struct {
+		Behavior schema.Behavior `json:"behavior"`
+		Weight   float64         `json:"weight"`
+	}
+
+	BehaviorWeightsRepresentationList := make([]BehaviorWeightsRepresentation, 0)
+
+	for _, b := range behaviors {
+		if weight, ok := behaviorWeights[string(b.ID)]; ok {
+			BehaviorWeightsRepresentationList = append(BehaviorWeightsRepresentationList, BehaviorWeightsRepresentation{
+				Behavior: b,
+				Weight:   weight,
+			})
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"is_default": isDefaultFormula,
+		"version":    formula.Version(),
 		"coefficient": map[string]interface{}{
-			"symptoms":        coefficient.Symptoms,
-			"behaviors":       coefficient.Behaviors,
-			"confirms":        coefficient.Confirms,
-			"symptom_weights": SymptomWeightsRepresentationList,
+			"symptoms":         coefficient.Symptoms,
+			"behaviors":        coefficient.Behaviors,
+			"confirms":         coefficient.Confirms,
+			"air_quality":      coefficient.AirQuality,
+			"symptom_weights":  SymptomWeightsRepresentationList,
+			"behavior_weights": BehaviorWeightsRepresentationList,
 		},
 	})
 }
@@ -192,10 +220,13 @@ func (s *Server) updateProfileFormula(c *gin.Context) {
 		return
 	}
 
-	params.Coefficient.UpdatedAt = time.Now().UTC()
+	if err := validateSymptomWeights(params.Coefficient.SymptomWeights); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
 
-	if err := s.mongoStore.UpdateProfileCoefficient(accountNumber, params.Coefficient); err != nil {
-		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+	if err := validateBehaviorWeights(params.Coefficient.BehaviorWeights); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
 		return
 	}
 
@@ -205,75 +236,162 @@ func (s *Server) updateProfileFormula(c *gin.Context) {
 		return
 	}
 
-	profile.Metric.Score = score.TotalScoreV1(params.Coefficient,
-		profile.Metric.Details.Symptoms.Score,
-		profile.Metric.Details.Behaviors.Score,
-		profile.Metric.Details.Confirm.Score,
-	)
+	params.Coefficient.UpdatedAt = time.Now().UTC()
 
-	if err := s.mongoStore.UpdateProfileMetric(accountNumber, profile.Metric); err != nil {
+	if err := s.mongoStore.UpdateProfileCoefficient(accountNumber, params.Coefficient); err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 		return
 	}
 
-	for _, profilePOI := range profile.PointsOfInterest {
-		poi, err := s.mongoStore.GetPOI(profilePOI.ID)
-		if err != nil {
-			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
-			return
+	if err := s.refreshProfileMetrics(profile, &params.Coefficient); err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": "OK"})
+}
+
+// refreshProfileMetrics brings the metrics of an account and its POIs up to date with a new coefficient.
+// The raw data of a metric is not saved along with it, so the metrics are synced from the collected data
+// again if the symptom or behavior weights change. Otherwise, only the total scores are recalculated.
+func (s *Server) refreshProfileMetrics(profile *schema.Profile, coefficient *schema.ScoreCoefficient) error {
+	formula := score.AccountFormula(profile.AccountNumber)
+	resync := scoreWeightsChanged(formula, profile.ScoreCoefficient, coefficient)
+
+	if resync && profile.Location != nil {
+		location := schema.Location{
+			Latitude:  profile.Location.Coordinates[1],
+			Longitude: profile.Location.Coordinates[0],
+		}
+		tz := utils.GetLocalLocation(profile.Timezone, location.Longitude)
+		if _, err := s.mongoStore.SyncAccountMetrics(profile.AccountNumber, coefficient, location, tz); err != nil {
+			return err
 		}
+	} else {
+		metric := profile.Metric
+		metric.Score = formula.TotalScore(coefficient, metric)
+		if err := s.mongoStore.UpdateProfileMetric(profile.AccountNumber, metric); err != nil {
+			return err
+		}
+	}
 
-		metric := poi.Metric
-		metric.Score = score.TotalScoreV1(params.Coefficient, metric.Details.Symptoms.Score, metric.Details.Behaviors.Score, metric.Details.Confirm.Score)
+	for _, poi := range profile.PointsOfInterest {
+		if resync {
+			if _, err := s.mongoStore.SyncAccountPOIMetrics(profile.AccountNumber, coefficient, poi.ID); err != nil {
+				return err
+			}
+			continue
+		}
 
+		metric := poi.Metric
+		metric.Score = formula.TotalScore(coefficient, metric)
 		if err := s.mongoStore.UpdateProfilePOIMetric(profile.AccountNumber, poi.ID, metric); err != nil {
-			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
-			return
+			return err
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"result": "OK"})
+	return nil
+}
+
+// scoreWeightsChanged tells if the symptom or behavior weights of two coefficients differ,
+// where no coefficient means the default coefficient of the formula
+func scoreWeightsChanged(formula score.ScoreFormula, previous, current *schema.ScoreCoefficient) bool {
+	defaultCoefficient := formula.DefaultCoefficient()
+	if previous == nil {
+		previous = &defaultCoefficient
+	}
+	if current == nil {
+		current = &defaultCoefficient
+	}
+
+	return !sameWeights(score.MergeSymptomWeights(previous.SymptomWeights), score.MergeSymptomWeights(current.SymptomWeights)) ||
+		!sameWeights(score.MergeBehaviorWeights(previous.BehaviorWeights), score.MergeBehaviorWeights(current.BehaviorWeights))
+}
+
+// sameWeights compares two sets of weights
+func sameWeights(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSymptomWeights checks that customized weights are given to official symptoms
+// only and are not negative. Empty weights are valid and mean the default weights.
+func validateSymptomWeights(weights schema.SymptomWeights) error {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	for symptomID, weight := range weights {
+		if !schema.OfficialSymptoms[symptomID] {
+			return fmt.Errorf("unknown symptom: %s", symptomID)
+		}
+		if weight < 0 {
+			return fmt.Errorf("negative weight for symptom: %s", symptomID)
+		}
+	}
+
+	// weights left out keep their defaults, so the merged weights are checked
+	totalWeight := float64(0)
+	for _, weight := range score.MergeSymptomWeights(weights) {
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("symptom weights are all zero")
+	}
+
+	return nil
+}
+
+// validateBehaviorWeights checks that customized weights are given to official behaviors
+// only and are not negative. Empty weights are valid and mean the default weights.
+func validateBehaviorWeights(weights schema.BehaviorWeights) error {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	totalWeight := float64(0)
+	for behaviorID, weight := range weights {
+		if _, ok := schema.OfficialBehaviorMatrix[schema.GoodBehaviorType(behaviorID)]; !ok {
+			return fmt.Errorf("unknown behavior: %s", behaviorID)
+		}
+		if weight < 0 {
+			return fmt.Errorf("negative weight for behavior: %s", behaviorID)
+		}
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return fmt.Errorf("behavior weights are all zero")
+	}
+
+	return nil
 }
 
 // resetProfileFormula cleans up existing customized formula for a user
 func (s *Server) resetProfileFormula(c *gin.Context) {
 	accountNumber := c.GetString("requester")
 
-	if err := s.mongoStore.ResetProfileCoefficient(accountNumber); err != nil {
-		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
-		return
-	}
-
 	profile, err := s.mongoStore.GetProfile(accountNumber)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 		return
 	}
 
-	profile.Metric.Score = score.DefaultTotalScore(
-		profile.Metric.Details.Symptoms.Score,
-		profile.Metric.Details.Behaviors.Score,
-		profile.Metric.Details.Confirm.Score,
-	)
-
-	if err := s.mongoStore.UpdateProfileMetric(accountNumber, profile.Metric); err != nil {
+	if err := s.mongoStore.ResetProfileCoefficient(accountNumber); err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 		return
 	}
 
-	for _, profilePOI := range profile.PointsOfInterest {
-		poi, err := s.mongoStore.GetPOI(profilePOI.ID)
-		if err != nil {
-			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
-			return
-		}
-
-		metric := poi.Metric
-
-		if err := s.mongoStore.UpdateProfilePOIMetric(profile.AccountNumber, poi.ID, metric); err != nil {
-			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
-			return
-		}
+	if err := s.refreshProfileMetrics(profile, nil); err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"result": "OK"})
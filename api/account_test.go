@@ -0,0 +1,104 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+)
+
+func TestScoreWeightsChanged(t *testing.T) {
+	formula := score.DefaultFormula()
+	defaultCoefficient := formula.DefaultCoefficient()
+
+	partialDefault := defaultCoefficient
+	partialDefault.BehaviorWeights = schema.BehaviorWeights{}
+	for behaviorID, w := range schema.DefaultBehaviorWeights {
+		partialDefault.BehaviorWeights[behaviorID] = w
+		break
+	}
+
+	behaviorChanged := defaultCoefficient
+	behaviorChanged.BehaviorWeights = schema.BehaviorWeights{}
+	for behaviorID, w := range schema.DefaultBehaviorWeights {
+		behaviorChanged.BehaviorWeights[behaviorID] = w + 1
+		break
+	}
+
+	onlyCoefficients := defaultCoefficient
+	onlyCoefficients.Symptoms, onlyCoefficients.Behaviors, onlyCoefficients.Confirms = 0.5, 0.3, 0.2
+
+	testCases := []struct {
+		name     string
+		previous *schema.ScoreCoefficient
+		current  *schema.ScoreCoefficient
+		changed  bool
+	}{
+		{"both default", nil, nil, false},
+		{"only coefficients", nil, &onlyCoefficients, false},
+		{"default behavior weights given partially", &defaultCoefficient, &partialDefault, false},
+		{"behavior weight changed", nil, &behaviorChanged, true},
+		{"behavior weight reset", &behaviorChanged, nil, true},
+		{"symptom weight changed", nil, &schema.ScoreCoefficient{SymptomWeights: schema.SymptomWeights{"fever": 10}}, true},
+		{"default symptom weight given partially", nil, &schema.ScoreCoefficient{SymptomWeights: schema.SymptomWeights{"fever": 3}}, false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.changed, scoreWeightsChanged(formula, tc.previous, tc.current), tc.name)
+	}
+}
+
+func TestValidateSymptomWeights(t *testing.T) {
+	allZero := schema.SymptomWeights{}
+	for symptomID := range schema.DefaultSymptomWeights {
+		allZero[symptomID] = 0
+	}
+
+	testCases := []struct {
+		name    string
+		weights schema.SymptomWeights
+		valid   bool
+	}{
+		{"default weights", nil, true},
+		{"partial weights", schema.SymptomWeights{"fever": 5}, true},
+		{"a symptom ignored", schema.SymptomWeights{"cough": 0}, true},
+		{"unknown symptom", schema.SymptomWeights{"sneeze": 1}, false},
+		{"suggested symptom", schema.SymptomWeights{"suggestion_42": 1}, false},
+		{"negative weight", schema.SymptomWeights{"fever": -1}, false},
+		{"all zero", allZero, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateSymptomWeights(tc.weights)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
+
+func TestValidateBehaviorWeights(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights schema.BehaviorWeights
+		valid   bool
+	}{
+		{"default weights", nil, true},
+		{"partial weights", schema.BehaviorWeights{"clean_hand": 3}, true},
+		{"unknown behavior", schema.BehaviorWeights{"sing": 1}, false},
+		{"negative weight", schema.BehaviorWeights{"clean_hand": -1}, false},
+		{"all zero", schema.BehaviorWeights{"clean_hand": 0}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateBehaviorWeights(tc.weights)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
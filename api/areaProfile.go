@@ -9,6 +9,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
+	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
 const (
@@ -37,7 +40,11 @@ func (s *Server) singleAreaProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, metric)
+	guarded := store.GetPrivacyGuard().Metric(*metric)
+	band := score.GetScoreBand(guarded.Score)
+	guarded.Band = &band
+
+	c.JSON(http.StatusOK, guarded)
 }
 
 func (s *Server) currentAreaProfile(c *gin.Context) {
@@ -64,18 +71,22 @@ func (s *Server) currentAreaProfile(c *gin.Context) {
 		// FIXME: return cached result directly if possible; otherwise get coefficient and run SyncAccountMetrics
 
 		metricLastUpdate := time.Unix(metric.LastUpdate, 0)
-		var coefficient *schema.ScoreCoefficient
+		coefficient := profile.ScoreCoefficient
 
 		if time.Since(metricLastUpdate) >= metricUpdateInterval {
-			// will sync with coefficient = nil
-		} else if coefficient = profile.ScoreCoefficient; coefficient != nil && coefficient.UpdatedAt.Sub(metricLastUpdate) > 0 {
+			// will sync with coefficient = profile.ScoreCoefficient since the metric is outdated
+		} else if coefficient != nil && coefficient.UpdatedAt.Sub(metricLastUpdate) > 0 {
 			// will sync with coefficient = profile.ScoreCoefficient
 		} else {
+			metric = store.GetPrivacyGuard().Metric(metric)
+			band := score.GetScoreBand(metric.Score)
+			metric.Band = &band
 			c.JSON(http.StatusOK, metric)
 			return
 		}
 
-		m, err := s.mongoStore.SyncAccountMetrics(account.AccountNumber, coefficient, location)
+		tz := utils.GetLocalLocation(profile.Timezone, location.Longitude)
+		m, err := s.mongoStore.SyncAccountMetrics(account.AccountNumber, coefficient, location, tz)
 		if err != nil {
 			c.Error(err)
 			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
@@ -85,5 +96,213 @@ func (s *Server) currentAreaProfile(c *gin.Context) {
 		}
 	}
 
+	metric = store.GetPrivacyGuard().Metric(metric)
+	band := score.GetScoreBand(metric.Score)
+	metric.Band = &band
+
 	c.JSON(http.StatusOK, metric)
 }
+
+const (
+	defaultMetricHistoryPeriod = 30 * 24 * time.Hour
+)
+
+// areaProfileHistory returns scores of a POI over time which are averaged by the granularity
+func (s *Server) areaProfileHistory(c *gin.Context) {
+	accountNumber := c.GetString("requester")
+
+	poiID, err := primitive.ObjectIDFromHex(c.Param("poiID"))
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid POI ID"))
+		return
+	}
+
+	var params struct {
+		From        int64  `form:"from"`
+		To          int64  `form:"to"`
+		Granularity string `form:"granularity"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	to := time.Now().UTC()
+	if params.To > 0 {
+		to = time.Unix(params.To, 0).UTC()
+	}
+
+	from := to.Add(-defaultMetricHistoryPeriod)
+	if params.From > 0 {
+		from = time.Unix(params.From, 0).UTC()
+	}
+
+	if !from.Before(to) {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("from is not earlier than to"))
+		return
+	}
+
+	granularity := schema.MetricHistoryGranularityDay
+	if params.Granularity != "" {
+		granularity = schema.MetricHistoryGranularity(params.Granularity)
+	}
+
+	if granularity != schema.MetricHistoryGranularityDay && granularity != schema.MetricHistoryGranularityHour {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid granularity"))
+		return
+	}
+
+	poi, err := s.mongoStore.GetPOI(poiID)
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI, err)
+		return
+	}
+
+	// days start at the local midnight of the POI
+	tz := utils.GetLocalLocation("", poi.Location.Coordinates[0])
+	history, err := s.mongoStore.GetAccountPOIMetricHistory(accountNumber, poiID, from, to, granularity, tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"granularity": granularity,
+		"history":     history,
+	})
+}
+
+// areaProfileExplain breaks down the score of a POI into what drives it
+func (s *Server) areaProfileExplain(c *gin.Context) {
+	accountNumber := c.GetString("requester")
+
+	poiID, err := primitive.ObjectIDFromHex(c.Param("poiID"))
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid POI ID"))
+		return
+	}
+
+	profile, err := s.mongoStore.GetProfile(accountNumber)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	followed := false
+	for _, p := range profile.PointsOfInterest {
+		if p.ID == poiID {
+			followed = true
+			break
+		}
+	}
+
+	if !followed {
+		abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI)
+		return
+	}
+
+	poi, err := s.mongoStore.GetPOI(poiID)
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI, err)
+		return
+	}
+
+	location := schema.Location{
+		Longitude: poi.Location.Coordinates[0],
+		Latitude:  poi.Location.Coordinates[1],
+		AddressComponent: schema.AddressComponent{
+			Country: poi.Country,
+			State:   poi.State,
+			County:  poi.County,
+		},
+	}
+
+	formula := score.AccountFormula(accountNumber)
+	tz := utils.GetLocalLocation("", location.Longitude)
+	rawMetrics, err := s.mongoStore.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	metric := formula.CalculateMetric(*rawMetrics, profile.ScoreCoefficient)
+
+	c.JSON(http.StatusOK, formula.Explain(metric, profile.ScoreCoefficient))
+}
+
+const (
+	// forecastHistoryDays is the number of days of the metric history which a forecast is based on
+	forecastHistoryDays = 14
+)
+
+// areaProfileForecast projects the metric of a POI for the coming days
+func (s *Server) areaProfileForecast(c *gin.Context) {
+	accountNumber := c.GetString("requester")
+
+	poiID, err := primitive.ObjectIDFromHex(c.Param("poiID"))
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid POI ID"))
+		return
+	}
+
+	profile, err := s.mongoStore.GetProfile(accountNumber)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	var poi *schema.ProfilePOI
+	for i, p := range profile.PointsOfInterest {
+		if p.ID == poiID {
+			poi = &profile.PointsOfInterest[i]
+			break
+		}
+	}
+	if poi == nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI, store.ErrPOINotFound)
+		return
+	}
+
+	metric := poi.Metric
+	coefficient := profile.ScoreCoefficient
+	metricLastUpdate := time.Unix(metric.LastUpdate, 0)
+	if time.Since(metricLastUpdate) >= metricUpdateInterval ||
+		(coefficient != nil && coefficient.UpdatedAt.Sub(metricLastUpdate) > 0) {
+		m, err := s.mongoStore.SyncAccountPOIMetrics(accountNumber, coefficient, poiID)
+		if err != nil {
+			if err == store.ErrPOINotFound {
+				abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI, err)
+				return
+			}
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
+		}
+		metric = *m
+	}
+
+	formula, err := score.GetFormula(score.MetricFormulaVersion(metric))
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	detail, err := s.mongoStore.GetPOI(poiID)
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI, err)
+		return
+	}
+
+	// the history is grouped by days starting at the local midnight of the POI
+	tz := utils.GetLocalLocation("", detail.Location.Coordinates[0])
+	now := time.Now().In(tz)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+	history, err := s.mongoStore.GetAccountPOIMetricHistory(accountNumber, poiID,
+		today.AddDate(0, 0, -forecastHistoryDays), today, schema.MetricHistoryGranularityDay, tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, score.GetForecast().ForecastMetric(formula, metric, coefficient, history, today))
+}
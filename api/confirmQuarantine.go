@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/store"
+)
+
+// getConfirmQuarantines lists the quarantined confirm records of a review status, the pending ones by default
+func (s *Server) getConfirmQuarantines(c *gin.Context) {
+	var params struct {
+		Status string `form:"status"`
+		Limit  int64  `form:"limit"`
+	}
+	if err := c.BindQuery(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	switch params.Status {
+	case "":
+		params.Status = schema.QuarantinePending
+	case "all":
+		params.Status = ""
+	case schema.QuarantinePending, schema.QuarantineApproved, schema.QuarantineRejected:
+	default:
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid status"))
+		return
+	}
+
+	records, err := s.mongoStore.GetConfirmQuarantines(params.Status, params.Limit)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": records})
+}
+
+func (s *Server) approveConfirmQuarantine(c *gin.Context) {
+	s.reviewConfirmQuarantine(c, true)
+}
+
+func (s *Server) rejectConfirmQuarantine(c *gin.Context) {
+	s.reviewConfirmQuarantine(c, false)
+}
+
+// reviewConfirmQuarantine approves a quarantined confirm record, which is then saved, or rejects it
+func (s *Server) reviewConfirmQuarantine(c *gin.Context, approve bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("quarantineID"))
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid quarantine ID"))
+		return
+	}
+
+	record, err := s.mongoStore.ReviewConfirmQuarantine(id, approve)
+	if err != nil {
+		switch err {
+		case store.ErrQuarantineNotFound:
+			abortWithEncoding(c, http.StatusNotFound, errorQuarantineNotFound)
+		case store.ErrQuarantineReviewed:
+			abortWithEncoding(c, http.StatusConflict, errorQuarantineReviewed)
+		default:
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": record})
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
+	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
 func (s *Server) currentAreaDebugData(c *gin.Context) {
@@ -27,16 +29,18 @@ func (s *Server) currentAreaDebugData(c *gin.Context) {
 
 	metric := profile.Metric
 	var userCount, aqiNumber, symptomCount int
+	var suppressed bool
 
 	if profile.Location != nil {
 		loc := schema.Location{
 			Latitude:  profile.Location.Coordinates[1],
 			Longitude: profile.Location.Coordinates[0],
 		}
+		tz := utils.GetLocalLocation(profile.Timezone, loc.Longitude)
 		metricLastUpdate := time.Unix(metric.LastUpdate, 0)
 		if time.Since(metricLastUpdate) >= metricUpdateInterval {
 			m, err :=
-				s.mongoStore.SyncAccountMetrics(account.AccountNumber, profile.ScoreCoefficient, loc)
+				s.mongoStore.SyncAccountMetrics(account.AccountNumber, profile.ScoreCoefficient, loc, tz)
 			if err != nil {
 				c.Error(err)
 				abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
@@ -53,7 +57,7 @@ func (s *Server) currentAreaDebugData(c *gin.Context) {
 			return
 		}
 
-		nearAccounts, err := s.mongoStore.NearestDistance(consts.NEARBY_DISTANCE_RANGE, loc)
+		nearAccounts, err := s.mongoStore.NearestDistance(score.GetNeighborhood().Radius, loc)
 		if err != nil {
 			c.Error(err)
 			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
@@ -61,25 +65,40 @@ func (s *Server) currentAreaDebugData(c *gin.Context) {
 		}
 		userCount = len(nearAccounts)
 
-		now := time.Now().UTC()
-		todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		now := time.Now().In(tz)
+		todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
 		todayStartAtUnix := todayStartAt.Unix()
 		tomorrowStartAtUnix := todayStartAt.AddDate(0, 0, 1).Unix()
-		distribution, err := s.mongoStore.FindNearbySymptomDistribution(consts.NEARBY_DISTANCE_RANGE, loc, todayStartAtUnix, tomorrowStartAtUnix)
+		distribution, err := s.mongoStore.FindNearbySymptomDistribution(score.GetNeighborhood().Radius, loc, todayStartAtUnix, tomorrowStartAtUnix, 0)
 		if err != nil {
 			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 			return
 		}
+		reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeSymptom, score.GetNeighborhood().Radius, loc, now, tz)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
+		}
+		distribution, suppressed = store.GetPrivacyGuard().Distribution(distribution, reporterCount)
+		var total float64
 		for _, cnt := range distribution {
-			symptomCount += cnt
+			total += cnt
+		}
+		noisy, noiseSuppressed, err := s.addPrivacyNoise(loc, now, total)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
 		}
+		symptomCount = int(noisy[0])
+		suppressed = suppressed || noiseSuppressed
 	}
 
 	debug := schema.Debug{
-		Metrics:  metric,
-		Users:    userCount,
-		AQI:      aqiNumber,
-		Symptoms: symptomCount,
+		Metrics:    metric,
+		Users:      userCount,
+		AQI:        aqiNumber,
+		Symptoms:   symptomCount,
+		Suppressed: suppressed,
 	}
 
 	c.JSON(http.StatusOK, debug)
@@ -100,6 +119,7 @@ func (s *Server) poiDebugData(c *gin.Context) {
 
 	metric := poi.Metric
 	var userCount, aqiNumber, symptomCount int
+	var suppressed bool
 
 	if poi.Location != nil {
 		loc := schema.Location{
@@ -111,6 +131,7 @@ func (s *Server) poiDebugData(c *gin.Context) {
 				County:  poi.County,
 			},
 		}
+		tz := utils.GetLocalLocation("", loc.Longitude)
 		metricLastUpdate := time.Unix(metric.LastUpdate, 0)
 		if time.Since(metricLastUpdate) >= metricUpdateInterval {
 			m, err :=
@@ -131,7 +152,7 @@ func (s *Server) poiDebugData(c *gin.Context) {
 			return
 		}
 
-		nearAccounts, err := s.mongoStore.NearestDistance(consts.NEARBY_DISTANCE_RANGE, loc)
+		nearAccounts, err := s.mongoStore.NearestDistance(score.GetNeighborhood().Radius, loc)
 		if err != nil {
 			c.Error(err)
 			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
@@ -139,26 +160,41 @@ func (s *Server) poiDebugData(c *gin.Context) {
 		}
 		userCount = len(nearAccounts)
 
-		now := time.Now().UTC()
-		todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		now := time.Now().In(tz)
+		todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
 		todayStartAtUnix := todayStartAt.Unix()
 		tomorrowStartAtUnix := todayStartAt.AddDate(0, 0, 1).Unix()
-		distribution, err := s.mongoStore.FindNearbySymptomDistribution(consts.NEARBY_DISTANCE_RANGE, loc, todayStartAtUnix, tomorrowStartAtUnix)
+		distribution, err := s.mongoStore.FindNearbySymptomDistribution(score.GetNeighborhood().Radius, loc, todayStartAtUnix, tomorrowStartAtUnix, 0)
 		if err != nil {
 			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 			return
 		}
+		reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeSymptom, score.GetNeighborhood().Radius, loc, now, tz)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
+		}
+		distribution, suppressed = store.GetPrivacyGuard().Distribution(distribution, reporterCount)
+		var total float64
 		for _, cnt := range distribution {
-			symptomCount += cnt
+			total += cnt
+		}
+		noisy, noiseSuppressed, err := s.addPrivacyNoise(loc, now, total)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
 		}
+		symptomCount = int(noisy[0])
+		suppressed = suppressed || noiseSuppressed
 
 	}
 
 	debug := schema.Debug{
-		Metrics:  metric,
-		Users:    userCount,
-		AQI:      aqiNumber,
-		Symptoms: symptomCount,
+		Metrics:    metric,
+		Users:      userCount,
+		AQI:        aqiNumber,
+		Symptoms:   symptomCount,
+		Suppressed: suppressed,
 	}
 
 	c.JSON(http.StatusOK, debug)
@@ -23,12 +23,16 @@ var (
 		1104: "unknown account location",
 		1105: "update score error",
 		1106: "unknown POI",
+		1107: "unknown region",
 
 		1200: store.ErrRequestNotExist.Error(),
 		1201: store.ErrMultipleRequestMade.Error(),
 
 		1300: store.ErrPOIListNotFound.Error(),
 		1301: store.ErrPOIListMismatch.Error(),
+
+		1400: store.ErrQuarantineNotFound.Error(),
+		1401: store.ErrQuarantineReviewed.Error(),
 	}
 
 	errorInternalServer             = errorJSON(999)
@@ -49,12 +53,16 @@ var (
 	errorUnknownAccountLocation = errorJSON(1104)
 	errorUpdateScore            = errorJSON(1105)
 	errorUnknownPOI             = errorJSON(1106)
+	errorUnknownRegion          = errorJSON(1107)
 
 	errorRequestNotExist     = errorJSON(1200)
 	errorMultipleRequestMade = errorJSON(1201)
 
 	errorPOIListNotFound  = errorJSON(1300)
 	errorPOIListMissmatch = errorJSON(1301)
+
+	errorQuarantineNotFound = errorJSON(1400)
+	errorQuarantineReviewed = errorJSON(1401)
 )
 
 type ErrorResponse struct {
@@ -8,8 +8,8 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
@@ -73,7 +73,7 @@ func (s *Server) goodBehaviors(c *gin.Context) {
 		return
 	}
 
-	nonOfficialBehaviors, err := s.mongoStore.FindNearbyNonOfficialBehaviors(consts.NEARBY_DISTANCE_RANGE, *loc)
+	nonOfficialBehaviors, err := s.mongoStore.FindNearbyNonOfficialBehaviors(score.GetNeighborhood().Radius, *loc)
 	if err != nil {
 		c.Error(err)
 	}
@@ -135,7 +135,7 @@ func (s *Server) getBehaviorsV2(c *gin.Context) {
 		return
 	}
 
-	customized, err := s.mongoStore.FindNearbyNonOfficialBehaviors(consts.NEARBY_DISTANCE_RANGE, *loc)
+	customized, err := s.mongoStore.FindNearbyNonOfficialBehaviors(score.GetNeighborhood().Radius, *loc)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
@@ -202,7 +202,7 @@ func (s *Server) reportBehaviors(c *gin.Context) {
 		}
 	}
 
-	accts, err := s.mongoStore.NearestDistance(consts.NEARBY_DISTANCE_RANGE, *loc)
+	accts, err := s.mongoStore.NearestDistance(score.GetNeighborhood().Radius, *loc)
 	if nil == err {
 		go func() {
 			if err := utils.TriggerAccountUpdate(*s.cadenceClient, c, accts); err != nil {
@@ -213,7 +213,7 @@ func (s *Server) reportBehaviors(c *gin.Context) {
 		c.Error(err)
 	}
 
-	pois, err := s.mongoStore.NearestPOI(consts.NEARBY_DISTANCE_RANGE, *loc)
+	pois, err := s.mongoStore.NearestPOI(score.GetNeighborhood().Radius, *loc)
 	if err != nil {
 		c.Error(err)
 	}
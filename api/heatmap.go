@@ -0,0 +1,221 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+// heatmapPrecision returns the geohash precision of the heatmap cells shown in a zoom level of a map
+func heatmapPrecision(zoom int) int {
+	precision := schema.HeatmapCellPrecision
+	switch {
+	case zoom <= 2:
+		precision = 1
+	case zoom <= 4:
+		precision = 2
+	case zoom <= 6:
+		precision = 3
+	case zoom <= 9:
+		precision = 4
+	case zoom <= 11:
+		precision = 5
+	}
+
+	if precision > schema.HeatmapCellPrecision {
+		precision = schema.HeatmapCellPrecision
+	}
+	return precision
+}
+
+// parseBoundingBox parses a bounding box in the form of `min_lon,min_lat,max_lon,max_lat`
+func parseBoundingBox(bbox string) (utils.GeohashBox, error) {
+	var box utils.GeohashBox
+
+	values := strings.Split(bbox, ",")
+	if len(values) != 4 {
+		return box, fmt.Errorf("invalid bbox: %s", bbox)
+	}
+
+	coordinates := make([]float64, 4)
+	for i, v := range values {
+		c, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return box, fmt.Errorf("invalid bbox: %s", bbox)
+		}
+		coordinates[i] = c
+	}
+
+	box = utils.GeohashBox{
+		MinLongitude: coordinates[0],
+		MinLatitude:  coordinates[1],
+		MaxLongitude: coordinates[2],
+		MaxLatitude:  coordinates[3],
+	}
+
+	if box.MinLongitude < -180 || box.MaxLongitude > 180 || box.MinLatitude < -90 || box.MaxLatitude > 90 ||
+		box.MinLongitude >= box.MaxLongitude || box.MinLatitude >= box.MaxLatitude {
+		return box, fmt.Errorf("invalid bbox: %s", bbox)
+	}
+
+	return box, nil
+}
+
+// guardHeatmapCells removes or coarsens the cells reported by too few users by the privacy guard
+func guardHeatmapCells(cells []schema.HeatmapCell) []schema.HeatmapCell {
+	guard := store.GetPrivacyGuard()
+
+	guarded := make([]schema.HeatmapCell, 0, len(cells))
+	for _, c := range cells {
+		if c, ok := guard.HeatmapCell(c); ok {
+			guarded = append(guarded, c)
+		}
+	}
+
+	return guarded
+}
+
+// aggregateHeatmapCells merges the cells into the cells of a coarser precision. The score of a merged cell
+// is the average of the scores weighted by the number of reporters, and its confirm data age is the oldest one.
+func aggregateHeatmapCells(cells []schema.HeatmapCell, precision int) []schema.HeatmapCell {
+	type aggregation struct {
+		cell        schema.HeatmapCell
+		scoreSum    float64
+		weightSum   float64
+		confirmAges []int
+	}
+
+	aggregations := make(map[string]*aggregation)
+	geohashes := make([]string, 0)
+	for _, c := range cells {
+		if len(c.Geohash) < precision {
+			continue
+		}
+
+		geohash := c.Geohash[:precision]
+		a, ok := aggregations[geohash]
+		if !ok {
+			a = &aggregation{
+				cell: schema.HeatmapCell{
+					Geohash:    geohash,
+					Confidence: schema.Confidence{ConfirmDataAge: -1},
+					UpdatedAt:  c.UpdatedAt,
+				},
+			}
+			aggregations[geohash] = a
+			geohashes = append(geohashes, geohash)
+		}
+
+		weight := float64(c.Confidence.Reporters)
+		if weight < 1 {
+			weight = 1
+		}
+		a.scoreSum += c.Score * weight
+		a.weightSum += weight
+
+		a.cell.Confidence.Reporters += c.Confidence.Reporters
+		a.cell.Confidence.Reports += c.Confidence.Reports
+		if c.Confidence.ConfirmDataAge > a.cell.Confidence.ConfirmDataAge {
+			a.cell.Confidence.ConfirmDataAge = c.Confidence.ConfirmDataAge
+		}
+		if c.UpdatedAt.Before(a.cell.UpdatedAt) {
+			a.cell.UpdatedAt = c.UpdatedAt
+		}
+	}
+
+	result := make([]schema.HeatmapCell, 0, len(geohashes))
+	rule := score.GetConfidenceRule()
+	for _, geohash := range geohashes {
+		a := aggregations[geohash]
+		a.cell.Score = a.scoreSum / a.weightSum
+		a.cell.Confidence.Level = rule.Level(a.cell.Confidence)
+		result = append(result, a.cell)
+	}
+
+	return result
+}
+
+// heatmapFeature converts a cell to a GeoJSON feature of the polygon of the cell
+func heatmapFeature(cell schema.HeatmapCell) gin.H {
+	box, _ := utils.GeohashDecode(cell.Geohash)
+	band := score.GetScoreBand(cell.Score)
+
+	return gin.H{
+		"type": "Feature",
+		"geometry": gin.H{
+			"type": "Polygon",
+			"coordinates": [][][]float64{{
+				{box.MinLongitude, box.MinLatitude},
+				{box.MaxLongitude, box.MinLatitude},
+				{box.MaxLongitude, box.MaxLatitude},
+				{box.MinLongitude, box.MaxLatitude},
+				{box.MinLongitude, box.MinLatitude},
+			}},
+		},
+		"properties": gin.H{
+			"geohash":     cell.Geohash,
+			"score":       cell.Score,
+			"band":        band,
+			"confidence":  cell.Confidence,
+			"last_update": cell.UpdatedAt.Unix(),
+		},
+	}
+}
+
+// getHeatmap returns the heatmap cells in a bounding box as a GeoJSON feature collection.
+// The cells are merged into coarser cells for the lower zoom levels.
+func (s *Server) getHeatmap(c *gin.Context) {
+	var params struct {
+		BBox string `form:"bbox"`
+		Zoom int    `form:"zoom"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	box, err := parseBoundingBox(params.BBox)
+	if err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	if params.Zoom < 0 {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid zoom: %d", params.Zoom))
+		return
+	}
+
+	// the cells across the edges of the box are included as the cells overlapping the box are queried
+	precision := heatmapPrecision(params.Zoom)
+	cells, truncated, err := s.mongoStore.FindHeatmapCells(box, precision)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	// cells are guarded before merged so that a merged cell tells nothing of the cells reported by too few users
+	cells = guardHeatmapCells(cells)
+	if precision < schema.HeatmapCellPrecision {
+		cells = aggregateHeatmapCells(cells, precision)
+	}
+
+	features := make([]gin.H, 0, len(cells))
+	for _, cell := range cells {
+		features = append(features, heatmapFeature(cell))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":      "FeatureCollection",
+		"features":  features,
+		"truncated": truncated,
+	})
+}
@@ -5,8 +5,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 	"github.com/bitmark-inc/autonomy-api/store"
 )
 
@@ -48,7 +48,7 @@ func (s *Server) askForHelp(c *gin.Context) {
 		return
 	}
 
-	accountNumbers, err := s.mongoStore.NearestDistance(consts.CORHORT_DISTANCE_RANGE, *lastLocation)
+	accountNumbers, err := s.mongoStore.NearestDistance(score.GetNeighborhood().CohortRadius, *lastLocation)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
 		return
@@ -6,9 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
 	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
+	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
 func (s *Server) getSymptomMetrics(c *gin.Context) {
@@ -28,18 +29,34 @@ func (s *Server) getSymptomMetrics(c *gin.Context) {
 	profileID := account.ProfileID.String()
 
 	now := time.Now().UTC()
+	timezone, _ := account.Profile.Metadata["timezone"].(string)
+	tz := utils.GetLocalLocation(timezone, loc.Longitude)
 
-	meToday, meYesterday, err := s.mongoStore.GetSymptomCount(profileID, nil, 0, now)
+	meToday, meYesterday, err := s.mongoStore.GetSymptomCount(profileID, nil, 0, now, tz)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
 	}
-	communityToday, communityYesterday, err := s.mongoStore.GetSymptomCount("", loc, consts.NEARBY_DISTANCE_RANGE, now)
+	communityToday, communityYesterday, err := s.mongoStore.GetSymptomCount("", loc, score.GetNeighborhood().Radius, now, tz)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
 	}
-	reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeSymptom, consts.NEARBY_DISTANCE_RANGE, *loc, now)
+	reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeSymptom, score.GetNeighborhood().Radius, *loc, now, tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return
+	}
+	reporterCountYesterday, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeSymptom, score.GetNeighborhood().Radius, *loc, now.AddDate(0, 0, -1), tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return
+	}
+
+	guard := store.GetPrivacyGuard()
+	guardedToday, suppressedToday := guard.Count(float64(communityToday), reporterCount)
+	guardedYesterday, suppressedYesterday := guard.Count(float64(communityYesterday), reporterCountYesterday)
+	noisy, noiseSuppressed, err := s.addPrivacyNoise(*loc, now, guardedToday, guardedYesterday)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
@@ -51,8 +68,9 @@ func (s *Server) getSymptomMetrics(c *gin.Context) {
 			"delta":       score.ChangeRate(float64(meToday), float64(meYesterday)),
 		},
 		"community": gin.H{
-			"avg_today": score.DivOrDefault(float64(communityToday), float64(reporterCount), 0.0),
-			"delta":     score.ChangeRate(float64(communityToday), float64(communityYesterday)),
+			"avg_today":  score.DivOrDefault(noisy[0], float64(reporterCount), 0.0),
+			"delta":      score.ChangeRate(noisy[0], noisy[1]),
+			"suppressed": suppressedToday || suppressedYesterday || noiseSuppressed,
 		},
 	})
 }
@@ -74,18 +92,34 @@ func (s *Server) getBehaviorMetrics(c *gin.Context) {
 	profileID := account.ProfileID.String()
 
 	now := time.Now().UTC()
+	timezone, _ := account.Profile.Metadata["timezone"].(string)
+	tz := utils.GetLocalLocation(timezone, loc.Longitude)
 
-	meToday, meYesterday, err := s.mongoStore.GetBehaviorCount(profileID, nil, 0, now)
+	meToday, meYesterday, err := s.mongoStore.GetBehaviorCount(profileID, nil, 0, now, tz)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
 	}
-	communityToday, communityYesterday, err := s.mongoStore.GetBehaviorCount("", loc, consts.NEARBY_DISTANCE_RANGE, now)
+	communityToday, communityYesterday, err := s.mongoStore.GetBehaviorCount("", loc, score.GetNeighborhood().Radius, now, tz)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
 	}
-	reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeBehavior, consts.NEARBY_DISTANCE_RANGE, *loc, now)
+	reporterCount, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeBehavior, score.GetNeighborhood().Radius, *loc, now, tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return
+	}
+	reporterCountYesterday, err := s.mongoStore.GetNearbyReportingUserCount(schema.ReportTypeBehavior, score.GetNeighborhood().Radius, *loc, now.AddDate(0, 0, -1), tz)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return
+	}
+
+	guard := store.GetPrivacyGuard()
+	guardedToday, suppressedToday := guard.Count(float64(communityToday), reporterCount)
+	guardedYesterday, suppressedYesterday := guard.Count(float64(communityYesterday), reporterCountYesterday)
+	noisy, noiseSuppressed, err := s.addPrivacyNoise(*loc, now, guardedToday, guardedYesterday)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
@@ -97,8 +131,28 @@ func (s *Server) getBehaviorMetrics(c *gin.Context) {
 			"delta":       score.ChangeRate(float64(meToday), float64(meYesterday)),
 		},
 		"community": gin.H{
-			"avg_today": score.DivOrDefault(float64(communityToday), float64(reporterCount), 0.0),
-			"delta":     score.ChangeRate(float64(communityToday), float64(communityYesterday)),
+			"avg_today":  score.DivOrDefault(noisy[0], float64(reporterCount), 0.0),
+			"delta":      score.ChangeRate(noisy[0], noisy[1]),
+			"suppressed": suppressedToday || suppressedYesterday || noiseSuppressed,
 		},
 	})
 }
+
+// addPrivacyNoise adds noise to the community counts of an area if the privacy noise is enabled.
+// The counts are suppressed to zeros if the privacy budget of the area is used up.
+func (s *Server) addPrivacyNoise(loc schema.Location, now time.Time, counts ...float64) ([]float64, bool, error) {
+	noise := store.GetPrivacyGuard().Noise
+	if !noise.Enabled {
+		return counts, false, nil
+	}
+
+	ok, err := s.mongoStore.SpendPrivacyBudget(loc, noise.Epsilon, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return make([]float64, len(counts)), true, nil
+	}
+
+	return noise.Counts(counts...), false, nil
+}
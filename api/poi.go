@@ -3,11 +3,14 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 	"github.com/bitmark-inc/autonomy-api/store"
 	"github.com/bitmark-inc/autonomy-api/utils"
 )
@@ -170,3 +173,126 @@ func (s *Server) deletePOI(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"result": "OK"})
 }
+
+const (
+	maxComparedPOIs = 10
+)
+
+// comparePOIs returns the metrics of the POIs of an account side by side, where the POIs are
+// ranked by their scores. The score delta is the change from the average score of the previous day,
+// and it is null if there is no score of the previous day.
+func (s *Server) comparePOIs(c *gin.Context) {
+	account, ok := c.MustGet("account").(*schema.Account)
+	if !ok {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return
+	}
+
+	var params struct {
+		IDs string `form:"ids"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	ids := strings.Split(params.IDs, ",")
+	if params.IDs == "" || len(ids) > maxComparedPOIs {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid POI IDs"))
+		return
+	}
+
+	profile, err := s.mongoStore.GetProfile(account.AccountNumber)
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	followed := make(map[primitive.ObjectID]schema.ProfilePOI)
+	for _, p := range profile.PointsOfInterest {
+		followed[p.ID] = p
+	}
+
+	pois := make([]gin.H, 0, len(ids))
+	scores := make([]float64, 0, len(ids))
+	for _, id := range ids {
+		poiID, err := primitive.ObjectIDFromHex(strings.TrimSpace(id))
+		if err != nil {
+			abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid POI ID"))
+			return
+		}
+
+		poi, ok := followed[poiID]
+		if !ok {
+			abortWithEncoding(c, http.StatusBadRequest, errorUnknownPOI)
+			return
+		}
+
+		metric := poi.Metric
+		coefficient := profile.ScoreCoefficient
+		metricLastUpdate := time.Unix(metric.LastUpdate, 0)
+		if time.Since(metricLastUpdate) >= metricUpdateInterval ||
+			(coefficient != nil && coefficient.UpdatedAt.Sub(metricLastUpdate) > 0) {
+			m, err := s.mongoStore.SyncAccountPOIMetrics(account.AccountNumber, coefficient, poiID)
+			if err != nil {
+				abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+				return
+			}
+			metric = *m
+		}
+
+		metric = store.GetPrivacyGuard().Metric(metric)
+		band := score.GetScoreBand(metric.Score)
+		metric.Band = &band
+
+		// the delta is from the score of yesterday, where days start at the local midnight of the POI
+		detail, err := s.mongoStore.GetPOI(poiID)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
+		}
+		tz := utils.GetLocalLocation("", detail.Location.Coordinates[0])
+		now := time.Now().In(tz)
+		todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+
+		history, err := s.mongoStore.GetAccountPOIMetricHistory(account.AccountNumber, poiID,
+			todayStartAt.AddDate(0, 0, -1), todayStartAt, schema.MetricHistoryGranularityDay, tz)
+		if err != nil {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+			return
+		}
+
+		var delta *float64
+		if len(history) > 0 {
+			d := metric.Score - history[len(history)-1].Score
+			delta = &d
+		}
+
+		pois = append(pois, gin.H{
+			"id":          poiID.Hex(),
+			"alias":       poi.Alias,
+			"address":     poi.Address,
+			"place_type":  poi.PlaceType,
+			"score":       metric.Score,
+			"score_delta": delta,
+			"metric":      metric,
+		})
+		scores = append(scores, metric.Score)
+	}
+
+	// rank the POIs from the safest one
+	for i := range pois {
+		rank := 1
+		for j := range scores {
+			if scores[j] > scores[i] {
+				rank++
+			}
+		}
+		pois[i]["rank"] = rank
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"points_of_interest": pois,
+	})
+}
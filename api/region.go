@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
+)
+
+// regionAnyPart is the placeholder of the state or the county of a region which covers
+// all the states or counties in it, e.g. `/regions/Taiwan/-/-/metrics` for the whole country
+const regionAnyPart = "-"
+
+// regionRankingPath is the path of the region ranking under `/regions`
+const regionRankingPath = "ranking"
+
+func regionPart(part string) string {
+	if part == regionAnyPart {
+		return ""
+	}
+	return part
+}
+
+// getRegionMetrics returns the metric of an administrative region, aggregated from the symptoms and
+// behaviors reported within its boundaries and the confirmed case data of the region.
+// Days start at the midnight of the time zone of the region.
+func (s *Server) getRegionMetrics(c *gin.Context) {
+	region, metric, _, ok := s.regionMetric(c)
+	if !ok {
+		return
+	}
+
+	metric = store.GetPrivacyGuard().Metric(metric)
+	band := score.GetScoreBand(metric.Score)
+	metric.Band = &band
+
+	c.JSON(http.StatusOK, gin.H{
+		"region": region,
+		"metric": metric,
+	})
+}
+
+// getRegionForecast projects the metric of an administrative region for the coming days.
+// Symptom and behavior scores are projected flat since the history of regions is not kept.
+func (s *Server) getRegionForecast(c *gin.Context) {
+	region, metric, tz, ok := s.regionMetric(c)
+	if !ok {
+		return
+	}
+
+	formula, err := score.GetFormula(score.MetricFormulaVersion(metric))
+	if err != nil {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	now := time.Now().In(tz)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+
+	c.JSON(http.StatusOK, gin.H{
+		"region":   region,
+		"forecast": score.GetForecast().ForecastMetric(formula, metric, nil, nil, today),
+	})
+}
+
+// regionMetric calculates the metric of the region in the path by the formula of the requester,
+// along with the time zone of the region. The request is aborted if it fails.
+func (s *Server) regionMetric(c *gin.Context) (schema.Region, schema.Metric, *time.Location, bool) {
+	account, ok := c.MustGet("account").(*schema.Account)
+	if !ok {
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
+		return schema.Region{}, schema.Metric{}, nil, false
+	}
+
+	region := schema.Region{
+		Country: c.Param("country"),
+		State:   regionPart(c.Param("state")),
+		County:  regionPart(c.Param("county")),
+	}
+	if region.Country == "" || region.Country == regionAnyPart {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters)
+		return schema.Region{}, schema.Metric{}, nil, false
+	}
+
+	formula := score.AccountFormula(account.AccountNumber)
+	rawMetrics, tz, err := s.mongoStore.GetRegionRawMetrics(region, score.FormulaScoreWindow(formula.Version()), time.Now())
+	if err != nil {
+		if err == store.ErrRegionNotFound {
+			abortWithEncoding(c, http.StatusNotFound, errorUnknownRegion, err)
+		} else {
+			abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		}
+		return schema.Region{}, schema.Metric{}, nil, false
+	}
+
+	return region, formula.CalculateMetric(*rawMetrics, nil), tz, true
+}
+
+const (
+	defaultRegionRankingLimit = 50
+	maxRegionRankingLimit     = 500
+)
+
+// getRegionCollection serves the paths of a single segment under `/regions`, which is only
+// `/regions/ranking` for now. A static route can not be registered beside `/regions/:country`
+// in gin, so the ranking is dispatched here.
+func (s *Server) getRegionCollection(c *gin.Context) {
+	if c.Param("country") != regionRankingPath {
+		abortWithEncoding(c, http.StatusNotFound, errorUnknownRegion)
+		return
+	}
+
+	s.getRegionRanking(c)
+}
+
+// getRegionRanking returns the regions of a level ranked by their scores, score deltas or confidence.
+// The regions are ranked by the metrics updated periodically in the background, and the regions
+// without data are not ranked. Regions with low confidence are ranked along with their confidence
+// unless `exclude_low_confidence` is set.
+func (s *Server) getRegionRanking(c *gin.Context) {
+	var params struct {
+		Level                schema.RegionLevel  `form:"level"`
+		Country              string              `form:"country"`
+		Sort                 store.RegionRanking `form:"sort"`
+		Limit                int64               `form:"limit"`
+		ExcludeLowConfidence bool                `form:"exclude_low_confidence"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	if params.Level == "" {
+		params.Level = schema.RegionLevelCounty
+	}
+	if params.Sort == "" {
+		params.Sort = store.RegionRankingByScore
+	}
+	if params.Limit <= 0 || params.Limit > maxRegionRankingLimit {
+		params.Limit = defaultRegionRankingLimit
+	}
+
+	switch params.Level {
+	case schema.RegionLevelCountry, schema.RegionLevelState, schema.RegionLevelCounty:
+	default:
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, fmt.Errorf("invalid level: %s", params.Level))
+		return
+	}
+
+	metrics, err := s.mongoStore.RankRegionMetrics(params.Level, params.Country, params.Sort, params.Limit, params.ExcludeLowConfidence)
+	if err != nil {
+		if err == store.ErrUnknownRegionRanking {
+			abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+			return
+		}
+		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer, err)
+		return
+	}
+
+	regions := make([]gin.H, 0, len(metrics))
+	for i, m := range metrics {
+		regions = append(regions, gin.H{
+			"rank":        i + 1,
+			"region":      m.Region,
+			"level":       m.Level,
+			"score":       m.Score,
+			"score_delta": m.ScoreDelta,
+			"band":        score.GetScoreBand(m.Score),
+			"confidence":  m.Confidence,
+			"last_update": m.UpdatedAt.Unix(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"regions": regions,
+	})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/mocks"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/store"
+)
+
+func regionRequest(t *testing.T, mongoStore store.MongoStore, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	s := &Server{mongoStore: mongoStore}
+	router := gin.New()
+	router.GET("/api/regions/:country", s.getRegionCollection)
+	router.GET("/api/regions/:country/:state/:county/metrics", s.getRegionMetrics)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetRegionRanking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+	region := schema.Region{Country: "Taiwan", State: "Taipei City", County: "Da'an District"}
+	mongoStore.EXPECT().
+		RankRegionMetrics(schema.RegionLevelCounty, "Taiwan", store.RegionRankingByScore, int64(defaultRegionRankingLimit), false).
+		Return([]schema.RegionMetric{{
+			ID:         region.ID(),
+			Region:     region,
+			Level:      schema.RegionLevelCounty,
+			Score:      80,
+			Confidence: schema.Confidence{Reporters: 1, Level: schema.ConfidenceLow},
+			UpdatedAt:  time.Now(),
+		}}, nil)
+
+	w := regionRequest(t, mongoStore, "/api/regions/ranking?level=county&country=Taiwan&sort=score")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result struct {
+		Regions []struct {
+			Rank       int               `json:"rank"`
+			Region     schema.Region     `json:"region"`
+			Confidence schema.Confidence `json:"confidence"`
+		} `json:"regions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Len(t, result.Regions, 1)
+	assert.Equal(t, 1, result.Regions[0].Rank)
+	assert.Equal(t, region, result.Regions[0].Region)
+	assert.Equal(t, schema.ConfidenceLow, result.Regions[0].Confidence.Level)
+
+	// regions with low confidence are excluded on request
+	mongoStore.EXPECT().
+		RankRegionMetrics(schema.RegionLevelCounty, "", store.RegionRankingByScore, int64(defaultRegionRankingLimit), true).
+		Return([]schema.RegionMetric{}, nil)
+
+	w = regionRequest(t, mongoStore, "/api/regions/ranking?exclude_low_confidence=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// a country alone is not a route of regions
+	w = regionRequest(t, mongoStore, "/api/regions/Taiwan")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
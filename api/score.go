@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bitmark-inc/autonomy-api/consts"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+)
+
+type simulateScoreParams struct {
+	Symptoms struct {
+		TotalPeople           float64                    `json:"total_people"`
+		TodayDistribution     schema.SymptomDistribution `json:"today_distribution"`
+		YesterdayDistribution schema.SymptomDistribution `json:"yesterday_distribution"`
+	} `json:"symptoms"`
+	Behaviors struct {
+		ReportTimes           float64            `json:"report_times"`
+		TodayDistribution     map[string]float64 `json:"today_distribution"`
+		YesterdayDistribution map[string]float64 `json:"yesterday_distribution"`
+	} `json:"behaviors"`
+	// ConfirmCases is the daily confirmed cases of the confirm score window, from the oldest day to the latest
+	ConfirmCases []float64 `json:"confirm_cases"`
+	// ConfirmPopulation is used to normalize confirmed cases if the confirm mode is per 100k people
+	ConfirmPopulation float64 `json:"confirm_population"`
+	// Confidence is how much data the metric is calculated from. It is estimated from the reports if not given.
+	Confidence     *schema.Confidence       `json:"confidence"`
+	Coefficient    *schema.ScoreCoefficient `json:"coefficient"`
+	FormulaVersion string                   `json:"formula_version"`
+}
+
+// validate rejects negative counts, which no area can report
+func (p simulateScoreParams) validate() error {
+	if p.Symptoms.TotalPeople < 0 {
+		return fmt.Errorf("negative total people: %v", p.Symptoms.TotalPeople)
+	}
+	if p.Behaviors.ReportTimes < 0 {
+		return fmt.Errorf("negative report times: %v", p.Behaviors.ReportTimes)
+	}
+	if p.ConfirmPopulation < 0 {
+		return fmt.Errorf("negative confirm population: %v", p.ConfirmPopulation)
+	}
+	if len(p.ConfirmCases) > consts.ConfirmScoreWindowSize {
+		return fmt.Errorf("confirm cases exceed the window size: %d", consts.ConfirmScoreWindowSize)
+	}
+	for _, cases := range p.ConfirmCases {
+		if cases < 0 {
+			return fmt.Errorf("negative confirm cases: %v", cases)
+		}
+	}
+
+	for _, distribution := range []map[string]float64{
+		p.Symptoms.TodayDistribution,
+		p.Symptoms.YesterdayDistribution,
+		p.Behaviors.TodayDistribution,
+		p.Behaviors.YesterdayDistribution,
+	} {
+		for id, cnt := range distribution {
+			if cnt < 0 {
+				return fmt.Errorf("negative count of %s: %v", id, cnt)
+			}
+		}
+	}
+
+	if p.Confidence != nil && (p.Confidence.Reporters < 0 || p.Confidence.Reports < 0) {
+		return fmt.Errorf("negative confidence: %+v", *p.Confidence)
+	}
+
+	if p.Coefficient != nil {
+		if err := validateBehaviorWeights(p.Coefficient.BehaviorWeights); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// metric builds the raw metric to be scored. The confidence is estimated from the
+// reports if it is not given: the symptom reporters are the reporters of the area, and
+// the confirm data is up to date if any confirmed cases are given.
+func (p simulateScoreParams) metric() schema.Metric {
+	confirmData := make([]schema.CDSScoreDataSet, 0, len(p.ConfirmCases))
+	for _, cases := range p.ConfirmCases {
+		confirmData = append(confirmData, schema.CDSScoreDataSet{Name: "simulation", Cases: cases})
+	}
+
+	var confidence schema.Confidence
+	if p.Confidence != nil {
+		confidence = *p.Confidence
+	} else {
+		confidence = schema.Confidence{
+			Reporters:      int(p.Symptoms.TotalPeople),
+			Reports:        int(p.Symptoms.TotalPeople + p.Behaviors.ReportTimes),
+			ConfirmDataAge: -1,
+		}
+		if len(p.ConfirmCases) > 0 {
+			confidence.ConfirmDataAge = 0
+		}
+	}
+
+	return schema.Metric{
+		Confidence: confidence,
+		Details: schema.Details{
+			Confirm: schema.ConfirmDetail{
+				ContinuousData: confirmData,
+				Population:     p.ConfirmPopulation,
+			},
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: p.Symptoms.TotalPeople,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: p.Symptoms.TodayDistribution,
+				},
+				YesterdayData: schema.NearestSymptomData{
+					WeightDistribution: p.Symptoms.YesterdayDistribution,
+				},
+			},
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes:           p.Behaviors.ReportTimes,
+				TodayDistribution:     p.Behaviors.TodayDistribution,
+				YesterdayDistribution: p.Behaviors.YesterdayDistribution,
+			},
+		},
+	}
+}
+
+// simulateScore calculates a metric from submitted raw metrics by the same formula
+// used to score areas, so that a formula can be examined without real reports.
+func (s *Server) simulateScore(c *gin.Context) {
+	var params simulateScoreParams
+
+	if err := c.BindJSON(&params); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	if err := params.validate(); err != nil {
+		abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+		return
+	}
+
+	formula := score.AccountFormula(c.GetString("requester"))
+	if params.FormulaVersion != "" {
+		f, err := score.GetFormula(params.FormulaVersion)
+		if err != nil {
+			abortWithEncoding(c, http.StatusBadRequest, errorInvalidParameters, err)
+			return
+		}
+		formula = f
+	}
+
+	metric := formula.CalculateMetric(params.metric(), params.Coefficient)
+
+	c.JSON(http.StatusOK, gin.H{
+		"metric":      metric,
+		"explanation": formula.Explain(metric, params.Coefficient),
+	})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func simulateScoreRequest(t *testing.T, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	s := &Server{}
+	router := gin.New()
+	router.POST("/api/score/simulate", s.simulateScore)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/score/simulate", bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSimulateScore(t *testing.T) {
+	w := simulateScoreRequest(t, `{
+		"symptoms": {"total_people": 30, "today_distribution": {"cough": 3}},
+		"behaviors": {"report_times": 30, "today_distribution": {"clean_hand": 20}},
+		"confirm_cases": [10, 20]
+	}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result struct {
+		Metric schema.Metric `json:"metric"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 30, result.Metric.Confidence.Reporters)
+	assert.Equal(t, 0, result.Metric.Confidence.ConfirmDataAge)
+	assert.Equal(t, schema.ConfidenceHigh, result.Metric.Confidence.Level)
+	assert.Less(t, result.Metric.Details.Symptoms.Score, 100.0)
+	assert.Greater(t, result.Metric.Details.Behaviors.Score, 0.0)
+}
+
+func TestSimulateScoreGivenConfidence(t *testing.T) {
+	w := simulateScoreRequest(t, `{
+		"symptoms": {"total_people": 30},
+		"confidence": {"reporters": 1, "confirm_data_age": -1}
+	}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result struct {
+		Metric schema.Metric `json:"metric"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, schema.ConfidenceLow, result.Metric.Confidence.Level)
+}
+
+func TestSimulateScoreInvalidParameters(t *testing.T) {
+	for _, body := range []string{
+		`{"symptoms": {"total_people": -1}}`,
+		`{"behaviors": {"report_times": -1}}`,
+		`{"symptoms": {"total_people": 10, "today_distribution": {"cough": -3}}}`,
+		`{"behaviors": {"report_times": 10, "yesterday_distribution": {"clean_hand": -1}}}`,
+		`{"confirm_cases": [10, -20]}`,
+		`{"confirm_cases": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15]}`,
+		`{"confirm_population": -100}`,
+		`{"confidence": {"reporters": -1}}`,
+		`{"coefficient": {"behavior_weights": {"unknown": 1}}}`,
+		`{"formula_version": "unknown"}`,
+		`{"symptoms": 1}`,
+	} {
+		w := simulateScoreRequest(t, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
@@ -166,6 +166,9 @@ func (s *Server) setupRouter() *gin.Engine {
 	secretRoute.Use(s.apikeyAuthentication(viper.GetString("server.apikey.admin")))
 	{
 		// secretRoute.POST("/delete-accounts", s.adminAccountDelete)
+		secretRoute.GET("/confirm-quarantines", s.getConfirmQuarantines)
+		secretRoute.POST("/confirm-quarantines/:quarantineID/approve", s.approveConfirmQuarantine)
+		secretRoute.POST("/confirm-quarantines/:quarantineID/reject", s.rejectConfirmQuarantine)
 	}
 
 	metricRoute := r.Group("/metrics")
@@ -190,6 +193,7 @@ func (s *Server) setupRouter() *gin.Engine {
 	{
 		poiRoute.POST("", s.addPOI)
 		poiRoute.GET("", s.getPOI)
+		poiRoute.GET("/compare", s.comparePOIs)
 		poiRoute.PUT("/order", s.updatePOIOrder)
 		poiRoute.PATCH("/:poiID", s.updatePOIAlias)
 		poiRoute.DELETE("/:poiID", s.deletePOI)
@@ -200,6 +204,29 @@ func (s *Server) setupRouter() *gin.Engine {
 	{
 		areaProfile.GET("/", s.currentAreaProfile)
 		areaProfile.GET("/:poiID", s.singleAreaProfile)
+		areaProfile.GET("/:poiID/history", s.areaProfileHistory)
+		areaProfile.GET("/:poiID/explain", s.areaProfileExplain)
+		areaProfile.GET("/:poiID/forecast", s.areaProfileForecast)
+	}
+
+	scoreRoute := apiRoute.Group("/score")
+	scoreRoute.Use(s.recognizeAccountMiddleware())
+	{
+		scoreRoute.POST("/simulate", s.simulateScore)
+	}
+
+	heatmapRoute := apiRoute.Group("/heatmap")
+	heatmapRoute.Use(s.recognizeAccountMiddleware())
+	{
+		heatmapRoute.GET("", s.getHeatmap)
+	}
+
+	regionRoute := apiRoute.Group("/regions")
+	regionRoute.Use(s.recognizeAccountMiddleware())
+	{
+		regionRoute.GET("/:country", s.getRegionCollection)
+		regionRoute.GET("/:country/:state/:county/metrics", s.getRegionMetrics)
+		regionRoute.GET("/:country/:state/:county/forecast", s.getRegionForecast)
 	}
 
 	r.GET("/healthz", s.healthz)
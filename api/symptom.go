@@ -8,8 +8,8 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 	"github.com/bitmark-inc/autonomy-api/utils"
 	workflow "go.uber.org/cadence/.gen/go/shared"
 )
@@ -72,7 +72,7 @@ func (s *Server) getSymptoms(c *gin.Context) {
 		abortWithEncoding(c, http.StatusBadRequest, errorUnknownAccountLocation)
 	}
 
-	customized, err := s.mongoStore.FindNearbyNonOfficialSymptoms(consts.NEARBY_DISTANCE_RANGE, *loc)
+	customized, err := s.mongoStore.FindNearbyNonOfficialSymptoms(score.GetNeighborhood().Radius, *loc)
 	if err != nil {
 		c.Error(err)
 	}
@@ -141,7 +141,7 @@ func (s *Server) getSymptomsV2(c *gin.Context) {
 		return
 	}
 
-	customized, err := s.mongoStore.FindNearbyNonOfficialSymptoms(consts.NEARBY_DISTANCE_RANGE, *loc)
+	customized, err := s.mongoStore.FindNearbyNonOfficialSymptoms(score.GetNeighborhood().Radius, *loc)
 	if err != nil {
 		abortWithEncoding(c, http.StatusInternalServerError, errorInternalServer)
 		return
@@ -206,7 +206,7 @@ func (s *Server) reportSymptoms(c *gin.Context) {
 		}
 	}
 
-	accts, err := s.mongoStore.NearestDistance(consts.NEARBY_DISTANCE_RANGE, *loc)
+	accts, err := s.mongoStore.NearestDistance(score.GetNeighborhood().Radius, *loc)
 	if nil == err {
 		go func() {
 			if err := utils.TriggerAccountUpdate(*s.cadenceClient, c, accts); err != nil {
@@ -216,7 +216,7 @@ func (s *Server) reportSymptoms(c *gin.Context) {
 	} else {
 		c.Error(err)
 	}
-	pois, err := s.mongoStore.NearestPOI(consts.NEARBY_DISTANCE_RANGE, *loc)
+	pois, err := s.mongoStore.NearestPOI(score.GetNeighborhood().Radius, *loc)
 	if nil == err {
 		go func() {
 			if err := utils.TriggerPOIUpdate(*s.cadenceClient, c, pois); err != nil {
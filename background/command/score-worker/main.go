@@ -15,8 +15,11 @@ import (
 	"googlemaps.github.io/maps"
 
 	scoreWorker "github.com/bitmark-inc/autonomy-api/background/score"
+	"github.com/bitmark-inc/autonomy-api/external/aqi"
 	cadence "github.com/bitmark-inc/autonomy-api/external/cadence"
 	"github.com/bitmark-inc/autonomy-api/geo"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 	"github.com/bitmark-inc/autonomy-api/store"
 )
 
@@ -105,6 +108,74 @@ func main() {
 		),
 	)
 
+	if err := score.SetupFormula(viper.GetString("score.formula.default"), viper.GetStringMapString("score.formula.accounts")); err != nil {
+		logger.Panic("setup score formula with error", zap.Error(err))
+	}
+
+	var scoreBands []schema.ScoreBand
+	if err := viper.UnmarshalKey("score.bands", &scoreBands); err != nil {
+		logger.Panic("setup score bands with error", zap.Error(err))
+	}
+	if err := score.SetupScoreBands(scoreBands); err != nil {
+		logger.Panic("setup score bands with error", zap.Error(err))
+	}
+
+	var spikeDetector score.SpikeDetector
+	if err := viper.UnmarshalKey("score.spike", &spikeDetector); err != nil {
+		logger.Panic("setup symptom spike detector with error", zap.Error(err))
+	}
+	if err := score.SetupSpikeDetector(spikeDetector); err != nil {
+		logger.Panic("setup symptom spike detector with error", zap.Error(err))
+	}
+
+	var scoreWindows map[string]schema.ScoreWindow
+	if err := viper.UnmarshalKey("score.windows", &scoreWindows); err != nil {
+		logger.Panic("setup score windows with error", zap.Error(err))
+	}
+	if err := score.SetupScoreWindows(scoreWindows); err != nil {
+		logger.Panic("setup score windows with error", zap.Error(err))
+	}
+
+	var neighborhood score.Neighborhood
+	if err := viper.UnmarshalKey("score.neighborhood", &neighborhood); err != nil {
+		logger.Panic("setup neighborhood with error", zap.Error(err))
+	}
+	if err := score.SetupNeighborhood(neighborhood); err != nil {
+		logger.Panic("setup neighborhood with error", zap.Error(err))
+	}
+
+	if err := score.SetupConfirmMode(viper.GetString("score.confirm.mode")); err != nil {
+		logger.Panic("setup confirm mode with error", zap.Error(err))
+	}
+
+	var confidenceRule score.ConfidenceRule
+	if err := viper.UnmarshalKey("score.confidence", &confidenceRule); err != nil {
+		logger.Panic("setup confidence rule with error", zap.Error(err))
+	}
+	if err := score.SetupConfidenceRule(confidenceRule); err != nil {
+		logger.Panic("setup confidence rule with error", zap.Error(err))
+	}
+
+	var airQuality score.AirQuality
+	if err := viper.UnmarshalKey("score.air_quality", &airQuality); err != nil {
+		logger.Panic("setup air quality with error", zap.Error(err))
+	}
+	if err := score.SetupAirQuality(airQuality); err != nil {
+		logger.Panic("setup air quality with error", zap.Error(err))
+	}
+
+	var privacyGuard store.PrivacyGuard
+	if err := viper.UnmarshalKey("privacy", &privacyGuard); err != nil {
+		logger.Panic("setup privacy guard with error", zap.Error(err))
+	}
+	if err := store.SetupPrivacyGuard(privacyGuard); err != nil {
+		logger.Panic("setup privacy guard with error", zap.Error(err))
+	}
+
+	if viper.GetString("aqi.key") != "" {
+		store.SetAQIClient(aqi.New(viper.GetString("aqi.key"), ""))
+	}
+
 	mongoStore := store.NewMongoStore(
 		mongoClient,
 		viper.GetString("mongo.database"),
@@ -112,5 +183,12 @@ func main() {
 
 	worker := scoreWorker.NewScoreUpdateWorker(viper.GetString("cadence.domain"), mongoStore)
 	worker.Register()
+
+	if err := scoreWorker.StartHeatmapUpdateWorkflow(context.Background(), cadence.NewClient()); err != nil {
+		logger.Panic("start heatmap update workflow with error", zap.Error(err))
+	}
+	if err := scoreWorker.StartRegionMetricUpdateWorkflow(context.Background(), cadence.NewClient()); err != nil {
+		logger.Panic("start region metric update workflow with error", zap.Error(err))
+	}
 	worker.Start(cadence.BuildCadenceServiceClient(viper.GetString("cadence.conn")), logger)
 }
@@ -22,6 +22,7 @@ var ErrTooFrequentUpdate = fmt.Errorf("too frequent update")
 // NotificationProfile is a struct that summarizes how notifications are going to deliver.
 type NotificationProfile struct {
 	StateChangedAccounts  []string
+	BandTransitions       []schema.ScoreBandTransition
 	SymptomsSpikeAccounts []string
 	ReportRiskArea        bool
 	RemindGoodBehavior    bool
@@ -61,12 +62,14 @@ func (s *ScoreUpdateWorker) CalculatePOIStateActivity(ctx context.Context, id st
 	}
 
 	logger.Info("Calculate POI metric by location.", zap.Any("location", location))
-	rawMetrics, err := s.mongo.CollectRawMetrics(location)
+	formula := score.DefaultFormula()
+	tz := utils.GetLocalLocation("", location.Longitude)
+	rawMetrics, err := s.mongo.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
 	if err != nil {
 		return nil, err
 	}
 
-	metric := score.CalculateMetric(*rawMetrics, nil)
+	metric := formula.CalculateMetric(*rawMetrics, nil)
 
 	return &metric, nil
 }
@@ -83,14 +86,23 @@ func (s *ScoreUpdateWorker) CheckLocationSpikeActivity(ctx context.Context, spik
 	return nil, nil
 }
 
+// addMetricHistory saves a snapshot of a refreshed metric. Failing to save a snapshot
+// is only logged since it should not fail the refresh of a metric.
+func (s *ScoreUpdateWorker) addMetricHistory(ctx context.Context, history schema.MetricHistory) {
+	if err := s.mongo.AddMetricHistory(history); err != nil {
+		activity.GetLogger(ctx).Error("add metric history", zap.String("accountNumber", history.AccountNumber), zap.Error(err))
+	}
+}
+
 // RefreshLocationStateActivity updates the metrics as well as the score if the POI id
 // is not provided. Otherwise, it updates the score of POIs in the profile.
-// It will return accounts whose score's color is changed.
+// It will return accounts whose score's band is changed along with the band transitions.
 func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, accountNumber, poiID string, metric schema.Metric) (*NotificationProfile, error) {
 	logger := activity.GetLogger(ctx)
 
 	var reportRiskArea, remindGoodBehavior bool
 	stateChangedAccounts := make([]string, 0)
+	bandTransitions := make([]schema.ScoreBandTransition, 0)
 	symptomsSpikeAccounts := make([]string, 0)
 
 	if poiID != "" {
@@ -102,6 +114,7 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 		if err := s.mongo.UpdatePOIMetric(id, metric); err != nil {
 			return nil, err
 		}
+		s.addMetricHistory(ctx, schema.NewMetricHistory("", &id, metric))
 
 		profiles, err := s.mongo.GetProfilesByPOI(poiID)
 		if err != nil {
@@ -129,13 +142,17 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 			accountNow := time.Now().In(accountLocation)
 			accountToday := time.Date(accountNow.Year(), accountNow.Month(), accountNow.Day(), 0, 0, 0, 0, accountLocation)
 
-			if profile.ScoreCoefficient != nil {
-				metric.Score = score.TotalScoreV1(*profile.ScoreCoefficient, metric.Details.Symptoms.Score, metric.Details.Behaviors.Score, metric.Details.Confirm.Score)
+			// recalculate the metric if the account is assigned to a formula which is
+			// different from the one used by the POI, or customizes its coefficient
+			formula := score.AccountFormula(profile.AccountNumber)
+			if formula.Version() != score.MetricFormulaVersion(metric) || profile.ScoreCoefficient != nil {
+				metric = formula.CalculateMetric(metric, profile.ScoreCoefficient)
 			}
 
 			if err := s.mongo.UpdateProfilePOIMetric(profile.AccountNumber, id, metric); err != nil {
 				return nil, err
 			}
+			s.addMetricHistory(ctx, schema.NewMetricHistory(profile.AccountNumber, &id, metric))
 
 			poi := profile.PointsOfInterest[0]
 			lastSpikeUpdate := poi.Metric.Details.Symptoms.LastSpikeUpdate.In(accountLocation)
@@ -152,13 +169,16 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 			}
 
 			var changed bool
+			var transition schema.ScoreBandTransition
 			if len(profile.PointsOfInterest) != 0 {
-				changed = score.CheckScoreColorChange(poi.Score, metric.Score)
+				transition, changed = score.CheckScoreBandTransition(poi.Score, metric.Score)
 			}
 
 			if changed {
-				logger.Debug("State color changed", zap.Any("old", profile.Metric.Score), zap.Any("new", metric.Score))
+				logger.Debug("State band changed", zap.Any("old", transition.Old), zap.Any("new", transition.New))
+				transition.AccountNumber = profile.AccountNumber
 				stateChangedAccounts = append(stateChangedAccounts, profile.AccountNumber)
+				bandTransitions = append(bandTransitions, transition)
 			}
 		}
 	} else { // poiID == ''
@@ -178,6 +198,7 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 		if err := s.mongo.UpdateProfileMetric(accountNumber, metric); err != nil {
 			return nil, err
 		}
+		s.addMetricHistory(ctx, schema.NewMetricHistory(accountNumber, nil, metric))
 
 		if time.Since(profile.LastNudge[schema.NudgeBehaviorOnSymptomSpikeArea]) > 90*time.Minute { // 90 minutes of delay between nudges
 			if profile.Metric.SymptomDelta < 10 && metric.SymptomDelta >= 10 { // from a non-spike area to a spike area
@@ -202,28 +223,43 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 		}
 
 		var changed bool
+		var transition schema.ScoreBandTransition
 		if profile.Metric.LastUpdate != 0 {
-			changed = score.CheckScoreColorChange(profile.Metric.Score, metric.Score)
+			transition, changed = score.CheckScoreBandTransition(profile.Metric.Score, metric.Score)
 		}
 
 		if changed {
-			logger.Debug("State color changed", zap.Any("old", profile.Metric.Score), zap.Any("new", metric.Score))
+			logger.Debug("State band changed", zap.Any("old", transition.Old), zap.Any("new", transition.New))
+			transition.AccountNumber = profile.AccountNumber
 			stateChangedAccounts = append(stateChangedAccounts, profile.AccountNumber)
+			bandTransitions = append(bandTransitions, transition)
 		}
 
 		// only report the risk area when a location state change is detected and
-		// the score it lower than 67 (with color yellow and red)
-		if changed && metric.Score < 67 {
+		// the score is not in the top band (with color yellow and red by default)
+		if changed && !score.IsTopScoreBand(transition.New) {
 			reportRiskArea = true
 		}
 	}
 
+	// skip risk notifications for a metric calculated from too little data
+	if score.IsLowConfidence(metric) {
+		logger.Info("skip risk notifications for low confidence metric", zap.Any("confidence", metric.Confidence))
+		symptomsSpikeAccounts = make([]string, 0)
+		stateChangedAccounts = make([]string, 0)
+		bandTransitions = make([]schema.ScoreBandTransition, 0)
+		reportRiskArea = false
+		remindGoodBehavior = false
+	}
+
 	logger.Debug("finish state refreshing",
 		zap.Any("stateChangedAccounts", stateChangedAccounts),
+		zap.Any("bandTransitions", bandTransitions),
 		zap.Any("symptomsSpikeAccounts", symptomsSpikeAccounts))
 
 	return &NotificationProfile{
 		StateChangedAccounts:  stateChangedAccounts,
+		BandTransitions:       bandTransitions,
 		SymptomsSpikeAccounts: symptomsSpikeAccounts,
 		ReportRiskArea:        reportRiskArea,
 		RemindGoodBehavior:    remindGoodBehavior,
@@ -231,7 +267,8 @@ func (s *ScoreUpdateWorker) RefreshLocationStateActivity(ctx context.Context, ac
 }
 
 // NotifyLocationStateActivity is to send notification to end users for notifing the
-// significant changes of location states.
+// significant changes of location states. It is kept for the workflows started before
+// notifications tell the band transitions.
 func (s *ScoreUpdateWorker) NotifyLocationStateActivity(ctx context.Context, id string, accounts []string) error {
 	logger := activity.GetLogger(ctx)
 	if len(accounts) == 0 {
@@ -255,6 +292,54 @@ func (s *ScoreUpdateWorker) NotifyLocationStateActivity(ctx context.Context, id
 	)
 }
 
+// NotifyLocationBandTransitionActivity is to send notification to end users for notifing the
+// significant changes of location states. Accounts are notified by groups of the same
+// band transition so that the notification could tell how the state changes.
+func (s *ScoreUpdateWorker) NotifyLocationBandTransitionActivity(ctx context.Context, id string, transitions []schema.ScoreBandTransition) error {
+	logger := activity.GetLogger(ctx)
+	if len(transitions) == 0 {
+		logger.Warn("Send notification without accounts")
+		return nil
+	}
+
+	template := viper.GetString("onesignal.template.saved_location_status_change")
+	if id == "" {
+		template = viper.GetString("onesignal.template.new_location_status_change")
+	}
+
+	type bandChange struct {
+		old, new string
+	}
+
+	accounts := make(map[bandChange][]string)
+	changes := make([]schema.ScoreBandTransition, 0)
+	for _, t := range transitions {
+		change := bandChange{t.Old.Name, t.New.Name}
+		if _, ok := accounts[change]; !ok {
+			changes = append(changes, t)
+		}
+		accounts[change] = append(accounts[change], t.AccountNumber)
+	}
+
+	for _, t := range changes {
+		data := map[string]interface{}{
+			"notification_type": "RISK_LEVEL_CHANGED",
+			"old_band":          t.Old.Name,
+			"new_band":          t.New.Name,
+			"direction":         t.Direction,
+		}
+		if id != "" {
+			data["poi_id"] = id
+		}
+
+		if err := s.notificationCenter.NotifyAccountsByTemplate(accounts[bandChange{t.Old.Name, t.New.Name}], template, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // CalculateAccountStateActivity calculates metrics by a given account's location
 func (s *ScoreUpdateWorker) CalculateAccountStateActivity(ctx context.Context, accountNumber string) (*schema.Metric, error) {
 	logger := activity.GetLogger(ctx)
@@ -276,11 +361,13 @@ func (s *ScoreUpdateWorker) CalculateAccountStateActivity(ctx context.Context, a
 	}
 
 	logger.Info("Calculate metric by location.", zap.Any("location", location))
-	rawMetrics, err := s.mongo.CollectRawMetrics(location)
+	formula := score.AccountFormula(accountNumber)
+	tz := utils.GetLocalLocation(profile.Timezone, location.Longitude)
+	rawMetrics, err := s.mongo.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
 	if err != nil {
 		return nil, err
 	}
 
-	metric := score.CalculateMetric(*rawMetrics, profile.ScoreCoefficient)
+	metric := formula.CalculateMetric(*rawMetrics, profile.ScoreCoefficient)
 	return &metric, nil
 }
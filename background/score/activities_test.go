@@ -9,6 +9,8 @@ import (
 	"github.com/bitmark-inc/autonomy-api/external/cadence"
 	"github.com/bitmark-inc/autonomy-api/mocks"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -83,6 +85,9 @@ func (ts *ScoreActivityTestSuite) SetupTest() {
 	testWorker.notificationCenter = nc
 	ts.mongoMock = mongoMock
 	ts.notificationMock = nc
+
+	// snapshots of refreshed metrics are not the concern of most tests
+	mongoMock.EXPECT().AddMetricHistory(gomock.Any()).Return(nil).AnyTimes()
 	ts.worker = testWorker
 }
 
@@ -105,7 +110,7 @@ func (ts *ScoreActivityTestSuite) TestCalculatePOIStateActivity() {
 		CollectRawMetrics(gomock.Eq(schema.Location{
 			Latitude:  regularPOI.Location.Coordinates[1],
 			Longitude: regularPOI.Location.Coordinates[0],
-		})).
+		}), gomock.Eq(score.DefaultScoreWindow), gomock.Eq(utils.GetLocation("GMT+8"))).
 		Return(&schema.Metric{}, nil)
 
 	values, err := ts.env.ExecuteActivity(ts.worker.CalculatePOIStateActivity, ts.testPOIID)
@@ -473,6 +478,10 @@ func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForAccountWher
 	ts.False(np.RemindGoodBehavior)
 	ts.Len(np.SymptomsSpikeAccounts, 0)
 	ts.Len(np.StateChangedAccounts, 1)
+	ts.Len(np.BandTransitions, 1)
+	ts.Equal("yellow", np.BandTransitions[0].Old.Name)
+	ts.Equal("green", np.BandTransitions[0].New.Name)
+	ts.Equal(schema.ScoreBandImproved, np.BandTransitions[0].Direction)
 }
 
 // TestRefreshLocationStateActivityForAccountOnEnteringHighRiskArea tests an account **SHOULD** be
@@ -509,6 +518,55 @@ func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForAccountOnEn
 	ts.False(np.RemindGoodBehavior)
 	ts.Len(np.SymptomsSpikeAccounts, 0)
 	ts.Len(np.StateChangedAccounts, 1)
+	ts.Len(np.BandTransitions, 1)
+	ts.Equal(ts.testAccountNumber, np.BandTransitions[0].AccountNumber)
+	ts.Equal(schema.ScoreBandWorsened, np.BandTransitions[0].Direction)
+}
+
+// TestRefreshLocationStateActivityForAccountWithLowConfidence tests an account **SHOULD NOT** be
+// marked `ReportRiskArea` or `RemindGoodBehavior`, nor produce any state change or band transition,
+// when the metric is in low confidence.
+func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForAccountWithLowConfidence() {
+	metricToUpdate := schema.Metric{
+		Score:        55,
+		SymptomDelta: 10,
+		Confidence: schema.Confidence{
+			Level: schema.ConfidenceLow,
+		},
+	}
+
+	ts.mongoMock.
+		EXPECT().
+		GetProfile(gomock.Eq(ts.testAccountNumber)).
+		Return(&schema.Profile{
+			AccountNumber: ts.testAccountNumber,
+			Timezone:      "GMT+8",
+			LastNudge: schema.NudgeTime{
+				schema.NudgeBehaviorOnSymptomSpikeArea: time.Now().Add(-100 * time.Minute),
+			},
+			Metric: schema.Metric{
+				LastUpdate:   time.Now().Unix(),
+				Score:        77,
+				SymptomDelta: 5,
+			},
+		}, nil)
+
+	ts.mongoMock.
+		EXPECT().
+		UpdateProfileMetric(gomock.Eq(ts.testAccountNumber), gomock.AssignableToTypeOf(schema.Metric{})).
+		Return(nil)
+
+	values, err := ts.env.ExecuteActivity(ts.worker.RefreshLocationStateActivity, ts.testAccountNumber, "", metricToUpdate)
+	ts.NoError(err)
+
+	var np NotificationProfile
+	err = values.Get(&np)
+	ts.NoError(err)
+	ts.False(np.ReportRiskArea)
+	ts.False(np.RemindGoodBehavior)
+	ts.Len(np.SymptomsSpikeAccounts, 0)
+	ts.Len(np.StateChangedAccounts, 0)
+	ts.Len(np.BandTransitions, 0)
 }
 
 // TestRefreshLocationStateActivityForAccountStayInHighRiskArea tests an account **SHOULD NOT** be
@@ -590,6 +648,62 @@ func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForPOINormalRu
 	ts.Len(np.StateChangedAccounts, 0)
 }
 
+// TestRefreshLocationStateActivityForPOICustomizedCoefficient tests the POI metric of an account is
+// recalculated with its customized weights, as the api does
+func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForPOICustomizedCoefficient() {
+	poiID, err := primitive.ObjectIDFromHex(ts.testPOIID)
+	ts.NoError(err)
+
+	metricToUpdate := schema.Metric{
+		Details: schema.Details{
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes: 10,
+				TodayDistribution: map[string]float64{
+					"clean_hand": 10,
+				},
+			},
+		},
+	}
+	coefficient := &schema.ScoreCoefficient{
+		Behaviors:       1,
+		BehaviorWeights: schema.BehaviorWeights{"clean_hand": 6},
+	}
+	expected := score.AccountFormula(ts.testAccountNumber).CalculateMetric(metricToUpdate, coefficient)
+
+	ts.mongoMock.
+		EXPECT().
+		UpdatePOIMetric(gomock.Eq(poiID), gomock.AssignableToTypeOf(schema.Metric{})).
+		Return(nil)
+
+	ts.mongoMock.
+		EXPECT().
+		GetProfilesByPOI(gomock.Eq(ts.testPOIID)).
+		Return([]schema.Profile{
+			{
+				AccountNumber:    ts.testAccountNumber,
+				Timezone:         "GMT+8",
+				ScoreCoefficient: coefficient,
+				PointsOfInterest: []schema.ProfilePOI{
+					{
+						ID: poiID,
+					},
+				},
+			},
+		}, nil)
+
+	ts.mongoMock.
+		EXPECT().
+		UpdateProfilePOIMetric(gomock.Eq(ts.testAccountNumber), gomock.Eq(poiID), gomock.AssignableToTypeOf(schema.Metric{})).
+		DoAndReturn(func(accountNumber string, id primitive.ObjectID, metric schema.Metric) error {
+			ts.Equal(expected.Details.Behaviors.Score, metric.Details.Behaviors.Score)
+			ts.Equal(expected.Score, metric.Score)
+			return nil
+		})
+
+	_, err = ts.env.ExecuteActivity(ts.worker.RefreshLocationStateActivity, "", ts.testPOIID, metricToUpdate)
+	ts.NoError(err)
+}
+
 // TestRefreshLocationStateActivityForPOILastSymptomSpikeInYesterday tests accounts should be
 // added into `SymptomsSpikeAccounts` if the last spike is yesterday
 func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForPOILastSymptomSpikeInYesterday() {
@@ -826,6 +940,9 @@ func (ts *ScoreActivityTestSuite) TestRefreshLocationStateActivityForPOILastStat
 	ts.Len(np.SymptomsSpikeAccounts, 0)
 	ts.Len(np.StateChangedAccounts, 1)
 	ts.Equal(fakeProfileAccount2, np.StateChangedAccounts[0])
+	ts.Len(np.BandTransitions, 1)
+	ts.Equal(fakeProfileAccount2, np.BandTransitions[0].AccountNumber)
+	ts.Equal(schema.ScoreBandImproved, np.BandTransitions[0].Direction)
 }
 
 // TestCalculateAccountStateActivityWithoutLocation tests CalculateAccountStateActivity
@@ -871,7 +988,7 @@ func (ts *ScoreActivityTestSuite) TestCalculateAccountStateActivityNormal() {
 		CollectRawMetrics(gomock.Eq(schema.Location{
 			Latitude:  testProfile.Location.Coordinates[1],
 			Longitude: testProfile.Location.Coordinates[0],
-		})).
+		}), gomock.Eq(score.DefaultScoreWindow), gomock.Eq(utils.GetLocation(testProfile.Timezone))).
 		Return(&schema.Metric{}, nil)
 
 	_, err := ts.env.ExecuteActivity(ts.worker.CalculateAccountStateActivity, ts.testAccountNumber)
@@ -902,7 +1019,7 @@ func (ts *ScoreActivityTestSuite) TestCalculateAccountStateActivityWithCollectEr
 		CollectRawMetrics(gomock.Eq(schema.Location{
 			Latitude:  testProfile.Location.Coordinates[1],
 			Longitude: testProfile.Location.Coordinates[0],
-		})).
+		}), gomock.Eq(score.DefaultScoreWindow), gomock.Eq(utils.GetLocation(testProfile.Timezone))).
 		Return(nil, fmt.Errorf("can not collect metrics"))
 
 	_, err := ts.env.ExecuteActivity(ts.worker.CalculateAccountStateActivity, ts.testAccountNumber)
@@ -915,27 +1032,221 @@ func (ts *ScoreActivityTestSuite) TestNotifyLocationStateActivity() {
 		gomock.AssignableToTypeOf(""),
 		gomock.Eq(map[string]interface{}{
 			"notification_type": "RISK_LEVEL_CHANGED",
+			"poi_id":            ts.testPOIID,
 		})).
 		Return(nil).Times(1)
 
-	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationStateActivity, "", []string{ts.testAccountNumber})
+	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationStateActivity, ts.testPOIID, []string{ts.testAccountNumber})
 	ts.NoError(err)
 }
 
-func (ts *ScoreActivityTestSuite) TestNotifyLocationStateActivityWithSpecificPOIID() {
+func (ts *ScoreActivityTestSuite) TestNotifyLocationBandTransitionActivity() {
+	ts.notificationMock.EXPECT().NotifyAccountsByTemplate(
+		gomock.Eq([]string{ts.testAccountNumber}),
+		gomock.AssignableToTypeOf(""),
+		gomock.Eq(map[string]interface{}{
+			"notification_type": "RISK_LEVEL_CHANGED",
+			"old_band":          "yellow",
+			"new_band":          "green",
+			"direction":         schema.ScoreBandImproved,
+		})).
+		Return(nil).Times(1)
+
+	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationBandTransitionActivity, "", []schema.ScoreBandTransition{
+		{
+			AccountNumber: ts.testAccountNumber,
+			Old:           score.GetScoreBand(50),
+			New:           score.GetScoreBand(80),
+			Direction:     schema.ScoreBandImproved,
+		},
+	})
+	ts.NoError(err)
+}
+
+func (ts *ScoreActivityTestSuite) TestNotifyLocationBandTransitionActivityWithSpecificPOIID() {
 	ts.notificationMock.EXPECT().NotifyAccountsByTemplate(
 		gomock.Eq([]string{ts.testAccountNumber}),
 		gomock.AssignableToTypeOf(""),
 		gomock.Eq(map[string]interface{}{
 			"notification_type": "RISK_LEVEL_CHANGED",
 			"poi_id":            ts.testPOIID,
+			"old_band":          "yellow",
+			"new_band":          "green",
+			"direction":         schema.ScoreBandImproved,
 		})).
 		Return(nil).Times(1)
 
-	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationStateActivity, ts.testPOIID, []string{ts.testAccountNumber})
+	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationBandTransitionActivity, ts.testPOIID, []schema.ScoreBandTransition{
+		{
+			AccountNumber: ts.testAccountNumber,
+			Old:           score.GetScoreBand(50),
+			New:           score.GetScoreBand(80),
+			Direction:     schema.ScoreBandImproved,
+		},
+	})
+	ts.NoError(err)
+}
+
+func (ts *ScoreActivityTestSuite) TestNotifyLocationBandTransitionActivityGroupsBandTransitions() {
+	ts.notificationMock.EXPECT().NotifyAccountsByTemplate(
+		gomock.Eq([]string{fakeProfileAccount1, fakeProfileAccount2}),
+		gomock.AssignableToTypeOf(""),
+		gomock.Eq(map[string]interface{}{
+			"notification_type": "RISK_LEVEL_CHANGED",
+			"old_band":          "yellow",
+			"new_band":          "green",
+			"direction":         schema.ScoreBandImproved,
+		})).
+		Return(nil).Times(1)
+
+	ts.notificationMock.EXPECT().NotifyAccountsByTemplate(
+		gomock.Eq([]string{ts.testAccountNumber}),
+		gomock.AssignableToTypeOf(""),
+		gomock.Eq(map[string]interface{}{
+			"notification_type": "RISK_LEVEL_CHANGED",
+			"old_band":          "yellow",
+			"new_band":          "red",
+			"direction":         schema.ScoreBandWorsened,
+		})).
+		Return(nil).Times(1)
+
+	yellow, green, red := score.GetScoreBand(50), score.GetScoreBand(80), score.GetScoreBand(10)
+	_, err := ts.env.ExecuteActivity(ts.worker.NotifyLocationBandTransitionActivity, "", []schema.ScoreBandTransition{
+		{AccountNumber: fakeProfileAccount1, Old: yellow, New: green, Direction: schema.ScoreBandImproved},
+		{AccountNumber: ts.testAccountNumber, Old: yellow, New: red, Direction: schema.ScoreBandWorsened},
+		{AccountNumber: fakeProfileAccount2, Old: yellow, New: green, Direction: schema.ScoreBandImproved},
+	})
 	ts.NoError(err)
 }
 
+// TestUpdateHeatmapCellsActivity tests a cell is scored at its center with its cached geo info
+func (ts *ScoreActivityTestSuite) TestUpdateHeatmapCellsActivity() {
+	geohash := "wsqqqm"
+	box, _ := utils.GeohashDecode(geohash)
+	lat, lon := box.Center()
+	address := schema.AddressComponent{Country: "Taiwan", State: "Taipei City", County: "Xinyi District"}
+
+	ts.mongoMock.
+		EXPECT().
+		GetHeatmapCell(gomock.Eq(geohash)).
+		Return(&schema.HeatmapCell{Geohash: geohash, Address: address}, nil)
+
+	ts.mongoMock.
+		EXPECT().
+		CollectRawMetrics(gomock.Eq(schema.Location{
+			AddressComponent: address,
+			Latitude:         lat,
+			Longitude:        lon,
+		}), gomock.Eq(score.DefaultScoreWindow), gomock.Eq(utils.GetLocation("GMT+8"))).
+		Return(&schema.Metric{
+			Confidence: schema.Confidence{Reporters: 30, ConfirmDataAge: 1},
+		}, nil)
+
+	ts.mongoMock.
+		EXPECT().
+		UpdateHeatmapCell(gomock.AssignableToTypeOf(schema.HeatmapCell{})).
+		DoAndReturn(func(cell schema.HeatmapCell) error {
+			ts.Equal(geohash, cell.Geohash)
+			ts.Equal([]float64{lon, lat}, cell.Center.Coordinates)
+			ts.Equal(address, cell.Address)
+			ts.Equal(30, cell.Confidence.Reporters)
+			ts.Equal(schema.ConfidenceHigh, cell.Confidence.Level)
+			return nil
+		})
+
+	_, err := ts.env.ExecuteActivity(ts.worker.UpdateHeatmapCellsActivity, []string{geohash, "invalid"})
+	ts.NoError(err)
+}
+
+// TestUpdateHeatmapCellsActivityWithoutUpdates tests an error is returned if no cell is updated
+func (ts *ScoreActivityTestSuite) TestUpdateHeatmapCellsActivityWithoutUpdates() {
+	_, err := ts.env.ExecuteActivity(ts.worker.UpdateHeatmapCellsActivity, []string{"invalid"})
+	ts.Error(err)
+}
+
+// TestFindRegionsActivity tests regions are identified by their IDs
+func (ts *ScoreActivityTestSuite) TestFindRegionsActivity() {
+	ts.mongoMock.
+		EXPECT().
+		FindRegions().
+		Return([]schema.Region{
+			{Country: "Taiwan"},
+			{Country: "Taiwan", County: "Taipei City"},
+		}, nil)
+
+	value, err := ts.env.ExecuteActivity(ts.worker.FindRegionsActivity)
+	ts.NoError(err)
+
+	var regionIDs []string
+	ts.NoError(value.Get(&regionIDs))
+	ts.Equal([]string{"Taiwan||", "Taiwan||Taipei City"}, regionIDs)
+}
+
+// TestUpdateRegionMetric tests the score delta of a region is the change from the last score of the previous day,
+// where days start at the midnight of the time zone of the region
+func (ts *ScoreActivityTestSuite) TestUpdateRegionMetric() {
+	region := schema.Region{Country: "United States", State: "New York", County: "Kings"}
+	now := time.Date(2020, 6, 2, 10, 0, 0, 0, time.UTC)
+
+	ts.mongoMock.
+		EXPECT().
+		CollectRegionRawMetrics(gomock.Eq(region.AddressComponent()), gomock.Eq(score.DefaultScoreWindow)).
+		Return(&schema.Metric{
+			Confidence: schema.Confidence{Reporters: 30, ConfirmDataAge: 1},
+		}, utils.GetLocation("GMT-5"), nil).
+		Times(2)
+
+	var saved schema.RegionMetric
+	ts.mongoMock.
+		EXPECT().
+		UpdateRegionMetric(gomock.AssignableToTypeOf(schema.RegionMetric{})).
+		DoAndReturn(func(metric schema.RegionMetric) error {
+			saved = metric
+			return nil
+		}).
+		Times(2)
+
+	// the last score of the previous day becomes the base of the delta, which is
+	// in the same day of UTC but the previous day in the time zone of the region
+	ts.mongoMock.
+		EXPECT().
+		GetRegionMetric(gomock.Eq(region.ID())).
+		Return(&schema.RegionMetric{
+			ID:            region.ID(),
+			Score:         60,
+			PreviousScore: 50,
+			UpdatedAt:     time.Date(2020, 6, 2, 2, 0, 0, 0, time.UTC),
+		}, nil)
+
+	ts.NoError(ts.worker.updateRegionMetric(region.ID(), now))
+	ts.Equal(region, saved.Region)
+	ts.Equal(schema.RegionLevelCounty, saved.Level)
+	ts.Equal(60.0, saved.PreviousScore)
+	ts.Equal(saved.Score-60, saved.ScoreDelta)
+	ts.Equal(schema.ConfidenceHigh, saved.Confidence.Level)
+
+	// the base is kept within the same day
+	ts.mongoMock.
+		EXPECT().
+		GetRegionMetric(gomock.Eq(region.ID())).
+		Return(&schema.RegionMetric{
+			ID:            region.ID(),
+			Score:         70,
+			PreviousScore: 60,
+			UpdatedAt:     time.Date(2020, 6, 2, 9, 0, 0, 0, time.UTC),
+		}, nil)
+
+	ts.NoError(ts.worker.updateRegionMetric(region.ID(), now))
+	ts.Equal(60.0, saved.PreviousScore)
+	ts.Equal(saved.Score-60, saved.ScoreDelta)
+}
+
+// TestUpdateRegionMetricsActivityWithoutUpdates tests an error is returned if no region is updated
+func (ts *ScoreActivityTestSuite) TestUpdateRegionMetricsActivityWithoutUpdates() {
+	_, err := ts.env.ExecuteActivity(ts.worker.UpdateRegionMetricsActivity, []string{"invalid"})
+	ts.Error(err)
+}
+
 func TestScoreActivity(t *testing.T) {
 	suite.Run(t, new(ScoreActivityTestSuite))
 }
@@ -0,0 +1,164 @@
+package score
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/activity"
+	cadenceClient "go.uber.org/cadence/client"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+
+	"github.com/bitmark-inc/autonomy-api/external/cadence"
+	"github.com/bitmark-inc/autonomy-api/geo"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+const (
+	HeatmapUpdateWorkflowID = "heatmap-update"
+	// HeatmapUpdateSchedule refreshes the heatmap at the beginning of every hour
+	HeatmapUpdateSchedule = "0 * * * *"
+
+	// heatmapBatchSize is the number of cells updated by an activity
+	heatmapBatchSize = 50
+)
+
+var heatmapActivityOptions = workflow.ActivityOptions{
+	ScheduleToStartTimeout: time.Minute,
+	StartToCloseTimeout:    10 * time.Minute,
+	HeartbeatTimeout:       time.Minute,
+}
+
+// StartHeatmapUpdateWorkflow schedules the heatmap update workflow. It does nothing if the workflow is scheduled.
+func StartHeatmapUpdateWorkflow(ctx context.Context, client *cadence.CadenceClient) error {
+	_, err := client.StartWorkflow(ctx, cadenceClient.StartWorkflowOptions{
+		ID:                           HeatmapUpdateWorkflowID,
+		TaskList:                     TaskListName,
+		ExecutionStartToCloseTimeout: time.Hour,
+		CronSchedule:                 HeatmapUpdateSchedule,
+		WorkflowIDReusePolicy:        cadenceClient.WorkflowIDReusePolicyAllowDuplicate,
+	}, "HeatmapUpdateWorkflow")
+
+	if _, ok := err.(*shared.WorkflowExecutionAlreadyStartedError); ok {
+		return nil
+	}
+
+	return err
+}
+
+// HeatmapUpdateWorkflow calculates the scores of the heatmap cells where symptoms or behaviors
+// are reported recently. The cells are updated by batches.
+func (s *ScoreUpdateWorker) HeatmapUpdateWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, heatmapActivityOptions)
+	logger := workflow.GetLogger(ctx)
+
+	var geohashes []string
+	if err := workflow.ExecuteActivity(ctx, s.FindHeatmapCellsActivity).Get(ctx, &geohashes); err != nil {
+		logger.Error("Fail to find heatmap cells", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Update heatmap cells", zap.Int("cells", len(geohashes)))
+
+	for i := 0; i < len(geohashes); i += heatmapBatchSize {
+		end := i + heatmapBatchSize
+		if end > len(geohashes) {
+			end = len(geohashes)
+		}
+
+		if err := workflow.ExecuteActivity(ctx, s.UpdateHeatmapCellsActivity, geohashes[i:end]).Get(ctx, nil); err != nil {
+			logger.Error("Fail to update heatmap cells", zap.Error(err))
+			sentry.CaptureException(err)
+		}
+	}
+
+	return nil
+}
+
+// FindHeatmapCellsActivity returns the geohashes of the cells where symptoms or behaviors
+// are reported in the current or the previous score window
+func (s *ScoreUpdateWorker) FindHeatmapCellsActivity(ctx context.Context) ([]string, error) {
+	window := score.FormulaScoreWindow(score.DefaultFormula().Version())
+	_, previous := score.ReportPeriods(window, time.Now().UTC())
+
+	return s.mongo.FindReportedGeohashes(schema.HeatmapCellPrecision, previous.Start.Unix())
+}
+
+// UpdateHeatmapCellsActivity calculates the scores of cells at their centers. A cell which
+// fails to be updated is skipped, and an error is returned only if no cell is updated.
+func (s *ScoreUpdateWorker) UpdateHeatmapCellsActivity(ctx context.Context, geohashes []string) error {
+	logger := activity.GetLogger(ctx)
+
+	var lastErr error
+	updated := 0
+	for i, geohash := range geohashes {
+		activity.RecordHeartbeat(ctx, i)
+
+		if err := s.updateHeatmapCell(geohash); err != nil {
+			logger.Warn("Fail to update heatmap cell", zap.String("geohash", geohash), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		updated++
+	}
+
+	if updated == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	return nil
+}
+
+func (s *ScoreUpdateWorker) updateHeatmapCell(geohash string) error {
+	box, ok := utils.GeohashDecode(geohash)
+	if !ok {
+		return fmt.Errorf("invalid geohash: %s", geohash)
+	}
+
+	lat, lon := box.Center()
+	location := schema.Location{
+		Latitude:  lat,
+		Longitude: lon,
+	}
+
+	// reuse the political geo info of the cell to save the queries to the external service
+	cell, err := s.mongo.GetHeatmapCell(geohash)
+	if err != nil {
+		return err
+	}
+	if cell != nil && cell.Address.Country != "" {
+		location.AddressComponent = cell.Address
+	} else {
+		location, err = geo.PoliticalGeoInfo(location)
+		if err != nil {
+			return err
+		}
+	}
+
+	formula := score.DefaultFormula()
+	tz := utils.GetLocalLocation("", location.Longitude)
+	rawMetrics, err := s.mongo.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
+	if err != nil {
+		return err
+	}
+
+	metric := formula.CalculateMetric(*rawMetrics, nil)
+
+	return s.mongo.UpdateHeatmapCell(schema.HeatmapCell{
+		Geohash: geohash,
+		Center: schema.GeoJSON{
+			Type:        "Point",
+			Coordinates: []float64{lon, lat},
+		},
+		Address:    location.AddressComponent,
+		Score:      metric.Score,
+		Confidence: metric.Confidence,
+		Suppressed: metric.Suppressed,
+		UpdatedAt:  time.Now().UTC(),
+	})
+}
@@ -0,0 +1,160 @@
+package score
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/activity"
+	cadenceClient "go.uber.org/cadence/client"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+
+	"github.com/bitmark-inc/autonomy-api/external/cadence"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+)
+
+const (
+	RegionMetricUpdateWorkflowID = "region-metric-update"
+	// RegionMetricUpdateSchedule refreshes the region metrics at the half of every hour
+	RegionMetricUpdateSchedule = "30 * * * *"
+
+	// regionMetricBatchSize is the number of regions updated by an activity
+	regionMetricBatchSize = 20
+)
+
+var regionMetricActivityOptions = workflow.ActivityOptions{
+	ScheduleToStartTimeout: time.Minute,
+	StartToCloseTimeout:    10 * time.Minute,
+	HeartbeatTimeout:       time.Minute,
+}
+
+// StartRegionMetricUpdateWorkflow schedules the region metric update workflow. It does nothing if the workflow is scheduled.
+func StartRegionMetricUpdateWorkflow(ctx context.Context, client *cadence.CadenceClient) error {
+	_, err := client.StartWorkflow(ctx, cadenceClient.StartWorkflowOptions{
+		ID:                           RegionMetricUpdateWorkflowID,
+		TaskList:                     TaskListName,
+		ExecutionStartToCloseTimeout: time.Hour,
+		CronSchedule:                 RegionMetricUpdateSchedule,
+		WorkflowIDReusePolicy:        cadenceClient.WorkflowIDReusePolicyAllowDuplicate,
+	}, "RegionMetricUpdateWorkflow")
+
+	if _, ok := err.(*shared.WorkflowExecutionAlreadyStartedError); ok {
+		return nil
+	}
+
+	return err
+}
+
+// RegionMetricUpdateWorkflow calculates the scores of the regions of all levels for the region ranking.
+// The regions are updated by batches.
+func (s *ScoreUpdateWorker) RegionMetricUpdateWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, regionMetricActivityOptions)
+	logger := workflow.GetLogger(ctx)
+
+	var regionIDs []string
+	if err := workflow.ExecuteActivity(ctx, s.FindRegionsActivity).Get(ctx, &regionIDs); err != nil {
+		logger.Error("Fail to find regions", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Update region metrics", zap.Int("regions", len(regionIDs)))
+
+	for i := 0; i < len(regionIDs); i += regionMetricBatchSize {
+		end := i + regionMetricBatchSize
+		if end > len(regionIDs) {
+			end = len(regionIDs)
+		}
+
+		if err := workflow.ExecuteActivity(ctx, s.UpdateRegionMetricsActivity, regionIDs[i:end]).Get(ctx, nil); err != nil {
+			logger.Error("Fail to update region metrics", zap.Error(err))
+			sentry.CaptureException(err)
+		}
+	}
+
+	return nil
+}
+
+// FindRegionsActivity returns the IDs of the regions of all levels
+func (s *ScoreUpdateWorker) FindRegionsActivity(ctx context.Context) ([]string, error) {
+	regions, err := s.mongo.FindRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	regionIDs := make([]string, 0, len(regions))
+	for _, r := range regions {
+		regionIDs = append(regionIDs, r.ID())
+	}
+
+	return regionIDs, nil
+}
+
+// UpdateRegionMetricsActivity calculates the scores of regions. A region which fails to be
+// updated is skipped, and an error is returned only if no region is updated.
+func (s *ScoreUpdateWorker) UpdateRegionMetricsActivity(ctx context.Context, regionIDs []string) error {
+	logger := activity.GetLogger(ctx)
+
+	var lastErr error
+	updated := 0
+	for i, id := range regionIDs {
+		activity.RecordHeartbeat(ctx, i)
+
+		if err := s.updateRegionMetric(id, time.Now().UTC()); err != nil {
+			logger.Warn("Fail to update region metric", zap.String("region", id), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		updated++
+	}
+
+	if updated == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	return nil
+}
+
+// updateRegionMetric calculates the score of a region, where days start at the midnight of the
+// time zone of the region as the region APIs do. The score delta is the change from the last
+// score of the previous day.
+func (s *ScoreUpdateWorker) updateRegionMetric(id string, now time.Time) error {
+	region, err := schema.ParseRegionID(id)
+	if err != nil {
+		return err
+	}
+
+	formula := score.DefaultFormula()
+	rawMetrics, tz, err := s.mongo.CollectRegionRawMetrics(region.AddressComponent(), score.FormulaScoreWindow(formula.Version()))
+	if err != nil {
+		return err
+	}
+
+	metric := formula.CalculateMetric(*rawMetrics, nil)
+
+	previous, err := s.mongo.GetRegionMetric(id)
+	if err != nil {
+		return err
+	}
+
+	previousScore := metric.Score
+	if previous != nil {
+		previousScore = previous.PreviousScore
+		if previous.UpdatedAt.In(tz).Format("2006-01-02") != now.In(tz).Format("2006-01-02") {
+			previousScore = previous.Score
+		}
+	}
+
+	return s.mongo.UpdateRegionMetric(schema.RegionMetric{
+		ID:            id,
+		Region:        region,
+		Level:         region.Level(),
+		Score:         metric.Score,
+		ScoreDelta:    metric.Score - previousScore,
+		PreviousScore: previousScore,
+		Confidence:    metric.Confidence,
+		UpdatedAt:     now,
+	})
+}
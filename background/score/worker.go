@@ -41,14 +41,23 @@ func NewScoreUpdateWorker(domain string, mongo store.MongoStore) *ScoreUpdateWor
 func (s *ScoreUpdateWorker) Register() {
 	workflow.RegisterWithOptions(s.POIStateUpdateWorkflow, workflow.RegisterOptions{Name: "POIStateUpdateWorkflow"})
 	workflow.RegisterWithOptions(s.AccountStateUpdateWorkflow, workflow.RegisterOptions{Name: "AccountStateUpdateWorkflow"})
+	workflow.RegisterWithOptions(s.HeatmapUpdateWorkflow, workflow.RegisterOptions{Name: "HeatmapUpdateWorkflow"})
+	workflow.RegisterWithOptions(s.RegionMetricUpdateWorkflow, workflow.RegisterOptions{Name: "RegionMetricUpdateWorkflow"})
 
 	activity.RegisterWithOptions(s.CalculatePOIStateActivity, activity.RegisterOptions{Name: "CalculatePOIStateActivity"})
 	activity.RegisterWithOptions(s.CalculateAccountStateActivity, activity.RegisterOptions{Name: "CalculateAccountStateActivity"})
 
 	activity.RegisterWithOptions(s.RefreshLocationStateActivity, activity.RegisterOptions{Name: "RefreshLocationStateActivity"})
 	activity.RegisterWithOptions(s.NotifyLocationStateActivity, activity.RegisterOptions{Name: "NotifyLocationStateActivity"})
+	activity.RegisterWithOptions(s.NotifyLocationBandTransitionActivity, activity.RegisterOptions{Name: "NotifyLocationBandTransitionActivity"})
 
 	activity.RegisterWithOptions(s.CheckLocationSpikeActivity, activity.RegisterOptions{Name: "CheckLocationSpikeActivity"})
+
+	activity.RegisterWithOptions(s.FindHeatmapCellsActivity, activity.RegisterOptions{Name: "FindHeatmapCellsActivity"})
+	activity.RegisterWithOptions(s.UpdateHeatmapCellsActivity, activity.RegisterOptions{Name: "UpdateHeatmapCellsActivity"})
+
+	activity.RegisterWithOptions(s.FindRegionsActivity, activity.RegisterOptions{Name: "FindRegionsActivity"})
+	activity.RegisterWithOptions(s.UpdateRegionMetricsActivity, activity.RegisterOptions{Name: "UpdateRegionMetricsActivity"})
 }
 
 func (s *ScoreUpdateWorker) Start(service workflowserviceclient.Interface, logger *zap.Logger) {
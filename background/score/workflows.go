@@ -18,6 +18,10 @@ const (
 	AccountStateCheckInterval = 5 * time.Minute
 )
 
+// notifyBandTransitionChangeID versions the workflows which notify band transitions
+// instead of the accounts whose location states change
+const notifyBandTransitionChangeID = "notify-band-transition"
+
 var activityOptions = workflow.ActivityOptions{
 	ScheduleToStartTimeout: time.Minute,
 	StartToCloseTimeout:    time.Minute,
@@ -62,8 +66,7 @@ func (s *ScoreUpdateWorker) POIStateUpdateWorkflow(ctx workflow.Context, id stri
 	}
 
 	if len(np.StateChangedAccounts) > 0 {
-		err := workflow.ExecuteActivity(ctx, s.NotifyLocationStateActivity, id, np.StateChangedAccounts).Get(ctx, nil)
-		if err != nil {
+		if err := s.notifyLocationState(ctx, id, np); err != nil {
 			logger.Error("Fail to notify users for location state", zap.Error(err))
 			sentry.CaptureException(err)
 		}
@@ -136,8 +139,7 @@ func (s *ScoreUpdateWorker) AccountStateUpdateWorkflow(ctx workflow.Context, acc
 	logger.Info("RefreshLocationStateActivity.", zap.Any("NotificationProfile", np))
 
 	if len(np.StateChangedAccounts) > 0 {
-		err := workflow.ExecuteActivity(ctx, s.NotifyLocationStateActivity, "", np.StateChangedAccounts).Get(ctx, nil)
-		if err != nil {
+		if err := s.notifyLocationState(ctx, "", np); err != nil {
 			logger.Error("Fail to notify users for location state", zap.Error(err))
 			sentry.CaptureException(err)
 		}
@@ -198,3 +200,13 @@ func (s *ScoreUpdateWorker) AccountStateUpdateWorkflow(ctx workflow.Context, acc
 
 	return workflow.NewContinueAsNewError(ctx, s.AccountStateUpdateWorkflow, accountNumber)
 }
+
+// notifyLocationState notifies the accounts whose location states change. Workflows started
+// before notifications tell the band transitions keep notifying by the legacy activity.
+func (s *ScoreUpdateWorker) notifyLocationState(ctx workflow.Context, id string, np NotificationProfile) error {
+	v := workflow.GetVersion(ctx, notifyBandTransitionChangeID, workflow.DefaultVersion, 1)
+	if v == workflow.DefaultVersion {
+		return workflow.ExecuteActivity(ctx, s.NotifyLocationStateActivity, id, np.StateChangedAccounts).Get(ctx, nil)
+	}
+	return workflow.ExecuteActivity(ctx, s.NotifyLocationBandTransitionActivity, id, np.BandTransitions).Get(ctx, nil)
+}
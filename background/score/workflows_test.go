@@ -2,10 +2,15 @@ package score
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/bitmark-inc/autonomy-api/external/cadence"
+	"github.com/bitmark-inc/autonomy-api/mocks"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
@@ -31,6 +36,11 @@ var (
 var (
 	fakeAccount1 = "fcqu8Deozrzv6pQ5EqSsdvAHG1SbTafHqviUjVvP1mDmbPyiBU"
 	fakeAccount2 = "eEfqMcw7ExsoUhULQ7H41r5avLJxpzPWf4vVm6pGWB1o2wvyjR"
+
+	fakeBandTransitions = []schema.ScoreBandTransition{
+		{AccountNumber: fakeAccount1, Old: score.GetScoreBand(50), New: score.GetScoreBand(80), Direction: schema.ScoreBandImproved},
+		{AccountNumber: fakeAccount2, Old: score.GetScoreBand(50), New: score.GetScoreBand(80), Direction: schema.ScoreBandImproved},
+	}
 )
 
 type ScoreWorkflowTestSuite struct {
@@ -87,7 +97,7 @@ func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowNormalRun() {
 }
 
 // TestAccountStateUpdateWorkflowStateChange validate whether
-// `NotifyLocationStateActivity` is triggered when the state of a location changes
+// `NotifyLocationBandTransitionActivity` is triggered when the state of a location changes
 func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowStateChange() {
 	ts.env.OnActivity(ts.worker.CalculateAccountStateActivity, mock.Anything, mock.Anything).Return(
 		func(ctx context.Context, accountNumber string) (*schema.Metric, error) {
@@ -101,13 +111,14 @@ func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowStateChange() {
 			ts.Equal("", poiID)
 			return &NotificationProfile{
 				StateChangedAccounts: []string{fakeAccount1, fakeAccount2},
+				BandTransitions:      fakeBandTransitions,
 			}, nil
 		})
 
-	ts.env.OnActivity(ts.worker.NotifyLocationStateActivity, mock.Anything, mock.Anything, mock.Anything).Return(
-		func(ctx context.Context, id string, accounts []string) error {
+	ts.env.OnActivity(ts.worker.NotifyLocationBandTransitionActivity, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, id string, transitions []schema.ScoreBandTransition) error {
 			ts.Equal("", id)
-			ts.Equal([]string{fakeAccount1, fakeAccount2}, accounts)
+			ts.Equal(fakeBandTransitions, transitions)
 			return nil
 		})
 
@@ -121,13 +132,91 @@ func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowStateChange() {
 
 	ts.env.AssertNumberOfCalls(ts.T(), "CalculateAccountStateActivity", 1)
 	ts.env.AssertNumberOfCalls(ts.T(), "RefreshLocationStateActivity", 1)
-	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationStateActivity", 1)
+	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationBandTransitionActivity", 1)
 	ts.env.AssertNumberOfCalls(ts.T(), "CheckLocationSpikeActivity", 1)
 
 	ts.True(ts.env.IsWorkflowCompleted())
 	ts.EqualError(ts.env.GetWorkflowError(), "ContinueAsNew")
 }
 
+// TestAccountStateUpdateWorkflowLowConfidence validates that a score change in low confidence
+// runs the real `RefreshLocationStateActivity` without triggering `NotifyLocationBandTransitionActivity`
+func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowLowConfidence() {
+	mockCtrl := gomock.NewController(ts.T())
+	defer mockCtrl.Finish()
+
+	mongo := mocks.NewMockMongoStore(mockCtrl)
+	testWorker.mongo = mongo
+
+	mongo.EXPECT().AddMetricHistory(gomock.Any()).Return(nil).AnyTimes()
+	mongo.EXPECT().
+		GetProfile(gomock.Eq(ts.testAccountNumber)).
+		Return(&schema.Profile{
+			AccountNumber: ts.testAccountNumber,
+			Metric: schema.Metric{
+				LastUpdate: time.Now().Unix(),
+				Score:      77,
+			},
+		}, nil)
+	mongo.EXPECT().
+		UpdateProfileMetric(gomock.Eq(ts.testAccountNumber), gomock.AssignableToTypeOf(schema.Metric{})).
+		Return(nil)
+
+	ts.env.OnActivity(ts.worker.CalculateAccountStateActivity, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, accountNumber string) (*schema.Metric, error) {
+			ts.Equal(ts.testAccountNumber, accountNumber)
+			return &schema.Metric{
+				Score:      30,
+				Confidence: schema.Confidence{Level: schema.ConfidenceLow},
+			}, nil
+		})
+
+	ts.env.OnActivity(ts.worker.NotifyLocationBandTransitionActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ts.env.OnActivity(ts.worker.CheckLocationSpikeActivity, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, symptoms []string) ([]schema.Symptom, error) {
+			return []schema.Symptom{}, nil
+		})
+
+	ts.env.ExecuteWorkflow(ts.worker.AccountStateUpdateWorkflow, ts.testAccountNumber)
+
+	ts.env.AssertNumberOfCalls(ts.T(), "CalculateAccountStateActivity", 1)
+	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationBandTransitionActivity", 0)
+	ts.env.AssertNumberOfCalls(ts.T(), "CheckLocationSpikeActivity", 1)
+	ts.True(ts.env.IsWorkflowCompleted())
+	ts.EqualError(ts.env.GetWorkflowError(), "ContinueAsNew")
+}
+
+// TestAccountStateUpdateWorkflowStateChangeLegacyVersion validates whether the workflows started
+// before notifications tell the band transitions keep triggering `NotifyLocationStateActivity`
+func (ts *ScoreWorkflowTestSuite) TestAccountStateUpdateWorkflowStateChangeLegacyVersion() {
+	ts.env.OnGetVersion(notifyBandTransitionChangeID, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+
+	ts.env.OnActivity(ts.worker.CalculateAccountStateActivity, mock.Anything, mock.Anything).Return(twoSpikeMetric, nil)
+
+	ts.env.OnActivity(ts.worker.RefreshLocationStateActivity, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		&NotificationProfile{
+			StateChangedAccounts: []string{fakeAccount1, fakeAccount2},
+			BandTransitions:      fakeBandTransitions,
+		}, nil)
+
+	ts.env.OnActivity(ts.worker.NotifyLocationStateActivity, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, id string, accounts []string) error {
+			ts.Equal("", id)
+			ts.Equal([]string{fakeAccount1, fakeAccount2}, accounts)
+			return nil
+		})
+
+	ts.env.OnActivity(ts.worker.CheckLocationSpikeActivity, mock.Anything, mock.Anything, mock.Anything).Return([]schema.Symptom{}, nil)
+
+	ts.env.ExecuteWorkflow(ts.worker.AccountStateUpdateWorkflow, ts.testAccountNumber)
+
+	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationStateActivity", 1)
+	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationBandTransitionActivity", 0)
+	ts.True(ts.env.IsWorkflowCompleted())
+	ts.EqualError(ts.env.GetWorkflowError(), "ContinueAsNew")
+}
+
 // TestAccountStateUpdateWorkflowNotifySpike validate whether
 // `NotifySymptomSpikeWorkflow` is triggered when there are new symptom spikes
 // found for the account
@@ -305,13 +394,14 @@ func (ts *ScoreWorkflowTestSuite) TestPOIStateUpdateWorkflowStateChange() {
 			ts.Equal(ts.testPOIID, poiID)
 			return &NotificationProfile{
 				StateChangedAccounts: []string{fakeAccount1, fakeAccount2},
+				BandTransitions:      fakeBandTransitions,
 			}, nil
 		})
 
-	ts.env.OnActivity(ts.worker.NotifyLocationStateActivity, mock.Anything, mock.Anything, mock.Anything).Return(
-		func(ctx context.Context, id string, accounts []string) error {
+	ts.env.OnActivity(ts.worker.NotifyLocationBandTransitionActivity, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, id string, transitions []schema.ScoreBandTransition) error {
 			ts.Equal(ts.testPOIID, id)
-			ts.Equal([]string{fakeAccount1, fakeAccount2}, accounts)
+			ts.Equal(fakeBandTransitions, transitions)
 			return nil
 		})
 
@@ -328,7 +418,7 @@ func (ts *ScoreWorkflowTestSuite) TestPOIStateUpdateWorkflowStateChange() {
 
 	ts.env.AssertNumberOfCalls(ts.T(), "CalculatePOIStateActivity", 1)
 	ts.env.AssertNumberOfCalls(ts.T(), "RefreshLocationStateActivity", 1)
-	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationStateActivity", 1)
+	ts.env.AssertNumberOfCalls(ts.T(), "NotifyLocationBandTransitionActivity", 1)
 	ts.env.AssertNumberOfCalls(ts.T(), "CheckLocationSpikeActivity", 1)
 	ts.True(ts.env.IsWorkflowCompleted())
 	ts.EqualError(ts.env.GetWorkflowError(), "ContinueAsNew")
@@ -380,6 +470,52 @@ func (ts *ScoreWorkflowTestSuite) TestPOIStateUpdateWorkflowWithSymptomSpike() {
 	ts.EqualError(ts.env.GetWorkflowError(), "ContinueAsNew")
 }
 
+// TestHeatmapUpdateWorkflow tests heatmap cells are updated by batches
+func (ts *ScoreWorkflowTestSuite) TestHeatmapUpdateWorkflow() {
+	geohashes := make([]string, 120)
+	for i := range geohashes {
+		geohashes[i] = fmt.Sprintf("wsqq%02d", i)
+	}
+
+	ts.env.OnActivity(ts.worker.FindHeatmapCellsActivity, mock.Anything).Return(geohashes, nil)
+
+	batches := make([]int, 0)
+	ts.env.OnActivity(ts.worker.UpdateHeatmapCellsActivity, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, geohashes []string) error {
+			batches = append(batches, len(geohashes))
+			return nil
+		})
+
+	ts.env.ExecuteWorkflow(ts.worker.HeatmapUpdateWorkflow)
+
+	ts.True(ts.env.IsWorkflowCompleted())
+	ts.NoError(ts.env.GetWorkflowError())
+	ts.Equal([]int{50, 50, 20}, batches)
+}
+
+// TestRegionMetricUpdateWorkflow tests region metrics are updated by batches
+func (ts *ScoreWorkflowTestSuite) TestRegionMetricUpdateWorkflow() {
+	regionIDs := make([]string, 45)
+	for i := range regionIDs {
+		regionIDs[i] = schema.Region{Country: "Taiwan", County: fmt.Sprintf("County %d", i)}.ID()
+	}
+
+	ts.env.OnActivity(ts.worker.FindRegionsActivity, mock.Anything).Return(regionIDs, nil)
+
+	batches := make([]int, 0)
+	ts.env.OnActivity(ts.worker.UpdateRegionMetricsActivity, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, regionIDs []string) error {
+			batches = append(batches, len(regionIDs))
+			return nil
+		})
+
+	ts.env.ExecuteWorkflow(ts.worker.RegionMetricUpdateWorkflow)
+
+	ts.True(ts.env.IsWorkflowCompleted())
+	ts.NoError(ts.env.GetWorkflowError())
+	ts.Equal([]int{20, 20, 5}, batches)
+}
+
 func TestScoreUpdateWorkflow(t *testing.T) {
 	suite.Run(t, new(ScoreWorkflowTestSuite))
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bitmark-inc/autonomy-api/external/cdc"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/store"
+)
+
+// retryPolicy retries a failed fetch with exponential backoff. The delay starts from
+// Initial and is doubled after each attempt, up to Max.
+type retryPolicy struct {
+	Attempts int           `mapstructure:"attempts"`
+	Initial  time.Duration `mapstructure:"initial"`
+	Max      time.Duration `mapstructure:"max"`
+}
+
+var defaultRetryPolicy = retryPolicy{
+	Attempts: 5,
+	Initial:  30 * time.Second,
+	Max:      10 * time.Minute,
+}
+
+// withDefaults fills the fields not given by the default values
+func (p retryPolicy) withDefaults() retryPolicy {
+	if p.Attempts <= 0 {
+		p.Attempts = defaultRetryPolicy.Attempts
+	}
+	if p.Initial <= 0 {
+		p.Initial = defaultRetryPolicy.Initial
+	}
+	if p.Max <= 0 {
+		p.Max = defaultRetryPolicy.Max
+	}
+	return p
+}
+
+// delay returns how long to wait after the given attempt fails
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.Initial
+	for i := 1; i < attempt && d < p.Max; i++ {
+		d *= 2
+	}
+	if d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+type confirmCrawler struct {
+	mongoStore store.MongoStore
+	source     cdc.ConfirmSource
+	retry      retryPolicy
+	validation cdc.Validation
+	after      func(time.Duration) <-chan time.Time
+}
+
+// Run crawls the source and saves its records. Each run is recorded whether it succeeds or not.
+func (c confirmCrawler) Run(ctx context.Context) {
+	_ = c.run(ctx)
+}
+
+// run crawls the source and records the run. It returns the error which fails the run.
+func (c confirmCrawler) run(ctx context.Context) error {
+	fields := log.Fields{"prefix": logPrefix, "source": c.source.Name(), "country": c.source.Country(), "level": c.source.Level()}
+
+	run := schema.CrawlRun{
+		Source:    c.source.Name(),
+		Country:   c.source.Country(),
+		Level:     c.source.Level(),
+		StartedAt: time.Now().UTC(),
+	}
+
+	err := c.crawl(ctx, &run)
+	run.EndedAt = time.Now().UTC()
+	if err != nil {
+		run.Error = err.Error()
+		log.WithFields(fields).WithError(err).WithField("attempts", run.Attempts).Error("crawl confirm source")
+	} else {
+		run.Succeeded = true
+		log.WithFields(fields).WithField("data count", run.Records).Debug("data from confirm source")
+	}
+	if run.Quarantined > 0 {
+		log.WithFields(fields).WithField("quarantined", run.Quarantined).Warn("confirm data fails validation")
+	}
+
+	if err := c.mongoStore.AddCrawlRun(run); err != nil {
+		log.WithFields(fields).WithError(err).Error("record crawl run")
+	}
+
+	return err
+}
+
+// crawl fetches the data of the source, which is retried on failure until the context is done, and saves
+// the normalized records. Records failing the validation are quarantined instead.
+func (c confirmCrawler) crawl(ctx context.Context, run *schema.CrawlRun) error {
+	var data []byte
+	var err error
+	for run.Attempts = 1; ; run.Attempts++ {
+		data, err = c.source.Fetch(ctx)
+		if err == nil {
+			break
+		}
+		if run.Attempts >= c.retry.Attempts {
+			return fmt.Errorf("fetch confirm data: %w", err)
+		}
+
+		delay := c.retry.delay(run.Attempts)
+		log.WithFields(log.Fields{
+			"prefix":  logPrefix,
+			"source":  c.source.Name(),
+			"attempt": run.Attempts,
+			"delay":   delay,
+			"error":   err,
+		}).Warn("retry fetching confirm data")
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("fetch confirm data: %w", ctx.Err())
+		case <-c.after(delay):
+		}
+	}
+
+	records, err := c.source.Normalize(data)
+	if err != nil {
+		return fmt.Errorf("normalize confirm data: %w", err)
+	}
+
+	valid, quarantined, err := c.validation.Validate(records, c.mongoStore.PreviousCDS)
+	if err != nil {
+		return fmt.Errorf("validate confirm data: %w", err)
+	}
+
+	for i := range quarantined {
+		quarantined[i].Source = c.source.Name()
+	}
+	if err := c.mongoStore.QuarantineCDS(quarantined); err != nil {
+		return fmt.Errorf("quarantine confirm data: %w", err)
+	}
+	run.Quarantined = len(quarantined)
+
+	if err := c.mongoStore.ReplaceCDS(valid); err != nil {
+		return fmt.Errorf("save confirm data: %w", err)
+	}
+	run.Records = len(valid)
+
+	return nil
+}
+
+// newConfirmCrawler - new cron job crawling a confirm source
+func newConfirmCrawler(mongoStore store.MongoStore, source cdc.ConfirmSource, retry retryPolicy, validation cdc.Validation) Cron {
+	return &confirmCrawler{
+		mongoStore: mongoStore,
+		source:     source,
+		retry:      retry.withDefaults(),
+		validation: validation.WithDefaults(),
+		after:      time.After,
+	}
+}
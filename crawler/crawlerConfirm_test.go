@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/external/cdc"
+	"github.com/bitmark-inc/autonomy-api/mocks"
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+// fakeSource fails to fetch for the given number of times
+type fakeSource struct {
+	failures int
+	fetched  int
+	records  []schema.CDSData
+}
+
+func (s *fakeSource) Name() string    { return "fake" }
+func (s *fakeSource) Country() string { return schema.CdsTaiwan }
+func (s *fakeSource) Level() string   { return schema.CDSLevelCountry }
+
+func (s *fakeSource) Fetch(ctx context.Context) ([]byte, error) {
+	s.fetched++
+	if s.fetched <= s.failures {
+		return nil, fmt.Errorf("service unavailable")
+	}
+	return []byte("[]"), nil
+}
+
+func (s *fakeSource) Normalize([]byte) ([]schema.CDSData, error) {
+	return s.records, nil
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := retryPolicy{Attempts: 5, Initial: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, time.Second, p.delay(1))
+	assert.Equal(t, 2*time.Second, p.delay(2))
+	assert.Equal(t, 4*time.Second, p.delay(3))
+	assert.Equal(t, 5*time.Second, p.delay(4))
+	assert.Equal(t, 5*time.Second, p.delay(10))
+
+	assert.Equal(t, defaultRetryPolicy, retryPolicy{}.withDefaults())
+}
+
+func TestConfirmCrawlerRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	records := []schema.CDSData{{Name: schema.CdsTaiwan, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry}}
+	source := &fakeSource{failures: 2, records: records}
+
+	var delays []time.Duration
+	c := newConfirmCrawler(mongoStore, source, retryPolicy{Attempts: 3, Initial: time.Second, Max: time.Minute}, cdc.Validation{}).(*confirmCrawler)
+	c.after = func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		return time.After(0)
+	}
+
+	mongoStore.EXPECT().PreviousCDS(records[0]).Return(nil, nil)
+	mongoStore.EXPECT().QuarantineCDS(gomock.Len(0)).Return(nil)
+	mongoStore.EXPECT().ReplaceCDS(records).Return(nil)
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.Equal(t, "fake", run.Source)
+		assert.Equal(t, 3, run.Attempts)
+		assert.Equal(t, 1, run.Records)
+		assert.True(t, run.Succeeded)
+		assert.Empty(t, run.Error)
+		assert.False(t, run.EndedAt.Before(run.StartedAt))
+		return nil
+	})
+
+	c.Run(context.Background())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, delays)
+}
+
+func TestConfirmCrawlerFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	source := &fakeSource{failures: 5}
+	c := newConfirmCrawler(mongoStore, source, retryPolicy{Attempts: 3, Initial: time.Second, Max: time.Minute}, cdc.Validation{}).(*confirmCrawler)
+	c.after = func(time.Duration) <-chan time.Time { return time.After(0) }
+
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.Equal(t, 3, run.Attempts)
+		assert.False(t, run.Succeeded)
+		assert.Contains(t, run.Error, "service unavailable")
+		return nil
+	})
+
+	c.Run(context.Background())
+	assert.Equal(t, 3, source.fetched)
+}
+
+// TestConfirmCrawlerRetryCanceled tests the retry stops waiting once the context is done
+func TestConfirmCrawlerRetryCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	source := &fakeSource{failures: 5}
+	c := newConfirmCrawler(mongoStore, source, retryPolicy{Attempts: 3, Initial: time.Hour, Max: time.Hour}, cdc.Validation{}).(*confirmCrawler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.Equal(t, 1, run.Attempts)
+		assert.False(t, run.Succeeded)
+		assert.Contains(t, run.Error, context.Canceled.Error())
+		return nil
+	})
+
+	c.Run(ctx)
+	assert.Equal(t, 1, source.fetched)
+}
+
+func TestConfirmCrawlerQuarantine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	previous := schema.CDSData{Name: schema.CdsTaiwan, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry,
+		Cases: 400, Active: 100, ReportTime: 1590364800}
+	records := []schema.CDSData{
+		{Name: schema.CdsTaiwan, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry, Cases: 410, Active: 5, ReportTime: 1590451200},
+	}
+	c := newConfirmCrawler(mongoStore, &fakeSource{records: records}, retryPolicy{}, cdc.Validation{}).(*confirmCrawler)
+
+	mongoStore.EXPECT().PreviousCDS(records[0]).Return(&previous, nil)
+	mongoStore.EXPECT().QuarantineCDS(gomock.Any()).DoAndReturn(func(quarantined []schema.ConfirmQuarantine) error {
+		assert.Len(t, quarantined, 1)
+		assert.Equal(t, "fake", quarantined[0].Source)
+		assert.Equal(t, []string{schema.QuarantineSharpDrop}, quarantined[0].Reasons)
+		return nil
+	})
+	mongoStore.EXPECT().ReplaceCDS(gomock.Len(0)).Return(nil)
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.True(t, run.Succeeded)
+		assert.Equal(t, 0, run.Records)
+		assert.Equal(t, 1, run.Quarantined)
+		return nil
+	})
+
+	c.Run(context.Background())
+}
+
+func TestNewScheduledCron(t *testing.T) {
+	job, err := newScheduledCron("fake", "", confirmCrawler{})
+	assert.NoError(t, err)
+	next := job.schedule.Next(time.Date(2020, 6, 1, 7, 30, 0, 0, time.Local))
+	assert.Equal(t, time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local), next)
+
+	_, err = newScheduledCron("fake", "every day", confirmCrawler{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bitmark-inc/autonomy-api/external/cdc"
+)
+
+// defaultSchedule crawls a source every six hours
+const defaultSchedule = "0 */6 * * *"
+
+// sourceConfig is a confirm source along with the schedule it is crawled by in the daemon mode
+type sourceConfig struct {
+	cdc.ConfirmSourceConfig `mapstructure:",squash"`
+	Schedule                string `mapstructure:"schedule"`
+}
+
+// scheduledCron is a cron job run by a schedule
+type scheduledCron struct {
+	name     string
+	schedule cron.Schedule
+	job      Cron
+}
+
+// newScheduledCron parses the cron expression of a job. The default schedule is used if it is empty.
+func newScheduledCron(name, spec string, job Cron) (scheduledCron, error) {
+	if spec == "" {
+		spec = defaultSchedule
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return scheduledCron{}, fmt.Errorf("invalid schedule of %s: %w", name, err)
+	}
+
+	return scheduledCron{name: name, schedule: schedule, job: job}, nil
+}
+
+// runDaemon runs each job once and then by its schedule until the context is done.
+// It returns after the running jobs finish.
+func runDaemon(ctx context.Context, jobs []scheduledCron) {
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j scheduledCron) {
+			defer wg.Done()
+
+			for {
+				j.job.Run(ctx)
+
+				next := j.schedule.Next(time.Now())
+				log.WithFields(log.Fields{"prefix": logPrefix, "job": j.name, "next": next}).Info("schedule next run")
+
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+}
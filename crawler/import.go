@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/bitmark-inc/autonomy-api/external/cdc"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/store"
+)
+
+// runImport saves the records in a local data file, such as the timeseries of the past days.
+// The file is read by the adapter of the given source type, or by a configured source which
+// reads the file instead, and a failure is not retried.
+func runImport(mongoStore store.MongoStore, args []string, sources []sourceConfig, validation cdc.Validation) error {
+	var config cdc.ConfirmSourceConfig
+	var sourceName string
+
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&config.File, "file", "", "path of the data file")
+	fs.StringVar(&sourceName, "source", "", "[optional] name of a configured source which reads the data file, such as a csv source")
+	fs.StringVar(&config.Format, "format", "",
+		fmt.Sprintf("[optional] layout of the data file of cds, %s (default), %s or %s", cdc.CDSDaily, cdc.CDSTimeseriesLocationFile, cdc.CDSTimeseriesByDateFile))
+	fs.StringVar(&config.Type, "type", cdc.ConfirmSourceCDS, "type of the confirm source")
+	fs.StringVar(&config.Country, "country", "", "country of the records, named as the boundaries are")
+	fs.StringVar(&config.Level, "level", schema.CDSLevelCountry, "boundary level of the records, country, state or county")
+	fs.StringVar(&config.Name, "name", "", "[optional] name of the source in the run history")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if config.File == "" {
+		return fmt.Errorf("no data file")
+	}
+
+	if sourceName != "" {
+		found := false
+		for _, s := range sources {
+			if s.Name == sourceName {
+				file := config.File
+				config = s.ConfirmSourceConfig
+				config.File = file
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no configured source %s", sourceName)
+		}
+	}
+
+	source, err := cdc.NewConfirmSource(config)
+	if err != nil {
+		return err
+	}
+
+	c := newConfirmCrawler(mongoStore, source, retryPolicy{Attempts: 1}, validation).(*confirmCrawler)
+	return c.run(context.Background())
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/external/cdc"
+	"github.com/bitmark-inc/autonomy-api/mocks"
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestRunImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	f, err := ioutil.TempFile("", "cds-*.json")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`{"Iceland": {"country": "Iceland", "level": "country", "dates": {"2020-03-01": {"cases": 5}, "2020-03-02": {"cases": 8}}}}`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	mongoStore.EXPECT().PreviousCDS(gomock.Any()).Return(nil, nil)
+	mongoStore.EXPECT().QuarantineCDS(gomock.Len(0)).Return(nil)
+	mongoStore.EXPECT().ReplaceCDS(gomock.Any()).DoAndReturn(func(records []schema.CDSData) error {
+		assert.Len(t, records, 2)
+		assert.Equal(t, schema.CdsIceland, records[0].Country)
+		assert.Equal(t, "2020-03-01", records[0].ReportTimeDate)
+		return nil
+	})
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.Equal(t, "backfill", run.Source)
+		assert.Equal(t, 2, run.Records)
+		assert.True(t, run.Succeeded)
+		return nil
+	})
+
+	err = runImport(mongoStore, []string{"--file", f.Name(), "--format", "timeSeriesLocationFile",
+		"--country", schema.CdsIceland, "--name", "backfill"}, nil, cdc.Validation{})
+	assert.NoError(t, err)
+
+	err = runImport(mongoStore, []string{"--country", schema.CdsIceland}, nil, cdc.Validation{})
+	assert.Error(t, err)
+}
+
+func TestRunImportConfiguredSource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoStore := mocks.NewMockMongoStore(ctrl)
+
+	f, err := ioutil.TempFile("", "jhu-*.csv")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("Province/State,Country/Region,1/22/20\n,Taiwan*,1\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	sources := []sourceConfig{{ConfirmSourceConfig: cdc.ConfirmSourceConfig{
+		Name: "jhu-tw", Type: cdc.ConfirmSourceCSV, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry,
+		URL: "https://example.com/jhu.csv",
+		CSV: cdc.CSVConfig{Regions: []cdc.CSVRegion{{Name: "Taiwan*", Boundary: schema.CdsTaiwan}}},
+	}}}
+
+	mongoStore.EXPECT().PreviousCDS(gomock.Any()).Return(nil, nil)
+	mongoStore.EXPECT().QuarantineCDS(gomock.Len(0)).Return(nil)
+	mongoStore.EXPECT().ReplaceCDS(gomock.Len(1)).Return(nil)
+	mongoStore.EXPECT().AddCrawlRun(gomock.Any()).DoAndReturn(func(run schema.CrawlRun) error {
+		assert.Equal(t, "jhu-tw", run.Source)
+		assert.True(t, run.Succeeded)
+		return nil
+	})
+
+	err = runImport(mongoStore, []string{"--file", f.Name(), "--source", "jhu-tw"}, sources, cdc.Validation{})
+	assert.NoError(t, err)
+
+	err = runImport(mongoStore, []string{"--file", f.Name(), "--source", "unknown"}, sources, cdc.Validation{})
+	assert.Error(t, err)
+}
@@ -5,7 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -20,13 +22,11 @@ import (
 
 const (
 	logPrefix      = "cron"
-	twURL          = "https://od.cdc.gov.tw/eic/Weekly_Age_County_Gender_19CoV.json"
-	cdsURL         = "https://coronadatascraper.com/data.json"
 	defaultTimeout = 15 * time.Second
 )
 
 type Cron interface {
-	Run()
+	Run(ctx context.Context)
 }
 
 func init() {
@@ -74,10 +74,12 @@ func loadConfig(file string) {
 
 func main() {
 	var configFile string
+	var once bool
 
 	initialCtx, cancelInitialization := context.WithCancel(context.Background())
 
 	flag.StringVar(&configFile, "c", "./config.yaml", "[optional] path of configuration file")
+	flag.BoolVar(&once, "once", false, "[optional] crawl each source once and exit")
 	flag.Parse()
 
 	loadConfig(configFile)
@@ -104,35 +106,75 @@ func main() {
 		viper.GetString("mongo.database"),
 	)
 
-	crawlerTWCDC := newTWCrawler("tw", mStore, cdc.NewTw(twURL))
-	crawlerTWCDC.Run()
-
 	if cancelInitialization != nil {
 		cancelInitialization()
 	}
 
-	crawlerTW := newCDSCrawler("Taiwan", mStore, cdc.NewCDS("Taiwan", "country", cdc.CDSDailyHTTP, nil, cdsURL))
+	var validation cdc.Validation
+	if err := viper.UnmarshalKey("crawler.validation", &validation); err != nil {
+		log.Panicf("load crawler validation with error: %s", err)
+	}
 
-	crawlerTW.Run()
+	var sourceConfigs []sourceConfig
+	if err := viper.UnmarshalKey("crawler.sources", &sourceConfigs); err != nil {
+		log.Panicf("load confirm sources with error: %s", err)
+	}
 
-	if cancelInitialization != nil {
-		cancelInitialization()
+	if flag.Arg(0) == "import" {
+		if err := runImport(mStore, flag.Args()[1:], sourceConfigs, validation); err != nil {
+			log.Panicf("import confirm data with error: %s", err)
+		}
+		disconnect(mongoClient)
+		return
 	}
-	crawlerIceland := newCDSCrawler("Iceland", mStore, cdc.NewCDS("Iceland", "country", cdc.CDSDailyHTTP, nil, cdsURL))
-	crawlerIceland.Run()
 
-	if cancelInitialization != nil {
-		cancelInitialization()
+	var retry retryPolicy
+	if err := viper.UnmarshalKey("crawler.retry", &retry); err != nil {
+		log.Panicf("load crawler retry policy with error: %s", err)
 	}
 
-	crawlerUS := newCDSCrawler("United States", mStore, cdc.NewCDS("United States", "county", cdc.CDSDailyHTTP, nil, cdsURL))
+	if len(sourceConfigs) == 0 {
+		for _, c := range cdc.DefaultConfirmSourceConfigs {
+			sourceConfigs = append(sourceConfigs, sourceConfig{ConfirmSourceConfig: c})
+		}
+	}
 
-	crawlerUS.Run()
+	jobs := make([]scheduledCron, 0, len(sourceConfigs))
+	for _, c := range sourceConfigs {
+		source, err := cdc.NewConfirmSource(c.ConfirmSourceConfig)
+		if err != nil {
+			log.Panicf("create confirm source with error: %s", err)
+		}
+
+		job, err := newScheduledCron(source.Name(), c.Schedule, newConfirmCrawler(mStore, source, retry, validation))
+		if err != nil {
+			log.Panicf("schedule confirm source with error: %s", err)
+		}
+		jobs = append(jobs, job)
+	}
 
-	if cancelInitialization != nil {
-		cancelInitialization()
+	if once {
+		for _, j := range jobs {
+			j.job.Run(context.Background())
+		}
+	} else {
+		daemonCtx, stop := context.WithCancel(context.Background())
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigs
+			log.WithField("prefix", logPrefix).Info("stop crawler daemon")
+			stop()
+		}()
+
+		log.WithFields(log.Fields{"prefix": logPrefix, "sources": len(jobs)}).Info("start crawler daemon")
+		runDaemon(daemonCtx, jobs)
 	}
 
+	disconnect(mongoClient)
+}
+
+func disconnect(mongoClient *mongo.Client) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
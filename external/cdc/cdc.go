@@ -1,10 +1,176 @@
 package cdc
 
-// CDC - interface to crawl cdc confirmed case
-type CDC interface {
-	Run() (int, error)
-}
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
 
 const (
 	logPrefix = "cdc"
 )
+
+const (
+	ConfirmSourceCDS   = "cds"
+	ConfirmSourceTWCDC = "twcdc"
+)
+
+var (
+	ErrInvalidConfirmSource   = fmt.Errorf("invalid confirm source")
+	ErrUnknownConfirmSource   = fmt.Errorf("unknown confirm source")
+	ErrConfirmSourceDuplicate = fmt.Errorf("confirm source already registered")
+	ErrHTTPStatus             = fmt.Errorf("unexpected http status")
+)
+
+// fetchTimeout bounds a fetch from the url of a source, which is retried by the crawler on failure
+const fetchTimeout = 2 * time.Minute
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// ConfirmSource is a source of confirmed case data. A source fetches its raw data and normalizes
+// it into the records of its country, which are reported by the boundary level of the source.
+type ConfirmSource interface {
+	// Name identifies the source in logs
+	Name() string
+	// Country is the country of the records, named as the boundaries are
+	Country() string
+	// Level is the boundary level which the records are reported by
+	Level() string
+	// Fetch returns the raw data of the source. A fetch over the network is cancelled with the context.
+	Fetch(ctx context.Context) ([]byte, error)
+	// Normalize parses the raw data into confirmed case records
+	Normalize(data []byte) ([]schema.CDSData, error)
+}
+
+// ConfirmSourceConfig configures a source. Type is the adapter which creates the source,
+// and the adapter may provide the default URL. A source reads File instead of URL if it
+// is given, and Format is the layout of the data if the adapter supports more than one.
+// CSV is the columns of the csv sources.
+type ConfirmSourceConfig struct {
+	Name    string    `mapstructure:"name"`
+	Type    string    `mapstructure:"type"`
+	Country string    `mapstructure:"country"`
+	Level   string    `mapstructure:"level"`
+	URL     string    `mapstructure:"url"`
+	File    string    `mapstructure:"file"`
+	Format  string    `mapstructure:"format"`
+	CSV     CSVConfig `mapstructure:"csv"`
+}
+
+// DefaultConfirmSourceConfigs are the sources crawled if no source is configured
+var DefaultConfirmSourceConfigs = []ConfirmSourceConfig{
+	{Type: ConfirmSourceCDS, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry},
+	{Type: ConfirmSourceCDS, Country: schema.CdsIceland, Level: schema.CDSLevelCountry},
+	{Type: ConfirmSourceCDS, Country: schema.CdsUSA, Level: schema.CDSLevelCounty},
+}
+
+// ConfirmSourceFactory creates a source by its configuration
+type ConfirmSourceFactory func(config ConfirmSourceConfig) (ConfirmSource, error)
+
+var (
+	confirmSourceLock sync.RWMutex
+
+	confirmSources = map[string]ConfirmSourceFactory{}
+)
+
+func init() {
+	if err := RegisterConfirmSource(ConfirmSourceCDS, newCDSFromConfig); err != nil {
+		panic(err)
+	}
+	if err := RegisterConfirmSource(ConfirmSourceCSV, newCSVFromConfig); err != nil {
+		panic(err)
+	}
+	if err := RegisterConfirmSource(ConfirmSourceTWCDC, func(config ConfirmSourceConfig) (ConfirmSource, error) {
+		if config.Country != schema.CdsTaiwan || config.Level != schema.CDSLevelCounty {
+			return nil, fmt.Errorf("%w: %s reports counties of %s", ErrInvalidConfirmSource, ConfirmSourceTWCDC, schema.CdsTaiwan)
+		}
+		if config.File != "" {
+			return nil, fmt.Errorf("%w: %s reads no file", ErrInvalidConfirmSource, ConfirmSourceTWCDC)
+		}
+		url := config.URL
+		if url == "" {
+			url = twURL
+		}
+		return NewTw(url), nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterConfirmSource adds an adapter into the registry keyed by its type
+func RegisterConfirmSource(sourceType string, factory ConfirmSourceFactory) error {
+	confirmSourceLock.Lock()
+	defer confirmSourceLock.Unlock()
+
+	if _, ok := confirmSources[sourceType]; ok {
+		return ErrConfirmSourceDuplicate
+	}
+	confirmSources[sourceType] = factory
+	return nil
+}
+
+// NewConfirmSource creates a source by the adapter of its type
+func NewConfirmSource(config ConfirmSourceConfig) (ConfirmSource, error) {
+	if config.Country == "" {
+		return nil, fmt.Errorf("%w: no country", ErrInvalidConfirmSource)
+	}
+
+	validLevel := false
+	for _, level := range schema.CDSLevels {
+		if config.Level == level {
+			validLevel = true
+		}
+	}
+	if !validLevel {
+		return nil, fmt.Errorf("%w: unknown level %s", ErrInvalidConfirmSource, config.Level)
+	}
+
+	confirmSourceLock.RLock()
+	factory, ok := confirmSources[config.Type]
+	confirmSourceLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownConfirmSource, config.Type)
+	}
+
+	source, err := factory(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Name != "" {
+		return namedConfirmSource{ConfirmSource: source, name: config.Name}, nil
+	}
+	return source, nil
+}
+
+// namedConfirmSource is a source renamed by its configuration
+type namedConfirmSource struct {
+	ConfirmSource
+	name string
+}
+
+func (s namedConfirmSource) Name() string {
+	return s.name
+}
+
+// NewConfirmSources creates the configured sources. The default sources are created if no source is given.
+func NewConfirmSources(configs []ConfirmSourceConfig) ([]ConfirmSource, error) {
+	if len(configs) == 0 {
+		configs = DefaultConfirmSourceConfigs
+	}
+
+	sources := make([]ConfirmSource, 0, len(configs))
+	for _, config := range configs {
+		source, err := NewConfirmSource(config)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,237 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestNewConfirmSources(t *testing.T) {
+	sources, err := NewConfirmSources(nil)
+	assert.NoError(t, err)
+	assert.Len(t, sources, len(DefaultConfirmSourceConfigs))
+	assert.Equal(t, "cds:United States", sources[2].Name())
+	assert.Equal(t, schema.CDSLevelCounty, sources[2].Level())
+
+	sources, err = NewConfirmSources([]ConfirmSourceConfig{
+		{Name: "tw-county", Type: ConfirmSourceTWCDC, Country: schema.CdsTaiwan, Level: schema.CDSLevelCounty},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tw-county", sources[0].Name())
+	assert.Equal(t, schema.CdsTaiwan, sources[0].Country())
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: "unknown", Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry})
+	assert.True(t, errors.Is(err, ErrUnknownConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCDS, Country: schema.CdsTaiwan, Level: "city"})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceTWCDC, Country: schema.CdsIceland, Level: schema.CDSLevelCounty})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+}
+
+func TestRegisterConfirmSource(t *testing.T) {
+	err := RegisterConfirmSource(ConfirmSourceCDS, func(ConfirmSourceConfig) (ConfirmSource, error) {
+		return nil, nil
+	})
+	assert.Equal(t, ErrConfirmSourceDuplicate, err)
+}
+
+func TestCDSNormalize(t *testing.T) {
+	data := []byte(`[
+		{"name": "Taiwan", "country": "Taiwan", "level": "country", "cases": 441, "deaths": 7, "recovered": 414},
+		{"name": "Los Angeles County, California, United States", "county": "Los Angeles County", "state": "California",
+			"country": "United States", "level": "county", "cases": 100, "deaths": 10, "active": 50, "population": 10000},
+		{"name": "California, United States", "state": "California", "country": "United States", "level": "state", "cases": 1000},
+		{"name": "St. Croix, United States Virgin Islands", "county": "St. Croix", "country": "United States Virgin Islands",
+			"level": "county", "cases": 20}
+	]`)
+
+	records, err := NewCDS(schema.CdsUSA, schema.CDSLevelCounty, CDSDailyHTTP, nil, "").Normalize(data)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "Los Angeles County", records[0].County)
+	assert.Equal(t, "California", records[0].State)
+	assert.Equal(t, schema.CdsUSA, records[0].Country)
+	assert.Equal(t, float64(50), records[0].Active)
+	assert.Equal(t, float64(10000), records[0].Population)
+
+	records, err = NewCDS(schema.CdsTaiwan, schema.CDSLevelCountry, CDSDailyHTTP, nil, "").Normalize(data)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, float64(20), records[0].Active)
+	assert.NotZero(t, records[0].ReportTime)
+
+	_, err = NewCDS(schema.CdsTaiwan, schema.CDSLevelCountry, CDSDaily, nil, "").Fetch(context.Background())
+	assert.Equal(t, ErrNoCDSDataFile, err)
+}
+
+// TestCDSNormalizeMalformed tests records with malformed coordinates or time zones are skipped
+func TestCDSNormalizeMalformed(t *testing.T) {
+	data := []byte(`[
+		{"name": "Kings County, New York, United States", "county": "Kings County", "state": "New York",
+			"country": "United States", "level": "county", "cases": 100, "coordinates": ["-73.9", "40.6"]},
+		{"name": "Queens County, New York, United States", "county": "Queens County", "state": "New York",
+			"country": "United States", "level": "county", "cases": 100, "tz": [-5]},
+		{"name": "Bronx County, New York, United States", "county": "Bronx County", "state": "New York",
+			"country": "United States", "level": "county", "cases": 100, "coordinates": [-73.9, 40.8], "tz": ["America/New_York"]}
+	]`)
+
+	records, err := NewCDS(schema.CdsUSA, schema.CDSLevelCounty, CDSDailyHTTP, nil, "").Normalize(data)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "Bronx County", records[0].County)
+	assert.Equal(t, []float64{-73.9, 40.8}, records[0].Location.Coordinates)
+	assert.Equal(t, []string{"America/New_York"}, records[0].Timezone)
+}
+
+// newCDSFile creates a cds source of the united states counties reading the given data from a file
+func newCDSFile(t *testing.T, format CovidSource, data string) ConfirmSource {
+	f, err := ioutil.TempFile("", "cds-*.json")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	_, err = f.WriteString(data)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	source, err := NewConfirmSource(ConfirmSourceConfig{
+		Type:    ConfirmSourceCDS,
+		Country: schema.CdsUSA,
+		Level:   schema.CDSLevelCounty,
+		File:    f.Name(),
+		Format:  string(format),
+	})
+	assert.NoError(t, err)
+	return source
+}
+
+func TestCDSTimeseriesByLocation(t *testing.T) {
+	source := newCDSFile(t, CDSTimeseriesLocationFile, `{
+		"Los Angeles County, California, United States": {
+			"county": "Los Angeles County", "state": "California", "country": "United States", "level": "county", "population": 10000,
+			"dates": {
+				"2020-3-2": {"cases": 12, "deaths": 1, "recovered": 1},
+				"2020-03-01": {"cases": 10, "deaths": 1}
+			}
+		},
+		"Taiwan": {"country": "Taiwan", "level": "country", "dates": {"2020-03-01": {"cases": 40}}}
+	}`)
+
+	// the file can be read more than once
+	for i := 0; i < 2; i++ {
+		data, err := source.Fetch(context.Background())
+		assert.NoError(t, err)
+
+		records, err := source.Normalize(data)
+		assert.NoError(t, err)
+		assert.Len(t, records, 2)
+
+		assert.Equal(t, "2020-03-01", records[0].ReportTimeDate)
+		assert.Equal(t, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), records[0].ReportTime)
+		assert.Equal(t, "Los Angeles County", records[0].County)
+		assert.Equal(t, float64(10), records[0].Cases)
+		assert.Equal(t, float64(9), records[0].Active)
+		assert.Equal(t, float64(10000), records[0].Population)
+
+		assert.Equal(t, "2020-03-02", records[1].ReportTimeDate)
+		assert.Equal(t, float64(10), records[1].Active)
+	}
+}
+
+func TestCDSTimeseriesByDate(t *testing.T) {
+	source := newCDSFile(t, CDSTimeseriesByDateFile, `{
+		"2020-03-02": {
+			"Los Angeles County, California, United States": {"county": "Los Angeles County", "state": "California", "country": "United States", "level": "county", "cases": 12},
+			"Orange County, California, United States": {"county": "Orange County", "state": "California", "country": "United States", "level": "county", "cases": 3}
+		},
+		"2020-03-01": {
+			"Los Angeles County, California, United States": {"county": "Los Angeles County", "state": "California", "country": "United States", "level": "county", "cases": 10}
+		}
+	}`)
+
+	data, err := source.Fetch(context.Background())
+	assert.NoError(t, err)
+
+	records, err := source.Normalize(data)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "2020-03-01", records[0].ReportTimeDate)
+	assert.Equal(t, "Los Angeles County, California, United States", records[1].Name)
+	assert.Equal(t, "Orange County", records[2].County)
+	assert.Equal(t, schema.CdsUSA, records[2].Country)
+	assert.Equal(t, float64(3), records[2].Active)
+
+	source = newCDSFile(t, CDSTimeseriesByDateFile, `{"March 1": {}}`)
+	data, err = source.Fetch(context.Background())
+	assert.NoError(t, err)
+	_, err = source.Normalize(data)
+	assert.True(t, errors.Is(err, ErrInvalidCDSDate))
+}
+
+func TestNewCDSFromConfig(t *testing.T) {
+	_, err := NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCDS, Country: schema.CdsUSA, Level: schema.CDSLevelCounty,
+		Format: string(CDSTimeseriesByDateFile)})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCDS, Country: schema.CdsUSA, Level: schema.CDSLevelCounty,
+		File: "data.json", Format: "csv"})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCDS, Country: schema.CdsUSA, Level: schema.CDSLevelCounty,
+		File: "not-found.json"})
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTWCDCNormalize(t *testing.T) {
+	data := []byte(`[
+		{"診斷年份": "2020", "診斷週別": "10", "縣市": "台北市", "性別": "F", "是否為境外移入": "是", "年齡層": "20-24", "確定病例數": "2"},
+		{"診斷年份": "2020", "診斷週別": "11", "縣市": "台北市", "性別": "M", "是否為境外移入": "否", "年齡層": "30-34", "確定病例數": "3"},
+		{"診斷年份": "2020", "診斷週別": "11", "縣市": "不明", "性別": "M", "是否為境外移入": "否", "年齡層": "30-34", "確定病例數": "1"}
+	]`)
+
+	records, err := NewTw("").Normalize(data)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "Taipei City", records[0].County)
+	assert.Equal(t, schema.CdsTaiwan, records[0].Country)
+	assert.Equal(t, schema.CDSLevelCounty, records[0].Level)
+	assert.Equal(t, float64(5), records[0].Cases)
+	assert.Equal(t, float64(5), records[0].Active)
+}
+
+func TestDataFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			w.Write([]byte("[]"))
+		case "/hang":
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	data, err := dataFromURL(context.Background(), server.URL+"/data.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("[]"), data)
+
+	_, err = dataFromURL(context.Background(), server.URL+"/missing")
+	assert.True(t, errors.Is(err, ErrHTTPStatus))
+
+	// a fetch which hangs is stopped with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = dataFromURL(ctx, server.URL+"/hang")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
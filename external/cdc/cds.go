@@ -1,12 +1,13 @@
 package cdc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/bitmark-inc/autonomy-api/schema"
@@ -22,152 +23,333 @@ const (
 	CDSTimeseriesByDateFile   CovidSource = "timeSeriesByDateFile"
 )
 
+const cdsURL = "https://coronadatascraper.com/data.json"
+
+var (
+	ErrUnsupportedCDSSource = fmt.Errorf("unsupported cds source")
+	ErrNoCDSDataFile        = fmt.Errorf("no cds data file")
+	ErrInvalidCDSDate       = fmt.Errorf("invalid cds date")
+)
+
+// CDS is the source of the confirmed cases collected by Corona Data Scraper
 type CDS struct {
-	Country     string
-	Level       string
-	CDSDataType CovidSource
-	DataFile    *os.File
-	URL         string
-	Result      []schema.CDSData
+	country  string
+	level    string
+	dataType CovidSource
+	dataFile *os.File
+	url      string
 }
 
-func (c *CDS) Run() (int, error) {
-	data, err := dataFromURL(c.URL)
-	if nil != err {
-		return 0, err
-	}
-	count := 0
-	updateRecords := []schema.CDSData{}
-	sourceData := make([]interface{}, 0)
+func (c *CDS) Name() string {
+	return fmt.Sprintf("%s:%s", ConfirmSourceCDS, c.country)
+}
+
+func (c *CDS) Country() string {
+	return c.country
+}
+
+func (c *CDS) Level() string {
+	return c.level
+}
 
-	err = json.Unmarshal(data, &sourceData)
+// Fetch reads the data of the source from its url or its data file
+func (c *CDS) Fetch(ctx context.Context) ([]byte, error) {
+	switch c.dataType {
+	case CDSDailyHTTP:
+		return dataFromURL(ctx, c.url)
+	case CDSDaily, CDSTimeseriesLocationFile, CDSTimeseriesByDateFile:
+		if c.dataFile == nil {
+			return nil, ErrNoCDSDataFile
+		}
+		if _, err := c.dataFile.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(c.dataFile)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCDSSource, c.dataType)
+	}
+}
 
+// Normalize picks the records of the country and the level of the source from the data in its layout.
+// Records of the daily data are reported today, and records of the timeseries are reported on their dates.
+func (c *CDS) Normalize(data []byte) ([]schema.CDSData, error) {
+	var records []schema.CDSData
+	var err error
+	switch c.dataType {
+	case CDSDailyHTTP, CDSDaily:
+		records, err = c.normalizeDaily(data, time.Now())
+	case CDSTimeseriesLocationFile:
+		records, err = c.normalizeTimeseriesByLocation(data, time.Now())
+	case CDSTimeseriesByDateFile:
+		records, err = c.normalizeTimeseriesByDate(data, time.Now())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCDSSource, c.dataType)
+	}
 	if err != nil {
-		fmt.Println("ParseDailyOnline error:", err)
-		return 0, err
-	}
-
-	for _, value := range sourceData {
-		record := schema.CDSData{}
-		object := value.(map[string]interface{})
-		name, ok := object["name"].(string)
-		if ok && len(name) > 0 && strings.Contains(name, c.Country) { // Country
-			record.Name = name
-		} else {
-			continue
+		log.WithFields(log.Fields{"prefix": logPrefix, "type": c.dataType, "error": err}).Error("parse cds json")
+		return nil, err
+	}
+
+	sortCDSRecords(records)
+	return records, nil
+}
+
+// sortCDSRecords sorts records by their report time and then their names
+func sortCDSRecords(records []schema.CDSData) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].ReportTime != records[j].ReportTime {
+			return records[i].ReportTime < records[j].ReportTime
 		}
-		record.City, _ = object["city"].(string)
-		record.County, _ = object["county"].(string)
-		record.State, _ = object["state"].(string)
-		record.Country, _ = object["country"].(string)
-		record.CountryID, _ = object["countryId"].(string)
-		record.StateID, _ = object["stateId"].(string)
-		record.CountyID, _ = object["countyId"].(string)
-		record.Level, _ = object["level"].(string)
-
-		if "" == record.Level {
-			switch c.Level {
-			case "country":
-				if "" != record.Country && "" == record.State {
-					record.Level = "country"
-				}
-			case "state":
-				if "" != record.State && "" == record.County {
-					record.Level = "state"
-				}
-			case "county":
-				if "" != record.County && "" == record.City {
-					record.Level = "county"
-				}
-			case "city":
-				record.Level = "city"
-			default:
-				log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name}).Warn("data from CDS")
+		return records[i].Name < records[j].Name
+	})
+}
+
+// normalizeDaily parses the daily data, which is an array of the data of locations:
+// [{"name": "Taiwan", "country": "Taiwan", "level": "country", "cases": 441, ...}, ...]
+func (c *CDS) normalizeDaily(data []byte, now time.Time) ([]schema.CDSData, error) {
+	var sourceData []map[string]interface{}
+	if err := json.Unmarshal(data, &sourceData); err != nil {
+		return nil, err
+	}
+
+	updateRecords := []schema.CDSData{}
+	for _, object := range sourceData {
+		if record, ok := c.normalizeRecord(object, now, now); ok {
+			updateRecords = append(updateRecords, record)
+		}
+	}
+	return updateRecords, nil
+}
+
+// normalizeTimeseriesByLocation parses the timeseries keyed by locations, where the data of a
+// location is followed by its counts keyed by dates:
+// {"Taiwan": {"country": "Taiwan", "level": "country", "dates": {"2020-05-26": {"cases": 441, ...}, ...}}, ...}
+func (c *CDS) normalizeTimeseriesByLocation(data []byte, now time.Time) ([]schema.CDSData, error) {
+	var sourceData map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &sourceData); err != nil {
+		return nil, err
+	}
+
+	updateRecords := []schema.CDSData{}
+	for name, location := range sourceData {
+		dates, _ := location["dates"].(map[string]interface{})
+		for date, value := range dates {
+			counts, ok := value.(map[string]interface{})
+			if !ok {
 				continue
 			}
-			log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name, "level": record.Level}).Warn("empty level set")
-		}
+			day, err := parseCDSDate(date)
+			if err != nil {
+				return nil, err
+			}
 
-		if record.Level != c.Level {
-			continue
+			if record, ok := c.normalizeRecord(mergeCDSObjects(name, location, counts), day, now); ok {
+				updateRecords = append(updateRecords, record)
+			}
 		}
+	}
+	return updateRecords, nil
+}
 
-		coorRaw, ok := object["coordinates"].([]interface{})
-		if ok && len(coorRaw) > 0 {
-			coortemp := []float64{}
-			for _, coorV := range coorRaw {
-				coortemp = append(coortemp, coorV.(float64))
-			}
-			record.Location = schema.GeoJSON{Type: "Point", Coordinates: coortemp}
-		} else {
-			record.Location = schema.GeoJSON{Type: "Point", Coordinates: []float64{}}
+// normalizeTimeseriesByDate parses the timeseries keyed by dates, where the data of the
+// locations of a date is keyed by the locations:
+// {"2020-05-26": {"Taiwan": {"country": "Taiwan", "level": "country", "cases": 441, ...}, ...}, ...}
+func (c *CDS) normalizeTimeseriesByDate(data []byte, now time.Time) ([]schema.CDSData, error) {
+	var sourceData map[string]map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &sourceData); err != nil {
+		return nil, err
+	}
+
+	updateRecords := []schema.CDSData{}
+	for date, locations := range sourceData {
+		day, err := parseCDSDate(date)
+		if err != nil {
+			return nil, err
 		}
 
-		tzRaw, ok := object["tz"].([]interface{})
-		if ok && len(tzRaw) > 0 {
-			tztemp := []string{}
-			for _, tzV := range tzRaw {
-				tztemp = append(tztemp, tzV.(string))
+		for name, location := range locations {
+			if record, ok := c.normalizeRecord(mergeCDSObjects(name, location, nil), day, now); ok {
+				updateRecords = append(updateRecords, record)
 			}
-			record.Timezone = tztemp
-		} else {
-			record.Timezone = []string{}
 		}
-		record.Cases, ok = object["cases"].(float64)
+	}
+	return updateRecords, nil
+}
 
-		if !ok {
-			log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name}).Warn("cast cases fail")
-			continue
+// mergeCDSObjects combines the data of a location and its counts of a date. The location is named by the key if it has no name.
+func mergeCDSObjects(name string, location, counts map[string]interface{}) map[string]interface{} {
+	object := make(map[string]interface{}, len(location)+len(counts)+1)
+	object["name"] = name
+	for k, v := range location {
+		if k != "dates" {
+			object[k] = v
 		}
-		record.Deaths, _ = object["deaths"].(float64)
-		if record.Deaths < 0 {
-			record.Deaths = 0
+	}
+	for k, v := range counts {
+		object[k] = v
+	}
+	return object
+}
+
+// parseCDSDate parses the dates of the timeseries, which may not be padded with zeros
+func parseCDSDate(date string) (time.Time, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		day, err = time.Parse("2006-1-2", date)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidCDSDate, date)
+	}
+	return day, nil
+}
+
+// normalizeRecord converts the data of a location into a record reported on the given day.
+// It returns false if the location is not of the country and the level of the source.
+func (c *CDS) normalizeRecord(object map[string]interface{}, day, now time.Time) (schema.CDSData, bool) {
+	record := schema.CDSData{}
+	name, ok := object["name"].(string)
+	if !ok || len(name) == 0 || object["country"] != c.country {
+		return record, false
+	}
+	record.Name = name
+	record.City, _ = object["city"].(string)
+	record.County, _ = object["county"].(string)
+	record.State, _ = object["state"].(string)
+	record.Country = c.country
+	record.CountryID, _ = object["countryId"].(string)
+	record.StateID, _ = object["stateId"].(string)
+	record.CountyID, _ = object["countyId"].(string)
+	record.Level, _ = object["level"].(string)
+
+	if "" == record.Level {
+		switch c.level {
+		case "country":
+			if "" != record.Country && "" == record.State {
+				record.Level = "country"
+			}
+		case "state":
+			if "" != record.State && "" == record.County {
+				record.Level = "state"
+			}
+		case "county":
+			if "" != record.County && "" == record.City {
+				record.Level = "county"
+			}
+		case "city":
+			record.Level = "city"
+		default:
+			log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name}).Warn("data from CDS")
+			return record, false
 		}
-		record.Recovered, _ = object["recovered"].(float64)
-		if record.Recovered < 0 {
-			record.Recovered = 0
+		log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name, "level": record.Level}).Warn("empty level set")
+	}
+
+	if record.Level != c.level {
+		return record, false
+	}
+
+	coorRaw, ok := object["coordinates"].([]interface{})
+	if ok && len(coorRaw) > 0 {
+		coortemp := []float64{}
+		for _, coorV := range coorRaw {
+			v, ok := coorV.(float64)
+			if !ok {
+				log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name, "coordinates": coorRaw}).Warn("cast coordinates fail")
+				return record, false
+			}
+			coortemp = append(coortemp, v)
 		}
+		record.Location = schema.GeoJSON{Type: "Point", Coordinates: coortemp}
+	} else {
+		record.Location = schema.GeoJSON{Type: "Point", Coordinates: []float64{}}
+	}
 
-		record.Active, _ = object["active"].(float64)
-		if record.Active <= 0 {
-			record.Active = record.Cases - record.Deaths - record.Recovered
+	tzRaw, ok := object["tz"].([]interface{})
+	if ok && len(tzRaw) > 0 {
+		tztemp := []string{}
+		for _, tzV := range tzRaw {
+			v, ok := tzV.(string)
+			if !ok {
+				log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name, "tz": tzRaw}).Warn("cast tz fail")
+				return record, false
+			}
+			tztemp = append(tztemp, v)
 		}
+		record.Timezone = tztemp
+	} else {
+		record.Timezone = []string{}
+	}
+	record.Cases, ok = object["cases"].(float64)
 
-		year, month, day := time.Now().Date()
-		dateString := fmt.Sprintf("%d-%.2d-%.2d", year, int(month), day)
-		record.UpdateTime = time.Now().UTC().Unix()
-		record.ReportTime = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()
-		record.ReportTimeDate = dateString //In local time
-		count++
-		updateRecords = append(updateRecords, record)
+	if !ok {
+		log.WithFields(log.Fields{"prefix": logPrefix, "name": record.Name}).Warn("cast cases fail")
+		return record, false
+	}
+	record.Deaths, _ = object["deaths"].(float64)
+	if record.Deaths < 0 {
+		record.Deaths = 0
+	}
+	record.Recovered, _ = object["recovered"].(float64)
+	if record.Recovered < 0 {
+		record.Recovered = 0
 	}
-	c.Result = updateRecords
-	return count, nil
+
+	record.Population, _ = object["population"].(float64)
+
+	record.Active, _ = object["active"].(float64)
+	if record.Active <= 0 {
+		record.Active = record.Cases - record.Deaths - record.Recovered
+	}
+
+	setReportTime(&record, day, now)
+	return record, true
 }
 
-func getCDSJSON(url string) ([]byte, error) {
-	resp, err := http.Get(url)
-	if nil != err {
-		log.WithFields(log.Fields{"prefix": logPrefix, "url": url, "error": err}).Error("get cds daily json")
-		return []byte{}, err
+// setReportTime sets the report time of a record to the given day, and its update time to now
+func setReportTime(record *schema.CDSData, reportDay, now time.Time) {
+	year, month, day := reportDay.Date()
+	record.UpdateTime = now.UTC().Unix()
+	record.ReportTime = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()
+	record.ReportTimeDate = fmt.Sprintf("%d-%.2d-%.2d", year, int(month), day) //In local time
+}
+
+// newCDSFromConfig creates a cds source which reads the daily data from the url, or the data in the
+// given format from the file. The daily data is read from the file if the format is not given.
+func newCDSFromConfig(config ConfirmSourceConfig) (ConfirmSource, error) {
+	format := CovidSource(config.Format)
+	if config.File == "" {
+		if format != "" && format != CDSDailyHTTP {
+			return nil, fmt.Errorf("%w: format %s reads a file", ErrInvalidConfirmSource, format)
+		}
+		url := config.URL
+		if url == "" {
+			url = cdsURL
+		}
+		return NewCDS(config.Country, config.Level, CDSDailyHTTP, nil, url), nil
+	}
+
+	switch format {
+	case "":
+		format = CDSDaily
+	case CDSDaily, CDSTimeseriesLocationFile, CDSTimeseriesByDateFile:
+	default:
+		return nil, fmt.Errorf("%w: unknown file format %s", ErrInvalidConfirmSource, format)
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
-	if nil != err {
-		log.WithFields(log.Fields{"prefix": logPrefix, "error": err}).Error("read cds daily json  response")
-		return []byte{}, err
+	f, err := os.Open(config.File)
+	if err != nil {
+		return nil, err
 	}
-	return data, nil
+	return NewCDS(config.Country, config.Level, format, f, ""), nil
 }
 
-// NewCDS - new tw cdc crawler
-func NewCDS(country string, level string, dataType CovidSource, f *os.File, url string) CDC {
+// NewCDS - new cds source of a country
+func NewCDS(country string, level string, dataType CovidSource, f *os.File, url string) ConfirmSource {
 	return &CDS{
-		Country:     country,
-		Level:       level,
-		CDSDataType: dataType,
-		DataFile:    f,
-		URL:         url,
+		country:  country,
+		level:    level,
+		dataType: dataType,
+		dataFile: f,
+		url:      url,
 	}
 }
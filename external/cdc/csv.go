@@ -0,0 +1,428 @@
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+const ConfirmSourceCSV = "csv"
+
+var ErrInvalidCSV = fmt.Errorf("invalid confirm csv")
+
+// CSVConfig describes a wide timeseries csv of the cumulative confirmed cases. Each row is a region
+// named by the region columns, and the columns whose headers are dates in DateFormat are the counts
+// of the dates, as JHU publishes. If DateColumn is given, each row is a date instead, and each of the
+// other columns is the counts of a country, as OWID publishes.
+// The csv only counts the cumulative confirmed cases, so the active cases of a day are derived as
+// the new cases in the last ActiveDays days up to the day, which is 14 days if it is not given.
+type CSVConfig struct {
+	CountryColumn    string `mapstructure:"country_column"`
+	StateColumn      string `mapstructure:"state_column"`
+	CountyColumn     string `mapstructure:"county_column"`
+	PopulationColumn string `mapstructure:"population_column"`
+	DateColumn       string `mapstructure:"date_column"`
+	DateFormat       string `mapstructure:"date_format"`
+	// CountySuffix is appended to the county names which do not end with it, e.g. " County"
+	CountySuffix string      `mapstructure:"county_suffix"`
+	Regions      []CSVRegion `mapstructure:"regions"`
+	// ActiveDays is the number of days a confirmed case is counted active
+	ActiveDays int `mapstructure:"active_days"`
+}
+
+// CSVRegion maps a region name in the csv onto the name of its boundary
+type CSVRegion struct {
+	Name     string `mapstructure:"name"`
+	Boundary string `mapstructure:"boundary"`
+}
+
+// DefaultCSVConfig is the layout of the global timeseries of JHU CSSE
+var DefaultCSVConfig = CSVConfig{
+	CountryColumn: "Country/Region",
+	StateColumn:   "Province/State",
+	DateFormat:    "1/2/06",
+	ActiveDays:    14,
+}
+
+// withDefaults uses the columns of the default layout if no column is given
+func (c CSVConfig) withDefaults() CSVConfig {
+	if c.CountryColumn == "" && c.StateColumn == "" && c.CountyColumn == "" && c.DateColumn == "" {
+		c.CountryColumn = DefaultCSVConfig.CountryColumn
+		c.StateColumn = DefaultCSVConfig.StateColumn
+	}
+	if c.DateFormat == "" {
+		c.DateFormat = DefaultCSVConfig.DateFormat
+	}
+	if c.ActiveDays <= 0 {
+		c.ActiveDays = DefaultCSVConfig.ActiveDays
+	}
+	return c
+}
+
+// CSV is the source of the confirmed cases in a wide timeseries csv. The active cases are derived
+// from the cumulative confirmed cases by the active days of the config.
+type CSV struct {
+	country  string
+	level    string
+	url      string
+	dataFile *os.File
+	config   CSVConfig
+	regions  map[string]string
+}
+
+func (c *CSV) Name() string {
+	return fmt.Sprintf("%s:%s", ConfirmSourceCSV, c.country)
+}
+
+func (c *CSV) Country() string {
+	return c.country
+}
+
+func (c *CSV) Level() string {
+	return c.level
+}
+
+// Fetch reads the csv from its data file or its url
+func (c *CSV) Fetch(ctx context.Context) ([]byte, error) {
+	if c.dataFile == nil {
+		return dataFromURL(ctx, c.url)
+	}
+	if _, err := c.dataFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(c.dataFile)
+}
+
+// Normalize picks the records of the country and the level of the source from the csv.
+// The counts of the regions mapped onto the same boundary are summed up. The records of a
+// country reported by states only are summed up from its states.
+func (c *CSV) Normalize(data []byte) ([]schema.CDSData, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		log.WithFields(log.Fields{"prefix": logPrefix, "source": c.Name(), "error": err}).Error("parse confirm csv")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCSV, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no header", ErrInvalidCSV)
+	}
+
+	var counts map[csvArea]map[time.Time]float64
+	var population map[csvArea]float64
+	if c.config.DateColumn != "" {
+		counts, err = c.countsByDateRows(rows)
+	} else {
+		counts, population, err = c.countsByRegionRows(rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := make([]schema.CDSData, 0)
+	for area, dates := range counts {
+		active := activeCases(dates, c.config.ActiveDays)
+		for day, cases := range dates {
+			record := schema.CDSData{
+				Name:       area.name(),
+				County:     area.county,
+				State:      area.state,
+				Country:    area.country,
+				Level:      c.level,
+				Cases:      cases,
+				Active:     active[day],
+				Population: population[area],
+				Location:   schema.GeoJSON{Type: "Point", Coordinates: []float64{}},
+				Timezone:   []string{},
+			}
+			setReportTime(&record, day, now)
+			records = append(records, record)
+		}
+	}
+
+	sortCDSRecords(records)
+	return records, nil
+}
+
+// activeCases derives the active cases of each day from the cumulative confirmed cases, which are
+// the new cases in the last activeDays days up to the day. The cases before the first day of the csv
+// are counted active, and a day whose cumulative count is corrected down has no active case.
+func activeCases(cumulative map[time.Time]float64, activeDays int) map[time.Time]float64 {
+	if activeDays <= 0 {
+		activeDays = DefaultCSVConfig.ActiveDays
+	}
+
+	days := make([]time.Time, 0, len(cumulative))
+	for day := range cumulative {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	active := make(map[time.Time]float64, len(days))
+	// base is the index of the latest day on or before the day the window starts
+	base := -1
+	for _, day := range days {
+		start := day.AddDate(0, 0, -activeDays)
+		for base+1 < len(days) && !days[base+1].After(start) {
+			base++
+		}
+
+		baseCases := 0.0
+		if base >= 0 {
+			baseCases = cumulative[days[base]]
+		}
+		active[day] = math.Max(0, cumulative[day]-baseCases)
+	}
+
+	return active
+}
+
+// csvArea is the boundary of a region in the csv
+type csvArea struct {
+	country, state, county string
+}
+
+// name names an area as cds does, from the most specific level
+func (a csvArea) name() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{a.county, a.state, a.country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countsByRegionRows reads the counts of the csv where each row is a region
+func (c *CSV) countsByRegionRows(rows [][]string) (map[csvArea]map[time.Time]float64, map[csvArea]float64, error) {
+	header := rows[0]
+	countryIndex, err := csvColumn(header, c.config.CountryColumn)
+	if err != nil {
+		return nil, nil, err
+	}
+	stateIndex, err := csvColumn(header, c.config.StateColumn)
+	if err != nil {
+		return nil, nil, err
+	}
+	countyIndex, err := csvColumn(header, c.config.CountyColumn)
+	if err != nil {
+		return nil, nil, err
+	}
+	populationIndex, err := csvColumn(header, c.config.PopulationColumn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dates := make(map[int]time.Time)
+	for i, h := range header {
+		if day, err := time.Parse(c.config.DateFormat, h); err == nil {
+			dates[i] = day
+		}
+	}
+	if len(dates) == 0 {
+		return nil, nil, fmt.Errorf("%w: no date column in %s", ErrInvalidCSV, c.config.DateFormat)
+	}
+
+	counts := make(map[csvArea]map[time.Time]float64)
+	population := make(map[csvArea]float64)
+	// the counts of the regions below the country, used if the country has no row
+	belowCounts := make(map[csvArea]map[time.Time]float64)
+	belowPopulation := make(map[csvArea]float64)
+	for n, row := range rows[1:] {
+		area := csvArea{country: c.country}
+		if countryIndex >= 0 {
+			area.country = c.boundaryName(row[countryIndex])
+		}
+		if area.country != c.country {
+			continue
+		}
+		if stateIndex >= 0 {
+			area.state = c.boundaryName(row[stateIndex])
+		}
+		if countyIndex >= 0 {
+			area.county = c.countyName(row[countyIndex])
+		}
+
+		targetCounts, targetPopulation := counts, population
+		switch c.level {
+		case schema.CDSLevelCountry:
+			if area.state != "" || area.county != "" {
+				targetCounts, targetPopulation = belowCounts, belowPopulation
+				area = csvArea{country: area.country}
+			}
+		case schema.CDSLevelState:
+			if area.state == "" || area.county != "" {
+				continue
+			}
+		case schema.CDSLevelCounty:
+			if area.county == "" {
+				continue
+			}
+		}
+
+		if targetCounts[area] == nil {
+			targetCounts[area] = make(map[time.Time]float64)
+		}
+		for i, day := range dates {
+			value, ok, err := csvValue(row[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: row %d, %s: %s", ErrInvalidCSV, n+2, header[i], err)
+			}
+			if ok {
+				targetCounts[area][day] += value
+			}
+		}
+
+		if populationIndex >= 0 {
+			value, _, err := csvValue(row[populationIndex])
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: row %d, %s: %s", ErrInvalidCSV, n+2, header[populationIndex], err)
+			}
+			targetPopulation[area] += value
+		}
+	}
+
+	if len(counts) == 0 {
+		return belowCounts, belowPopulation, nil
+	}
+	return counts, population, nil
+}
+
+// countsByDateRows reads the counts of the csv where each row is a date and each column is a country
+func (c *CSV) countsByDateRows(rows [][]string) (map[csvArea]map[time.Time]float64, error) {
+	header := rows[0]
+	dateIndex, err := csvColumn(header, c.config.DateColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[csvArea]map[time.Time]float64)
+	for i, h := range header {
+		if i == dateIndex || c.boundaryName(h) != c.country {
+			continue
+		}
+
+		area := csvArea{country: c.country}
+		if counts[area] == nil {
+			counts[area] = make(map[time.Time]float64)
+		}
+		for n, row := range rows[1:] {
+			day, err := time.Parse(c.config.DateFormat, row[dateIndex])
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: %s", ErrInvalidCDSDate, n+2, row[dateIndex])
+			}
+			value, ok, err := csvValue(row[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d, %s: %s", ErrInvalidCSV, n+2, h, err)
+			}
+			if ok {
+				counts[area][day] += value
+			}
+		}
+	}
+	return counts, nil
+}
+
+// boundaryName maps a region name onto the name of its boundary. The name is kept if it is not mapped.
+func (c *CSV) boundaryName(name string) string {
+	name = strings.TrimSpace(name)
+	if boundary, ok := c.regions[utils.EnNameToKey(name)]; ok {
+		return boundary
+	}
+	return name
+}
+
+// countyName maps a county name onto the name of its boundary. A county not mapped is named with the county suffix.
+func (c *CSV) countyName(name string) string {
+	name = strings.TrimSpace(name)
+	if boundary, ok := c.regions[utils.EnNameToKey(name)]; ok {
+		return boundary
+	}
+	if name != "" && c.config.CountySuffix != "" && !strings.HasSuffix(name, c.config.CountySuffix) {
+		name += c.config.CountySuffix
+	}
+	return name
+}
+
+// csvColumn returns the index of a column in the header, or -1 if the column is not given
+func csvColumn(header []string, column string) (int, error) {
+	if column == "" {
+		return -1, nil
+	}
+	for i, h := range header {
+		if strings.TrimSpace(h) == column {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: no column %s", ErrInvalidCSV, column)
+}
+
+// csvValue parses a count in the csv. It returns false if the count is empty.
+func csvValue(value string) (float64, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+// newCSVFromConfig creates a csv source which reads the file if it is given, or the url
+func newCSVFromConfig(config ConfirmSourceConfig) (ConfirmSource, error) {
+	if config.Format != "" {
+		return nil, fmt.Errorf("%w: %s is configured by its columns", ErrInvalidConfirmSource, ConfirmSourceCSV)
+	}
+	if config.File == "" && config.URL == "" {
+		return nil, fmt.Errorf("%w: %s reads a file or a url", ErrInvalidConfirmSource, ConfirmSourceCSV)
+	}
+
+	csvConfig := config.CSV.withDefaults()
+	if csvConfig.DateColumn != "" && config.Level != schema.CDSLevelCountry {
+		return nil, fmt.Errorf("%w: columns of countries are reported by %s", ErrInvalidConfirmSource, schema.CDSLevelCountry)
+	}
+
+	var f *os.File
+	if config.File != "" {
+		var err error
+		if f, err = os.Open(config.File); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewCSV(config.Country, config.Level, csvConfig, f, config.URL), nil
+}
+
+// NewCSV - new csv source of a country
+func NewCSV(country, level string, config CSVConfig, f *os.File, url string) ConfirmSource {
+	regions := make(map[string]string, len(config.Regions))
+	for _, r := range config.Regions {
+		regions[utils.EnNameToKey(strings.TrimSpace(r.Name))] = r.Boundary
+	}
+
+	return &CSV{
+		country:  country,
+		level:    level,
+		url:      url,
+		dataFile: f,
+		config:   config,
+		regions:  regions,
+	}
+}
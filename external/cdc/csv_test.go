@@ -0,0 +1,151 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+const jhuGlobalCSV = "\ufeffProvince/State,Country/Region,Lat,Long,1/22/20,1/23/20\n" +
+	",Taiwan*,23.7,121.0,1,3\n" +
+	",Iceland,64.9,-19.0,0,\n" +
+	"Queensland,Australia,-27.5,153.0,2,4\n" +
+	"Victoria,Australia,-37.8,144.9,1,5\n"
+
+func TestCSVByRegionRows(t *testing.T) {
+	config := DefaultCSVConfig
+	config.Regions = []CSVRegion{{Name: "Taiwan*", Boundary: schema.CdsTaiwan}}
+
+	records, err := NewCSV(schema.CdsTaiwan, schema.CDSLevelCountry, config, nil, "").Normalize([]byte(jhuGlobalCSV))
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, schema.CdsTaiwan, records[0].Name)
+	assert.Equal(t, schema.CdsTaiwan, records[0].Country)
+	assert.Equal(t, schema.CDSLevelCountry, records[0].Level)
+	assert.Equal(t, "2020-01-22", records[0].ReportTimeDate)
+	assert.Equal(t, float64(1), records[0].Cases)
+	assert.Equal(t, float64(3), records[1].Active)
+
+	// empty counts are skipped
+	records, err = NewCSV(schema.CdsIceland, schema.CDSLevelCountry, config, nil, "").Normalize([]byte(jhuGlobalCSV))
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	// a country reported by states only is summed up from its states
+	records, err = NewCSV("Australia", schema.CDSLevelCountry, config, nil, "").Normalize([]byte(jhuGlobalCSV))
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, float64(3), records[0].Cases)
+	assert.Equal(t, float64(9), records[1].Cases)
+
+	records, err = NewCSV("Australia", schema.CDSLevelState, config, nil, "").Normalize([]byte(jhuGlobalCSV))
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, "Queensland, Australia", records[0].Name)
+	assert.Equal(t, "Queensland", records[0].State)
+
+	_, err = NewCSV("Australia", schema.CDSLevelState, config, nil, "").Normalize([]byte("Country/Region,1/22/20\nAustralia,many\n"))
+	assert.True(t, errors.Is(err, ErrInvalidCSV))
+}
+
+func TestCSVByCountyRows(t *testing.T) {
+	data := "UID,Admin2,Province_State,Country_Region,Population,3/1/20,3/2/20\n" +
+		"84006037,Los Angeles,California,US,10039107,1,2\n" +
+		"84022071,Orleans Parish,Louisiana,US,390144,0,1\n" +
+		"84080006,Out of CA,California,US,0,0,0\n"
+
+	config := CSVConfig{
+		CountryColumn:    "Country_Region",
+		StateColumn:      "Province_State",
+		CountyColumn:     "Admin2",
+		PopulationColumn: "Population",
+		CountySuffix:     " County",
+		Regions: []CSVRegion{
+			{Name: "US", Boundary: schema.CdsUSA},
+			{Name: "orleans parish", Boundary: "Orleans Parish"},
+		},
+	}.withDefaults()
+	assert.Equal(t, "1/2/06", config.DateFormat)
+
+	records, err := NewCSV(schema.CdsUSA, schema.CDSLevelCounty, config, nil, "").Normalize([]byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, records, 6)
+	assert.Equal(t, "Los Angeles County, California, United States", records[0].Name)
+	assert.Equal(t, "Los Angeles County", records[0].County)
+	assert.Equal(t, float64(10039107), records[0].Population)
+	assert.Equal(t, "Orleans Parish", records[1].County)
+	assert.Equal(t, "Louisiana", records[1].State)
+}
+
+func TestCSVByDateRows(t *testing.T) {
+	data := "date,World,Taiwan,Iceland\n" +
+		"2020-03-01,87000,40,\n" +
+		"2020-03-02,89000,41,6\n"
+
+	f, err := ioutil.TempFile("", "owid-*.csv")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(data)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	source, err := NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCSV, Country: schema.CdsIceland, Level: schema.CDSLevelCountry,
+		File: f.Name(), CSV: CSVConfig{DateColumn: "date", DateFormat: "2006-01-02"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "csv:Iceland", source.Name())
+
+	data2, err := source.Fetch(context.Background())
+	assert.NoError(t, err)
+	records, err := source.Normalize(data2)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "2020-03-02", records[0].ReportTimeDate)
+	assert.Equal(t, float64(6), records[0].Cases)
+}
+
+func TestCSVActiveCases(t *testing.T) {
+	data := "Province/State,Country/Region,3/1/20,3/2/20,3/3/20,3/4/20,3/6/20,3/7/20\n" +
+		",Taiwan,1,3,6,10,12,8\n"
+
+	config := CSVConfig{ActiveDays: 2}.withDefaults()
+	records, err := NewCSV(schema.CdsTaiwan, schema.CDSLevelCountry, config, nil, "").Normalize([]byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, records, 6)
+
+	active := make([]float64, 0, len(records))
+	for _, r := range records {
+		active = append(active, r.Active)
+	}
+	// the new cases of the last two days, based on the latest count on or before two days ago,
+	// and a count corrected down has no active case
+	assert.Equal(t, []float64{1, 3, 5, 7, 2, 0}, active)
+
+	// a case is active for 14 days by default
+	assert.Equal(t, 14, CSVConfig{}.withDefaults().ActiveDays)
+	records, err = NewCSV(schema.CdsTaiwan, schema.CDSLevelCountry, DefaultCSVConfig, nil, "").Normalize([]byte(data))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(12), records[4].Active)
+}
+
+func TestNewCSVFromConfig(t *testing.T) {
+	_, err := NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCSV, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCSV, Country: schema.CdsUSA, Level: schema.CDSLevelState,
+		URL: "https://example.com/owid.csv", CSV: CSVConfig{DateColumn: "date"}})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCSV, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry,
+		URL: "https://example.com/jhu.csv", Format: string(CDSDaily)})
+	assert.True(t, errors.Is(err, ErrInvalidConfirmSource))
+
+	_, err = NewConfirmSource(ConfirmSourceConfig{Type: ConfirmSourceCSV, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry,
+		URL: "https://example.com/jhu.csv"})
+	assert.NoError(t, err)
+}
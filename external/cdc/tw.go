@@ -1,15 +1,21 @@
 package cdc
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/bitmark-inc/autonomy-api/store"
+	"github.com/bitmark-inc/autonomy-api/consts"
+	"github.com/bitmark-inc/autonomy-api/schema"
 )
 
+const twURL = "https://od.cdc.gov.tw/eic/Weekly_Age_County_Gender_19CoV.json"
+
 // Taiwan government returns json with chinese key
 type twCovid struct {
 	Year           string `json:"診斷年份"`
@@ -21,36 +27,78 @@ type twCovid struct {
 	ConfirmedCount int    `json:"確定病例數,string"`
 }
 
+// TWCDC is the source of the confirmed cases of the counties of Taiwan published by Taiwan CDC.
+// Recoveries are not published, so the active cases are the confirmed cases.
 type TWCDC struct {
-	URL    string
-	Result store.ConfirmCountyCount
+	url string
 }
 
-func (t *TWCDC) Run() (int, error) {
-	data, err := dataFromURL(t.URL)
-	if nil != err {
-		return 0, err
-	}
+func (t *TWCDC) Name() string {
+	return ConfirmSourceTWCDC
+}
 
+func (t *TWCDC) Country() string {
+	return schema.CdsTaiwan
+}
+
+func (t *TWCDC) Level() string {
+	return schema.CDSLevelCounty
+}
+
+func (t *TWCDC) Fetch(ctx context.Context) ([]byte, error) {
+	return dataFromURL(ctx, t.url)
+}
+
+// Normalize sums up the confirmed cases of each county. Counties of unknown names are skipped.
+func (t *TWCDC) Normalize(data []byte) ([]schema.CDSData, error) {
 	// decode json
 	var arr []twCovid
-	err = json.Unmarshal(data, &arr)
+	err := json.Unmarshal(data, &arr)
 	if nil != err {
 		log.WithFields(log.Fields{
 			"prefix":   logPrefix,
 			"error":    err,
 			"raw json": string(data),
 		}).Error("decode json")
-		return 0, err
+		return nil, err
 	}
 
-	aggregated, count := aggregateTw(arr)
-	t.Result = aggregated
-	return count, nil
+	aggregated, _ := aggregateTw(arr)
+
+	now := time.Now()
+	records := make([]schema.CDSData, 0, len(aggregated))
+	for county, count := range aggregated {
+		name, ok := consts.TwCountyEnglish[county]
+		if !ok {
+			log.WithFields(log.Fields{"prefix": logPrefix, "county": county}).Warn("unknown tw county")
+			continue
+		}
+
+		record := schema.CDSData{
+			Name:     fmt.Sprintf("%s, %s", name, schema.CdsTaiwan),
+			County:   name,
+			Country:  schema.CdsTaiwan,
+			Level:    schema.CDSLevelCounty,
+			Cases:    float64(count),
+			Active:   float64(count),
+			Location: schema.GeoJSON{Type: "Point", Coordinates: []float64{}},
+			Timezone: []string{},
+		}
+		setReportTime(&record, now, now)
+		records = append(records, record)
+	}
+
+	return records, nil
 }
 
-func dataFromURL(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+// dataFromURL downloads the data of a source by the http client with a timeout
+func dataFromURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	resp, err := httpClient.Do(req)
 	if nil != err {
 		log.WithFields(log.Fields{
 			"prefix": logPrefix,
@@ -61,6 +109,15 @@ func dataFromURL(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		log.WithFields(log.Fields{
+			"prefix": logPrefix,
+			"url":    url,
+			"status": resp.StatusCode,
+		}).Error("get tw cdc daily confirm cases")
+		return []byte{}, fmt.Errorf("%w: %d", ErrHTTPStatus, resp.StatusCode)
+	}
+
 	data, err := ioutil.ReadAll(resp.Body)
 	if nil != err {
 		log.WithFields(log.Fields{
@@ -86,9 +143,9 @@ func aggregateTw(data []twCovid) (map[string]int, int) {
 	return countyMapping, count
 }
 
-// NewTw - new tw cdc crawler
-func NewTw(url string) CDC {
+// NewTw - new tw cdc source
+func NewTw(url string) ConfirmSource {
 	return &TWCDC{
-		URL: url,
+		url: url,
 	}
 }
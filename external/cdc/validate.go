@@ -0,0 +1,122 @@
+package cdc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+// Validation checks the confirmed case records of a source before they are saved.
+// A record is quarantined if its active cases drop by MaxDrop or more from the previous
+// record of its area, which is only checked when the previous active cases are at least MinActive.
+type Validation struct {
+	MaxDrop   float64 `mapstructure:"max_drop"`
+	MinActive float64 `mapstructure:"min_active"`
+}
+
+var DefaultValidation = Validation{
+	MaxDrop:   0.9,
+	MinActive: 10,
+}
+
+// PreviousRecord returns the latest record of the area of a record reported before it, or nil if there is none
+type PreviousRecord func(record schema.CDSData) (*schema.CDSData, error)
+
+// WithDefaults fills the fields not given by the default values
+func (v Validation) WithDefaults() Validation {
+	if v.MaxDrop <= 0 {
+		v.MaxDrop = DefaultValidation.MaxDrop
+	}
+	if v.MinActive <= 0 {
+		v.MinActive = DefaultValidation.MinActive
+	}
+	return v
+}
+
+// Validate splits the records into the valid ones and the quarantined ones. Each record is compared
+// with the previous valid record of its area in the records, or the one given by previous.
+func (v Validation) Validate(records []schema.CDSData, previous PreviousRecord) ([]schema.CDSData, []schema.ConfirmQuarantine, error) {
+	type area struct {
+		country, state, county string
+	}
+	type report struct {
+		area
+		ts int64
+	}
+
+	sorted := make([]schema.CDSData, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ReportTime < sorted[j].ReportTime
+	})
+
+	reports := make(map[report]int)
+	for _, r := range sorted {
+		reports[report{area{r.Country, r.State, r.County}, r.ReportTime}]++
+	}
+
+	now := time.Now().UTC()
+	last := make(map[area]*schema.CDSData)
+	valid := make([]schema.CDSData, 0, len(sorted))
+	quarantined := make([]schema.ConfirmQuarantine, 0)
+	for _, r := range sorted {
+		a := area{r.Country, r.State, r.County}
+		prev, ok := last[a]
+		if !ok {
+			p, err := previous(r)
+			if err != nil {
+				return nil, nil, fmt.Errorf("previous record of %s: %w", r.Name, err)
+			}
+			prev = p
+			last[a] = p
+		}
+
+		reasons := v.check(r, prev)
+		if reports[report{a, r.ReportTime}] > 1 {
+			reasons = append(reasons, schema.QuarantineDuplicate)
+		}
+
+		if len(reasons) > 0 {
+			quarantined = append(quarantined, schema.ConfirmQuarantine{
+				Record:    r,
+				Previous:  prev,
+				Reasons:   reasons,
+				Status:    schema.QuarantinePending,
+				CreatedAt: now,
+			})
+			continue
+		}
+
+		record := r
+		last[a] = &record
+		valid = append(valid, r)
+	}
+
+	return valid, quarantined, nil
+}
+
+// check returns the reasons why a record is not valid compared with the previous record of its area
+func (v Validation) check(r schema.CDSData, prev *schema.CDSData) []string {
+	reasons := []string{}
+	if r.Cases < 0 || r.Deaths < 0 || r.Recovered < 0 || r.Active < 0 {
+		reasons = append(reasons, schema.QuarantineNegativeCount)
+	}
+	if r.Deaths > r.Cases || r.Recovered > r.Cases || r.Active > r.Cases {
+		reasons = append(reasons, schema.QuarantineOverCases)
+	}
+
+	if prev == nil {
+		return reasons
+	}
+
+	// the cases and the deaths are cumulative
+	if r.Cases < prev.Cases || r.Deaths < prev.Deaths {
+		reasons = append(reasons, schema.QuarantineDecrease)
+	}
+	if prev.Active >= v.MinActive && (prev.Active-r.Active)/prev.Active >= v.MaxDrop {
+		reasons = append(reasons, schema.QuarantineSharpDrop)
+	}
+	return reasons
+}
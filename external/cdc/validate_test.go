@@ -0,0 +1,79 @@
+package cdc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func taipeiRecord(ts int64, cases, deaths, active float64) schema.CDSData {
+	return schema.CDSData{
+		Name: "Taipei City", County: "Taipei City", Country: schema.CdsTaiwan, Level: schema.CDSLevelCounty,
+		Cases: cases, Deaths: deaths, Active: active, ReportTime: ts,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	stored := taipeiRecord(100, 200, 2, 100)
+	previous := func(record schema.CDSData) (*schema.CDSData, error) {
+		assert.Equal(t, int64(200), record.ReportTime)
+		return &stored, nil
+	}
+
+	records := []schema.CDSData{
+		taipeiRecord(400, 230, 3, 5),   // drops by 95% from the day before
+		taipeiRecord(300, 190, 3, 100), // cases decrease, compared with the day before
+		taipeiRecord(200, 220, 3, 100),
+		taipeiRecord(500, 240, 3, -1),
+	}
+
+	valid, quarantined, err := DefaultValidation.Validate(records, previous)
+	assert.NoError(t, err)
+	assert.Equal(t, []schema.CDSData{records[2]}, valid)
+	assert.Len(t, quarantined, 3)
+
+	assert.Equal(t, int64(300), quarantined[0].Record.ReportTime)
+	assert.Equal(t, []string{schema.QuarantineDecrease}, quarantined[0].Reasons)
+	assert.Equal(t, records[2], *quarantined[0].Previous)
+	assert.Equal(t, schema.QuarantinePending, quarantined[0].Status)
+
+	assert.Equal(t, []string{schema.QuarantineSharpDrop}, quarantined[1].Reasons)
+	assert.Equal(t, records[2], *quarantined[1].Previous)
+
+	assert.Equal(t, []string{schema.QuarantineNegativeCount, schema.QuarantineSharpDrop}, quarantined[2].Reasons)
+}
+
+func TestValidateDuplicate(t *testing.T) {
+	previous := func(schema.CDSData) (*schema.CDSData, error) {
+		return nil, nil
+	}
+
+	records := []schema.CDSData{
+		taipeiRecord(100, 5, 0, 5),
+		taipeiRecord(100, 6, 0, 6),
+		taipeiRecord(100, 2, 3, 0),
+	}
+	records[2].County = "New Taipei City"
+
+	valid, quarantined, err := DefaultValidation.Validate(records, previous)
+	assert.NoError(t, err)
+	assert.Empty(t, valid)
+	assert.Len(t, quarantined, 3)
+	assert.Equal(t, []string{schema.QuarantineDuplicate}, quarantined[0].Reasons)
+	assert.Equal(t, []string{schema.QuarantineDuplicate}, quarantined[1].Reasons)
+	assert.Equal(t, []string{schema.QuarantineOverCases}, quarantined[2].Reasons)
+
+	errLookup := errors.New("lookup failure")
+	_, _, err = DefaultValidation.Validate(records, func(schema.CDSData) (*schema.CDSData, error) {
+		return nil, errLookup
+	})
+	assert.True(t, errors.Is(err, errLookup))
+}
+
+func TestValidationWithDefaults(t *testing.T) {
+	assert.Equal(t, DefaultValidation, Validation{}.WithDefaults())
+	assert.Equal(t, 0.5, Validation{MaxDrop: 0.5}.WithDefaults().MaxDrop)
+}
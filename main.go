@@ -28,6 +28,9 @@ import (
 	"github.com/bitmark-inc/autonomy-api/api"
 	"github.com/bitmark-inc/autonomy-api/external/aqi"
 	"github.com/bitmark-inc/autonomy-api/geo"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/store"
 	"github.com/bitmark-inc/autonomy-api/utils"
 
 	bitmarksdk "github.com/bitmark-inc/bitmark-sdk-go"
@@ -196,7 +199,82 @@ func main() {
 		),
 	)
 
+	if err := score.SetupFormula(viper.GetString("score.formula.default"), viper.GetStringMapString("score.formula.accounts")); err != nil {
+		log.Panicf("setup score formula with error: %s", err)
+	}
+
+	var scoreBands []schema.ScoreBand
+	if err := viper.UnmarshalKey("score.bands", &scoreBands); err != nil {
+		log.Panicf("setup score bands with error: %s", err)
+	}
+	if err := score.SetupScoreBands(scoreBands); err != nil {
+		log.Panicf("setup score bands with error: %s", err)
+	}
+
+	var spikeDetector score.SpikeDetector
+	if err := viper.UnmarshalKey("score.spike", &spikeDetector); err != nil {
+		log.Panicf("setup symptom spike detector with error: %s", err)
+	}
+	if err := score.SetupSpikeDetector(spikeDetector); err != nil {
+		log.Panicf("setup symptom spike detector with error: %s", err)
+	}
+
+	var scoreWindows map[string]schema.ScoreWindow
+	if err := viper.UnmarshalKey("score.windows", &scoreWindows); err != nil {
+		log.Panicf("setup score windows with error: %s", err)
+	}
+	if err := score.SetupScoreWindows(scoreWindows); err != nil {
+		log.Panicf("setup score windows with error: %s", err)
+	}
+
+	var neighborhood score.Neighborhood
+	if err := viper.UnmarshalKey("score.neighborhood", &neighborhood); err != nil {
+		log.Panicf("setup neighborhood with error: %s", err)
+	}
+	if err := score.SetupNeighborhood(neighborhood); err != nil {
+		log.Panicf("setup neighborhood with error: %s", err)
+	}
+
+	if err := score.SetupConfirmMode(viper.GetString("score.confirm.mode")); err != nil {
+		log.Panicf("setup confirm mode with error: %s", err)
+	}
+
+	var confidenceRule score.ConfidenceRule
+	if err := viper.UnmarshalKey("score.confidence", &confidenceRule); err != nil {
+		log.Panicf("setup confidence rule with error: %s", err)
+	}
+	if err := score.SetupConfidenceRule(confidenceRule); err != nil {
+		log.Panicf("setup confidence rule with error: %s", err)
+	}
+
+	var forecast score.Forecast
+	if err := viper.UnmarshalKey("score.forecast", &forecast); err != nil {
+		log.Panicf("setup forecast with error: %s", err)
+	}
+	if err := score.SetupForecast(forecast); err != nil {
+		log.Panicf("setup forecast with error: %s", err)
+	}
+
+	var airQuality score.AirQuality
+	if err := viper.UnmarshalKey("score.air_quality", &airQuality); err != nil {
+		log.Panicf("setup air quality with error: %s", err)
+	}
+	if err := score.SetupAirQuality(airQuality); err != nil {
+		log.Panicf("setup air quality with error: %s", err)
+	}
+
+	var privacyGuard store.PrivacyGuard
+	if err := viper.UnmarshalKey("privacy", &privacyGuard); err != nil {
+		log.Panicf("setup privacy guard with error: %s", err)
+	}
+	if err := store.SetupPrivacyGuard(privacyGuard); err != nil {
+		log.Panicf("setup privacy guard with error: %s", err)
+	}
+
 	aqiClient := aqi.New(viper.GetString("aqi.key"), "")
+	if viper.GetString("aqi.key") != "" {
+		store.SetAQIClient(aqiClient)
+	}
 
 	// Init http server
 	server = api.NewServer(
@@ -0,0 +1,21 @@
+package schema
+
+import (
+	"time"
+)
+
+const (
+	AirQualityCollection = "airQuality"
+
+	// AirQualityCellPrecision is the geohash precision of the cells which air quality is looked up by
+	AirQualityCellPrecision = 5
+	// AirQualityTTL is how long the air quality of a cell is cached
+	AirQualityTTL = time.Hour
+)
+
+// AirQuality is the cached air quality index of a geohash cell
+type AirQuality struct {
+	Geohash   string    `bson:"_id"`
+	AQI       int       `bson:"aqi"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
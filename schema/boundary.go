@@ -15,4 +15,6 @@ type Boundary struct {
 	State    string   `bson:"state"`
 	County   string   `bson:"county"`
 	Geometry Geometry `bson:"geometry"`
+	// Population is the number of people living in the boundary if it is known
+	Population float64 `bson:"population,omitempty"`
 }
@@ -83,8 +83,8 @@ func migrateMongo() error {
 		return err
 	}
 
-	if err := setupCDSConfirmCollection(client); err != nil {
-		fmt.Println("failed to set up collection `cds confirm`: ", err)
+	if err := migrateCDSConfirmCollections(client); err != nil {
+		fmt.Println("failed to migrate collection `cds confirm`: ", err)
 		return err
 	}
 
@@ -177,37 +177,50 @@ func BehaviorListNullToEmptyArray(client *mongo.Client) error {
 	fmt.Println("Migration: replace Customized Behavior List with Empty Array result:", result.MatchedCount)
 	return nil
 }
-func setupCDSConfirmCollection(client *mongo.Client) error {
+
+// legacyCDSConfirmCollections are the collections which kept the confirmed case data of each country
+var legacyCDSConfirmCollections = []string{"ConfirmUS", "ConfirmTaiwan", "ConfirmIceland"}
+
+// migrateCDSConfirmCollections copies the confirmed case data from the collections of each
+// country into the collection of all countries. Records which are already copied are skipped.
+func migrateCDSConfirmCollections(client *mongo.Client) error {
+	ctx := context.Background()
 	db := client.Database(viper.GetString("mongo.database"))
-	cdsIndex := mongo.IndexModel{
-		Keys:    bson.D{{"name", 1}, {"report_ts", 1}},
-		Options: options.Index().SetUnique(true),
-	}
-	usCol := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsUSA)]
-	_, err := db.Collection(usCol).Indexes().CreateOne(context.Background(), cdsIndex)
 
-	if nil != err {
-		fmt.Println("collection", usCol, "mongodb create name and report_ts combined index with error: ", err)
-		return err
-	}
-	fmt.Println("confirm collection initialized", usCol)
-	twCol := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)]
-	_, err = db.Collection(twCol).Indexes().CreateOne(context.Background(), cdsIndex)
+	for _, legacy := range legacyCDSConfirmCollections {
+		cursor, err := db.Collection(legacy).Find(ctx, bson.M{})
+		if err != nil {
+			return err
+		}
 
-	if nil != err {
-		fmt.Println("collection", twCol, "mongodb create name and report_ts combined index with error: ", err)
-		return err
-	}
-	fmt.Println("confirm collection initialized", twCol)
+		copied := 0
+		for cursor.Next(ctx) {
+			var record schema.CDSData
+			if err := cursor.Decode(&record); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
 
-	icelandCol := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsIceland)]
-	_, err = db.Collection(icelandCol).Indexes().CreateOne(context.Background(), cdsIndex)
+			filter := bson.M{
+				"country":   record.Country,
+				"state":     record.State,
+				"county":    record.County,
+				"report_ts": record.ReportTime,
+			}
+			result, err := db.Collection(schema.ConfirmCDSCollection).UpdateOne(ctx, filter,
+				bson.M{"$setOnInsert": record}, options.Update().SetUpsert(true))
+			if err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+			if result.UpsertedCount > 0 {
+				copied++
+			}
+		}
+		cursor.Close(ctx)
 
-	if nil != err {
-		fmt.Println("collection", icelandCol, "mongodb create name and report_ts combined index with error: ", err)
-		return err
+		fmt.Println("Migration: copy confirm collection", legacy, "records:", copied)
 	}
-	fmt.Println("confirm collection initialized", icelandCol)
 
 	return nil
 }
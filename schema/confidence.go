@@ -0,0 +1,21 @@
+package schema
+
+type ConfidenceLevel string
+
+const (
+	ConfidenceLow    ConfidenceLevel = "low"
+	ConfidenceMedium ConfidenceLevel = "medium"
+	ConfidenceHigh   ConfidenceLevel = "high"
+)
+
+// Confidence describes how much data a metric is calculated from
+type Confidence struct {
+	// Reporters is the number of users who have reported symptoms or behaviors in the area
+	Reporters int `json:"reporters" bson:"reporters"`
+	// Reports is the number of symptom and behavior reports in the area
+	Reports int `json:"reports" bson:"reports"`
+	// ConfirmDataAge is the number of days since the latest confirmed cases are reported.
+	// It is -1 if there is no confirmed case data of the area.
+	ConfirmDataAge int             `json:"confirm_data_age" bson:"confirm_data_age"`
+	Level          ConfidenceLevel `json:"level" bson:"level"`
+}
@@ -1,6 +1,7 @@
 package schema
 
-type CDSCountryType string
+// ConfirmCDSCollection keeps the confirmed case data of all countries, keyed by country, state, county and report time
+const ConfirmCDSCollection = "confirmCDS"
 
 const (
 	CdsUSA     = "United States"
@@ -8,11 +9,15 @@ const (
 	CdsIceland = "Iceland"
 )
 
-var CDSCountyCollectionMatrix = map[CDSCountryType]string{
-	CDSCountryType(CdsUSA):     "ConfirmUS",
-	CDSCountryType(CdsTaiwan):  "ConfirmTaiwan",
-	CDSCountryType(CdsIceland): "ConfirmIceland",
-}
+// the boundary levels which confirmed cases are reported by
+const (
+	CDSLevelCountry = "country"
+	CDSLevelState   = "state"
+	CDSLevelCounty  = "county"
+)
+
+// CDSLevels are the boundary levels ordered from the most specific one
+var CDSLevels = []string{CDSLevelCounty, CDSLevelState, CDSLevelCountry}
 
 type CDSData struct {
 	Name           string   `json:"name" bson:"name"`
@@ -25,6 +30,7 @@ type CDSData struct {
 	Deaths         float64  `json:"deaths" bson:"deaths"`
 	Recovered      float64  `json:"recovered" bson:"recovered"`
 	Active         float64  `json:"active" bson:"active"`
+	Population     float64  `json:"population" bson:"population"`
 	ReportTime     int64    `json:"report_ts" bson:"report_ts"`
 	UpdateTime     int64    `json:"update_ts"  bson:"update_ts"`
 	ReportTimeDate string   `json:"report_date" bson:"report_date"`
@@ -36,6 +42,8 @@ type CDSData struct {
 }
 
 type CDSScoreDataSet struct {
-	Name  string  `json:"name" bson:"name"`
-	Cases float64 `json:"cases" bson:"cases"`
+	Name       string  `json:"name" bson:"name"`
+	Cases      float64 `json:"cases" bson:"cases"`
+	Population float64 `json:"population" bson:"population"`
+	ReportTime int64   `json:"report_ts" bson:"report_ts"`
 }
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ConfirmQuarantineCollection keeps the confirmed case records which fail the validation of the crawler
+const ConfirmQuarantineCollection = "confirmQuarantine"
+
+// the review status of a quarantined record
+const (
+	QuarantinePending  = "pending"
+	QuarantineApproved = "approved"
+	QuarantineRejected = "rejected"
+)
+
+// the reasons why a record is quarantined
+const (
+	QuarantineNegativeCount = "negative_count"
+	QuarantineOverCases     = "over_cases"
+	QuarantineDecrease      = "cumulative_decrease"
+	QuarantineSharpDrop     = "sharp_drop"
+	QuarantineDuplicate     = "duplicate_report_time"
+)
+
+// ConfirmQuarantine is a confirmed case record held back from the scores until it is reviewed.
+// Previous is the record of the area reported before, which the record is compared with.
+type ConfirmQuarantine struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Source     string             `json:"source" bson:"source"`
+	Record     CDSData            `json:"record" bson:"record"`
+	Previous   *CDSData           `json:"previous,omitempty" bson:"previous,omitempty"`
+	Reasons    []string           `json:"reasons" bson:"reasons"`
+	Status     string             `json:"status" bson:"status"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+}
@@ -1,9 +0,0 @@
-package schema
-
-type Confirm struct {
-	County        string `bson:"county"`
-	Count         int    `bson:"count"`
-	Country       string `bson:"country"`
-	UpdateTime    int64  `bson:"update_time"`
-	DiffYesterday int    `bson:"diff_yesterday"`
-}
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CrawlRunCollection = "crawlRun"
+
+	// CrawlRunTTL is how long the history of crawler runs is kept
+	CrawlRunTTL = 30 * 24 * time.Hour
+)
+
+// CrawlRun is a run of the crawler of a confirm source. Attempts is the number of times
+// the data is fetched, Quarantined is the number of records failing the validation,
+// and Error is the last error of the run if it fails.
+type CrawlRun struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Source      string             `bson:"source"`
+	Country     string             `bson:"country"`
+	Level       string             `bson:"level"`
+	StartedAt   time.Time          `bson:"started_at"`
+	EndedAt     time.Time          `bson:"ended_at"`
+	Attempts    int                `bson:"attempts"`
+	Records     int                `bson:"records"`
+	Quarantined int                `bson:"quarantined"`
+	Succeeded   bool               `bson:"succeeded"`
+	Error       string             `bson:"error,omitempty"`
+}
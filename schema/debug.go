@@ -5,4 +5,6 @@ type Debug struct {
 	Users    int    `json:"users"`
 	AQI      int    `json:"aqi"`
 	Symptoms int    `json:"symptoms"`
+	// Suppressed tells if the symptoms are suppressed or coarsened because they are reported by too few users
+	Suppressed bool `json:"suppressed"`
 }
@@ -0,0 +1,26 @@
+package schema
+
+// ForecastValue is a projected value and the bounds of its prediction interval
+type ForecastValue struct {
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// ForecastDay is the projection of a day
+type ForecastDay struct {
+	Date string `json:"date"`
+	// Confirm is the number of confirmed cases of the day
+	Confirm   ForecastValue `json:"confirm"`
+	Symptoms  ForecastValue `json:"symptoms"`
+	Behaviors ForecastValue `json:"behaviors"`
+	Score     ForecastValue `json:"score"`
+}
+
+// Forecast is the projection of a metric for the coming days
+type Forecast struct {
+	Model string `json:"model"`
+	// Interval is the probability which the prediction intervals cover
+	Interval float64       `json:"interval"`
+	Days     []ForecastDay `json:"days"`
+}
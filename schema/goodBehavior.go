@@ -27,9 +27,8 @@ var DefaultBehaviorWeightMatrix = map[GoodBehaviorType]BehaviorWeight{
 }
 
 const (
-	BehaviorCollection          = "behaviors"
-	BehaviorReportCollection    = "behaviorReport"
-	TotalOfficialBehaviorWeight = float64(6)
+	BehaviorCollection       = "behaviors"
+	BehaviorReportCollection = "behaviorReport"
 )
 
 type BehaviorSource string
@@ -0,0 +1,27 @@
+package schema
+
+import (
+	"time"
+)
+
+const (
+	HeatmapCellCollection = "heatmapCell"
+
+	// HeatmapCellPrecision is the geohash precision of the cells whose scores are calculated
+	HeatmapCellPrecision = 6
+	// HeatmapCellTTL is how long a cell is kept since it is last updated
+	HeatmapCellTTL = 24 * time.Hour
+)
+
+// HeatmapCell is the score of a geohash cell where symptoms or behaviors are reported
+type HeatmapCell struct {
+	Geohash string `bson:"_id"`
+	// Center is the center of the cell where the score is calculated
+	Center     GeoJSON          `bson:"center"`
+	Address    AddressComponent `bson:"address"`
+	Score      float64          `bson:"score"`
+	Confidence Confidence       `bson:"confidence"`
+	// Suppressed tells if the symptoms or behaviors reported in the cell are reported by too few users
+	Suppressed bool      `bson:"suppressed"`
+	UpdatedAt  time.Time `bson:"updated_at"`
+}
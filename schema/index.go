@@ -55,6 +55,14 @@ func (m *MongoDBIndexer) IndexAll() {
 	panicIfError(m.IndexSymptomCollection())
 	panicIfError(m.IndexSymptomReportCollection())
 	panicIfError(m.IndexCDSConfirmCollection())
+	panicIfError(m.IndexMetricHistoryCollection())
+	panicIfError(m.IndexPrivacyBudgetCollection())
+	panicIfError(m.IndexHeatmapCellCollection())
+	panicIfError(m.IndexRegionMetricCollection())
+	panicIfError(m.IndexRegionRawMetricCollection())
+	panicIfError(m.IndexAirQualityCollection())
+	panicIfError(m.IndexCrawlRunCollection())
+	panicIfError(m.IndexConfirmQuarantineCollection())
 }
 
 func (m *MongoDBIndexer) IndexProfileCollection() error {
@@ -170,21 +178,143 @@ func (m *MongoDBIndexer) IndexBoundryCollection() error {
 }
 
 func (m *MongoDBIndexer) IndexCDSConfirmCollection() error {
-	cdsIndex := mongo.IndexModel{
-		Keys:    bson.D{{"name", 1}, {"report_ts", 1}},
+	return m.createIndex(ConfirmCDSCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"country", 1},
+			{"state", 1},
+			{"county", 1},
+			{"report_ts", 1},
+		},
 		Options: options.Index().SetUnique(true),
+	})
+}
+
+func (m *MongoDBIndexer) IndexMetricHistoryCollection() error {
+	if err := m.createIndex(MetricHistoryCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"account_number", 1},
+			{"poi_id", 1},
+			{"created_at", 1},
+		},
+	}); err != nil {
+		return err
 	}
-	usCollection := CDSCountyCollectionMatrix[CDSCountryType(CdsUSA)]
-	if err := m.createIndex(usCollection, cdsIndex); err != nil {
+
+	return m.createIndex(MetricHistoryCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"created_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(MetricHistoryTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexPrivacyBudgetCollection() error {
+	if err := m.createIndex(PrivacyBudgetCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"area", 1},
+			{"day", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	return m.createIndex(PrivacyBudgetCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"created_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(PrivacyBudgetTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexHeatmapCellCollection() error {
+	if err := m.createIndex(HeatmapCellCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"center": "2dsphere",
+		},
+	}); err != nil {
+		return err
+	}
+
+	return m.createIndex(HeatmapCellCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"updated_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(HeatmapCellTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexRegionMetricCollection() error {
+	if err := m.createIndex(RegionMetricCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"level":   1,
+			"country": 1,
+		},
+	}); err != nil {
 		return err
 	}
-	twCollection := CDSCountyCollectionMatrix[CDSCountryType(CdsTaiwan)]
-	if err := m.createIndex(twCollection, cdsIndex); err != nil {
+
+	return m.createIndex(RegionMetricCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"updated_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(RegionMetricTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexRegionRawMetricCollection() error {
+	return m.createIndex(RegionRawMetricCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"updated_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(RegionRawMetricTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexAirQualityCollection() error {
+	return m.createIndex(AirQualityCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"updated_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(AirQualityTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexCrawlRunCollection() error {
+	if err := m.createIndex(CrawlRunCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"source", 1},
+			{"started_at", -1},
+		},
+	}); err != nil {
 		return err
 	}
-	icelandCollection := CDSCountyCollectionMatrix[CDSCountryType(CdsIceland)]
-	if err := m.createIndex(icelandCollection, cdsIndex); err != nil {
+
+	return m.createIndex(CrawlRunCollection, mongo.IndexModel{
+		Keys: bson.M{
+			"started_at": 1,
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(CrawlRunTTL.Seconds())),
+	})
+}
+
+func (m *MongoDBIndexer) IndexConfirmQuarantineCollection() error {
+	if err := m.createIndex(ConfirmQuarantineCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"status", 1},
+			{"created_at", -1},
+		},
+	}); err != nil {
 		return err
 	}
-	return nil
+
+	return m.createIndex(ConfirmQuarantineCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{"source", 1},
+			{"record.country", 1},
+			{"record.state", 1},
+			{"record.county", 1},
+			{"record.report_ts", 1},
+		},
+	})
 }
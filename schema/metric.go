@@ -6,23 +6,38 @@ import (
 
 type ConfirmDetail struct {
 	ContinuousData []CDSScoreDataSet `json:"-" bson:"data"`
-	Score          float64           `json:"score" bson:"score"`
+	// Population is the population of the area where the confirmed cases are reported
+	Population float64 `json:"-" bson:"population"`
+	Score      float64 `json:"score" bson:"score"`
+}
+
+// AirQualityDetail is the air quality index of a location. Available is false if the index is not known.
+type AirQualityDetail struct {
+	AQI       int     `json:"aqi" bson:"aqi"`
+	Available bool    `json:"available" bson:"available"`
+	Score     float64 `json:"score" bson:"score"`
 }
 
 type BehaviorDetail struct {
-	Score                 float64        `json:"score" bson:"score"`
-	ReportTimes           int            `json:"-" bson:"-"`
-	TodayDistribution     map[string]int `json:"-" bson:"-"`
-	YesterdayDistribution map[string]int `json:"-" bson:"-"`
+	Score                 float64            `json:"score" bson:"score"`
+	ReportTimes           float64            `json:"-" bson:"-"`
+	TodayDistribution     map[string]float64 `json:"-" bson:"-"`
+	YesterdayDistribution map[string]float64 `json:"-" bson:"-"`
 }
 
 type SymptomDetail struct {
-	Score           float64            `json:"score" bson:"score"`
-	TotalPeople     float64            `json:"-" bson:"-"`
-	TodayData       NearestSymptomData `json:"-"  bson:"-"`
-	YesterdayData   NearestSymptomData `json:"-"  bson:"-"`
-	LastSpikeUpdate time.Time          `json:"-" bson:"last_spike_update"`
-	LastSpikeList   []string           `json:"-" bson:"last_spike_types"`
+	Score         float64            `json:"score" bson:"score"`
+	TotalPeople   float64            `json:"-" bson:"-"`
+	TodayData     NearestSymptomData `json:"-"  bson:"-"`
+	YesterdayData NearestSymptomData `json:"-"  bson:"-"`
+	// BaselineData is the daily distributions before today, from the oldest day to yesterday
+	BaselineData []SymptomDistribution `json:"-" bson:"-"`
+	// LatestDayData is the distribution of today which spikes are detected from.
+	// TodayData is used instead if it is not collected.
+	LatestDayData   SymptomDistribution `json:"-" bson:"-"`
+	LastSpikeUpdate time.Time           `json:"-" bson:"last_spike_update"`
+	LastSpikeList   []string            `json:"-" bson:"last_spike_types"`
+	LastSpikes      []SymptomSpike      `json:"-" bson:"last_spikes"`
 }
 
 type NearestSymptomData struct {
@@ -30,9 +45,10 @@ type NearestSymptomData struct {
 }
 
 type Details struct {
-	Confirm   ConfirmDetail  `json:"confirm" bson:"confirm"`
-	Behaviors BehaviorDetail `json:"behaviors" bson:"behaviors"`
-	Symptoms  SymptomDetail  `json:"symptoms" bson:"symptoms"`
+	Confirm    ConfirmDetail    `json:"confirm" bson:"confirm"`
+	Behaviors  BehaviorDetail   `json:"behaviors" bson:"behaviors"`
+	Symptoms   SymptomDetail    `json:"symptoms" bson:"symptoms"`
+	AirQuality AirQualityDetail `json:"air_quality" bson:"air_quality"`
 }
 
 type Metric struct {
@@ -44,5 +60,15 @@ type Metric struct {
 	BehaviorDelta  float64 `json:"behavior_delta" bson:"behavior_delta"`
 	Score          float64 `json:"score" bson:"score"`
 	LastUpdate     int64   `json:"last_update" bson:"last_update"`
-	Details        Details `json:"details" bson:"details"`
+	FormulaVersion string  `json:"formula_version" bson:"formula_version"`
+	// Radius is the radius in meters of the area where reports are collected
+	Radius     int        `json:"radius" bson:"radius"`
+	Confidence Confidence `json:"confidence" bson:"confidence"`
+	// Suppressed tells if the symptoms or behaviors reported nearby are suppressed or coarsened
+	// because they are reported by too few users
+	Suppressed bool    `json:"suppressed" bson:"suppressed"`
+	Details    Details `json:"details" bson:"details"`
+
+	// Band is the band of the score which is resolved when a metric is responded
+	Band *ScoreBand `json:"band,omitempty" bson:"-"`
 }
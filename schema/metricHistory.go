@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MetricHistoryCollection = "metricHistory"
+
+	// MetricHistoryTTL is how long a metric snapshot is kept
+	MetricHistoryTTL = 90 * 24 * time.Hour
+)
+
+type MetricHistoryGranularity string
+
+const (
+	MetricHistoryGranularityHour MetricHistoryGranularity = "hour"
+	MetricHistoryGranularityDay  MetricHistoryGranularity = "day"
+)
+
+// MetricHistory is a snapshot of a metric taken whenever the metric is refreshed.
+// A snapshot of the metric of an account's current location has no POI ID, and
+// a snapshot of the metric shared by a POI has no account number.
+type MetricHistory struct {
+	AccountNumber  string              `bson:"account_number,omitempty"`
+	POIID          *primitive.ObjectID `bson:"poi_id,omitempty"`
+	Score          float64             `bson:"score"`
+	SymptomScore   float64             `bson:"symptom_score"`
+	BehaviorScore  float64             `bson:"behavior_score"`
+	ConfirmScore   float64             `bson:"confirm_score"`
+	FormulaVersion string              `bson:"formula_version"`
+	CreatedAt      time.Time           `bson:"created_at"`
+}
+
+// NewMetricHistory takes a snapshot of the scores of a metric
+func NewMetricHistory(accountNumber string, poiID *primitive.ObjectID, metric Metric) MetricHistory {
+	return MetricHistory{
+		AccountNumber:  accountNumber,
+		POIID:          poiID,
+		Score:          metric.Score,
+		SymptomScore:   metric.Details.Symptoms.Score,
+		BehaviorScore:  metric.Details.Behaviors.Score,
+		ConfirmScore:   metric.Details.Confirm.Score,
+		FormulaVersion: metric.FormulaVersion,
+		CreatedAt:      time.Now().UTC(),
+	}
+}
+
+// MetricHistoryPoint is the averaged scores of the snapshots taken in a period
+type MetricHistoryPoint struct {
+	Timestamp     int64   `json:"ts"`
+	Score         float64 `json:"score"`
+	SymptomScore  float64 `json:"symptoms"`
+	BehaviorScore float64 `json:"behaviors"`
+	ConfirmScore  float64 `json:"confirm"`
+	Count         int     `json:"count"`
+}
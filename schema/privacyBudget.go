@@ -0,0 +1,20 @@
+package schema
+
+import (
+	"time"
+)
+
+const (
+	PrivacyBudgetCollection = "privacyBudget"
+
+	// PrivacyBudgetTTL is how long the spent privacy budget of a day is kept
+	PrivacyBudgetTTL = 7 * 24 * time.Hour
+)
+
+// PrivacyBudget is the privacy budget spent on releasing noisy aggregates of an area in a day
+type PrivacyBudget struct {
+	Area      string    `bson:"area"`
+	Day       string    `bson:"day"`
+	Spent     float64   `bson:"spent"`
+	CreatedAt time.Time `bson:"created_at"`
+}
@@ -11,6 +11,9 @@ const (
 // SymptomWeights is structure for customized symptom weights
 type SymptomWeights map[string]float64
 
+// BehaviorWeights is structure for customized behavior weights
+type BehaviorWeights map[string]float64
+
 var (
 	DefaultSymptomWeights = SymptomWeights{
 		"cough":            2,
@@ -21,15 +24,26 @@ var (
 		"throat":           1,
 		"loss_taste_smell": 2,
 	}
+
+	DefaultBehaviorWeights = BehaviorWeights{
+		string(CleanHand):        1,
+		string(SocialDistancing): 1,
+		string(TouchFace):        1,
+		string(WearMask):         1,
+		string(CoveringCough):    1,
+		string(CleanSurface):     1,
+	}
 )
 
 // ScoreCoefficient is structure for all customized weights for calculating personal score
 type ScoreCoefficient struct {
-	Symptoms       float64        `json:"symptoms" bson:"symptoms"`
-	Behaviors      float64        `json:"behaviors" bson:"behaviors"`
-	Confirms       float64        `json:"confirms" bson:"confirms"`
-	UpdatedAt      time.Time      `json:"-" bson:"updated_at"`
-	SymptomWeights SymptomWeights `json:"symptom_weights" bson:"symptom_weights"`
+	Symptoms        float64         `json:"symptoms" bson:"symptoms"`
+	Behaviors       float64         `json:"behaviors" bson:"behaviors"`
+	Confirms        float64         `json:"confirms" bson:"confirms"`
+	AirQuality      float64         `json:"air_quality" bson:"air_quality"`
+	UpdatedAt       time.Time       `json:"-" bson:"updated_at"`
+	SymptomWeights  SymptomWeights  `json:"symptom_weights" bson:"symptom_weights"`
+	BehaviorWeights BehaviorWeights `json:"behavior_weights" bson:"behavior_weights"`
 }
 
 type NudgeType string
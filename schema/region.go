@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RegionMetricCollection = "regionMetric"
+
+	// RegionMetricTTL is how long the metric of a region is kept since it is last updated
+	RegionMetricTTL = 7 * 24 * time.Hour
+
+	RegionRawMetricCollection = "regionRawMetric"
+
+	// RegionRawMetricTTL is how long the raw metrics of a region are cached for the region APIs
+	RegionRawMetricTTL = 5 * time.Minute
+)
+
+type RegionLevel string
+
+const (
+	RegionLevelCountry RegionLevel = "country"
+	RegionLevelState   RegionLevel = "state"
+	RegionLevelCounty  RegionLevel = "county"
+)
+
+// regionIDSeparator separates the country, the state and the county in the ID of a region
+const regionIDSeparator = "|"
+
+// Region is an administrative region made of the boundaries in it
+type Region struct {
+	Country string `bson:"country" json:"country"`
+	State   string `bson:"state" json:"state"`
+	County  string `bson:"county" json:"county"`
+}
+
+// ParseRegionID parses the ID of a region
+func ParseRegionID(id string) (Region, error) {
+	parts := strings.Split(id, regionIDSeparator)
+	if len(parts) != 3 || parts[0] == "" {
+		return Region{}, fmt.Errorf("invalid region id: %s", id)
+	}
+
+	return Region{Country: parts[0], State: parts[1], County: parts[2]}, nil
+}
+
+// ID returns the ID of a region
+func (r Region) ID() string {
+	return strings.Join([]string{r.Country, r.State, r.County}, regionIDSeparator)
+}
+
+// Level returns the level of a region
+func (r Region) Level() RegionLevel {
+	switch {
+	case r.County != "":
+		return RegionLevelCounty
+	case r.State != "":
+		return RegionLevelState
+	default:
+		return RegionLevelCountry
+	}
+}
+
+// AddressComponent returns the address of a region
+func (r Region) AddressComponent() AddressComponent {
+	return AddressComponent{Country: r.Country, State: r.State, County: r.County}
+}
+
+// RegionMetric is the score of a region which is updated periodically
+type RegionMetric struct {
+	ID     string `bson:"_id"`
+	Region `bson:",inline"`
+	Level  RegionLevel `bson:"level"`
+	Score  float64     `bson:"score"`
+	// ScoreDelta is the change of the score from the last score of the previous day
+	ScoreDelta float64 `bson:"score_delta"`
+	// PreviousScore is the last score of the previous day
+	PreviousScore float64    `bson:"previous_score"`
+	Confidence    Confidence `bson:"confidence"`
+	UpdatedAt     time.Time  `bson:"updated_at"`
+}
+
+// RegionRawMetric is the cached raw metrics of a region collected by a score window
+type RegionRawMetric struct {
+	ID     string `bson:"_id"`
+	Metric Metric `bson:"metric"`
+	// Timezone is the time zone of the region where the days of the metrics start
+	Timezone  string    `bson:"timezone"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionID(t *testing.T) {
+	regions := []Region{
+		{Country: "Taiwan"},
+		{Country: "Taiwan", County: "Taipei City"},
+		{Country: "United States", State: "New York"},
+		{Country: "United States", State: "New York", County: "Kings"},
+	}
+	levels := []RegionLevel{RegionLevelCountry, RegionLevelCounty, RegionLevelState, RegionLevelCounty}
+
+	for i, r := range regions {
+		parsed, err := ParseRegionID(r.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, r, parsed)
+		assert.Equal(t, levels[i], parsed.Level())
+	}
+
+	_, err := ParseRegionID("Taiwan")
+	assert.Error(t, err)
+	_, err = ParseRegionID("||Taipei City")
+	assert.Error(t, err)
+}
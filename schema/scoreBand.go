@@ -0,0 +1,24 @@
+package schema
+
+// ScoreBand is a named range of scores, such as the colors shown to users.
+// A score falls in a band if it is in [Lower, Upper).
+type ScoreBand struct {
+	Name  string  `json:"name" mapstructure:"name"`
+	Lower float64 `json:"lower" mapstructure:"lower"`
+	Upper float64 `json:"upper" mapstructure:"upper"`
+}
+
+type ScoreBandDirection string
+
+const (
+	ScoreBandImproved ScoreBandDirection = "improved"
+	ScoreBandWorsened ScoreBandDirection = "worsened"
+)
+
+// ScoreBandTransition describes the score of an account moving from a band to another
+type ScoreBandTransition struct {
+	AccountNumber string             `json:"account_number"`
+	Old           ScoreBand          `json:"old"`
+	New           ScoreBand          `json:"new"`
+	Direction     ScoreBandDirection `json:"direction"`
+}
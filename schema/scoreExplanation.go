@@ -0,0 +1,64 @@
+package schema
+
+// ScoreExplanation breaks a calculated score down into what drives it
+type ScoreExplanation struct {
+	FormulaVersion string                      `json:"formula_version"`
+	Score          float64                     `json:"score"`
+	Components     []ScoreComponentExplanation `json:"components"`
+	Symptoms       SymptomExplanation          `json:"symptoms"`
+	Behaviors      BehaviorExplanation         `json:"behaviors"`
+	Confirm        ConfirmExplanation          `json:"confirm"`
+}
+
+// ScoreComponentExplanation is the contribution of a sub-score to the total score
+type ScoreComponentExplanation struct {
+	Name         string  `json:"name"`
+	Coefficient  float64 `json:"coefficient"`
+	Score        float64 `json:"score"`
+	Contribution float64 `json:"contribution"`
+}
+
+type SymptomExplanation struct {
+	TotalPeople float64               `json:"total_people"`
+	TopSymptoms []SymptomContribution `json:"top_symptoms"`
+}
+
+// SymptomContribution is how many points a reported symptom takes off the symptom score
+type SymptomContribution struct {
+	ID       string  `json:"id"`
+	Count    float64 `json:"count"`
+	Weight   float64 `json:"weight"`
+	Official bool    `json:"official"`
+	Penalty  float64 `json:"penalty"`
+}
+
+type BehaviorExplanation struct {
+	ReportTimes float64                `json:"report_times"`
+	Behaviors   []BehaviorContribution `json:"behaviors"`
+}
+
+// BehaviorContribution is how many points a reported behavior adds to the behavior score
+type BehaviorContribution struct {
+	ID           string  `json:"id"`
+	Count        float64 `json:"count"`
+	Weight       float64 `json:"weight"`
+	Official     bool    `json:"official"`
+	Contribution float64 `json:"contribution"`
+}
+
+// ConfirmExplanation is how the confirmed cases are scored. Mode is the confirm mode applied,
+// which is the raw mode if the population is unknown, and Population is absent if it is unknown.
+type ConfirmExplanation struct {
+	Mode       string                  `json:"mode"`
+	Population *float64                `json:"population,omitempty"`
+	Window     []ConfirmDayExplanation `json:"window"`
+}
+
+// ConfirmDayExplanation is the confirmed cases of a day in the confirm score window,
+// the cases scaled by the confirm mode and the exponential weight applied to it
+type ConfirmDayExplanation struct {
+	DaysAgo     int     `json:"days_ago"`
+	Cases       float64 `json:"cases"`
+	ScoredCases float64 `json:"scored_cases"`
+	Weight      float64 `json:"weight"`
+}
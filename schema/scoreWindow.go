@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"time"
+)
+
+type ScoreWindowMode string
+
+const (
+	ScoreWindowCalendar ScoreWindowMode = "calendar"
+	ScoreWindowRolling  ScoreWindowMode = "rolling"
+)
+
+// ScoreWindow defines the reports of symptoms and behaviors collected for a score.
+// In the calendar mode, the reports of today are compared with the reports of yesterday.
+// In the rolling mode, the reports of the last Hours are compared with the reports of
+// the Hours before, and a report weighs half as much every HalfLifeHours.
+type ScoreWindow struct {
+	Mode          ScoreWindowMode `json:"mode" mapstructure:"mode"`
+	Hours         int             `json:"hours" mapstructure:"hours"`
+	HalfLifeHours float64         `json:"half_life_hours" mapstructure:"half_life_hours"`
+}
+
+// HalfLife returns the duration in which the weight of a report halves.
+// Reports are not decayed if it is zero.
+func (w ScoreWindow) HalfLife() time.Duration {
+	if w.Mode != ScoreWindowRolling {
+		return 0
+	}
+	return time.Duration(w.HalfLifeHours * float64(time.Hour))
+}
@@ -185,7 +185,8 @@ type SymptomReportData struct {
 	Timestamp     int64     `json:"ts" bson:"ts"`
 }
 
-type SymptomDistribution map[string]int
+// SymptomDistribution is the mapping of symptoms and their counts, which may be weighted by the report time
+type SymptomDistribution map[string]float64
 
 func (s *SymptomReportData) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
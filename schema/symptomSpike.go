@@ -0,0 +1,18 @@
+package schema
+
+type SpikeSeverity string
+
+const (
+	SpikeSeverityLow    SpikeSeverity = "low"
+	SpikeSeverityMedium SpikeSeverity = "medium"
+	SpikeSeverityHigh   SpikeSeverity = "high"
+)
+
+// SymptomSpike is a symptom reported significantly more than its baseline
+type SymptomSpike struct {
+	ID       string        `json:"id" bson:"id"`
+	Count    float64       `json:"count" bson:"count"`
+	Baseline float64       `json:"baseline" bson:"baseline"`
+	ZScore   float64       `json:"z_score" bson:"z_score"`
+	Severity SpikeSeverity `json:"severity" bson:"severity"`
+}
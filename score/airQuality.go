@@ -0,0 +1,145 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrInvalidAirQuality = fmt.Errorf("invalid air quality")
+)
+
+// AirQualityBreakpoint maps an air quality index to a sub-score
+type AirQualityBreakpoint struct {
+	AQI   int     `mapstructure:"aqi"`
+	Score float64 `mapstructure:"score"`
+}
+
+// AirQuality configures the air quality component of scores. Coefficient is the default weight of
+// the component, and zero leaves air quality out of scores. Breakpoints are ordered by increasing
+// indexes, and the sub-score of an index between two breakpoints is interpolated linearly.
+type AirQuality struct {
+	Coefficient float64                `mapstructure:"coefficient"`
+	Breakpoints []AirQualityBreakpoint `mapstructure:"breakpoints"`
+}
+
+// DefaultAirQualityBreakpoints follow the levels of the US AQI, from good (0 ~ 50) to hazardous (300+)
+var DefaultAirQualityBreakpoints = []AirQualityBreakpoint{
+	{AQI: 0, Score: 100},
+	{AQI: 50, Score: 80},
+	{AQI: 100, Score: 60},
+	{AQI: 150, Score: 40},
+	{AQI: 200, Score: 20},
+	{AQI: 300, Score: 0},
+}
+
+var DefaultAirQuality = AirQuality{
+	Coefficient: 0,
+	Breakpoints: DefaultAirQualityBreakpoints,
+}
+
+var (
+	airQualityLock sync.RWMutex
+	airQuality     = DefaultAirQuality
+)
+
+// SetupAirQuality configures the air quality component. The default breakpoints are used if no breakpoint is given.
+func SetupAirQuality(a AirQuality) error {
+	if len(a.Breakpoints) == 0 {
+		a.Breakpoints = DefaultAirQualityBreakpoints
+	}
+
+	if a.Coefficient < 0 {
+		return fmt.Errorf("%w: negative coefficient %f", ErrInvalidAirQuality, a.Coefficient)
+	}
+
+	for i, b := range a.Breakpoints {
+		if b.AQI < 0 || b.Score < 0 || b.Score > 100 {
+			return fmt.Errorf("%w: breakpoint %d is out of range", ErrInvalidAirQuality, i)
+		}
+		if i > 0 && a.Breakpoints[i-1].AQI >= b.AQI {
+			return fmt.Errorf("%w: breakpoint %d is not in increasing order", ErrInvalidAirQuality, i)
+		}
+	}
+
+	airQualityLock.Lock()
+	defer airQualityLock.Unlock()
+
+	airQuality = AirQuality{
+		Coefficient: a.Coefficient,
+		Breakpoints: append([]AirQualityBreakpoint{}, a.Breakpoints...),
+	}
+	return nil
+}
+
+// GetAirQuality returns the configured air quality component
+func GetAirQuality() AirQuality {
+	airQualityLock.RLock()
+	defer airQualityLock.RUnlock()
+
+	return airQuality
+}
+
+// Score returns the sub-score of an air quality index. Indexes out of the
+// breakpoints take the score of the first or the last breakpoint.
+func (a AirQuality) Score(aqi int) float64 {
+	bps := a.Breakpoints
+	if aqi <= bps[0].AQI {
+		return bps[0].Score
+	}
+
+	for i := 1; i < len(bps); i++ {
+		if aqi <= bps[i].AQI {
+			lower, upper := bps[i-1], bps[i]
+			ratio := float64(aqi-lower.AQI) / float64(upper.AQI-lower.AQI)
+			return lower.Score + ratio*(upper.Score-lower.Score)
+		}
+	}
+
+	return bps[len(bps)-1].Score
+}
+
+// CalculateAirQualityScore updates the air quality sub-score of a metric by the configured breakpoints
+func CalculateAirQualityScore(metric *schema.Metric) {
+	detail := &metric.Details.AirQuality
+	if !detail.Available {
+		detail.Score = 0
+		return
+	}
+
+	detail.Score = GetAirQuality().Score(detail.AQI)
+}
+
+// airQualityWeight returns the weight of the air quality component in a score, which is
+// zero if the component is turned off or the air quality of the metric is not known
+func airQualityWeight(c schema.ScoreCoefficient, metric schema.Metric) float64 {
+	if c.AirQuality <= 0 || !metric.Details.AirQuality.Available {
+		return 0
+	}
+	return c.AirQuality
+}
+
+// airQualityScale returns the factor which the coefficients are rescaled by when the air quality
+// component is added, so that they keep summing up to the sum of the coefficients of the other
+// components and the range of the total score stays the same.
+func airQualityScale(c schema.ScoreCoefficient, metric schema.Metric) float64 {
+	weight := airQualityWeight(c, metric)
+	others := c.Symptoms + c.Behaviors + c.Confirms
+	if weight == 0 || others <= 0 {
+		return 1
+	}
+
+	return others / (others + weight)
+}
+
+// blendAirQuality adds the air quality sub-score into a total score of the other components
+func blendAirQuality(total float64, c schema.ScoreCoefficient, metric schema.Metric) float64 {
+	scale := airQualityScale(c, metric)
+	if scale == 1 {
+		return total
+	}
+
+	return (total + c.AirQuality*metric.Details.AirQuality.Score) * scale
+}
@@ -0,0 +1,104 @@
+package score
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestAirQualityScore(t *testing.T) {
+	testCases := []struct {
+		aqi   int
+		score float64
+	}{
+		{-5, 100},
+		{0, 100},
+		{25, 90},
+		{50, 80},
+		{175, 30},
+		{250, 10},
+		{300, 0},
+		{500, 0},
+	}
+
+	for _, tc := range testCases {
+		assert.InDelta(t, tc.score, DefaultAirQuality.Score(tc.aqi), 0.000001, tc.aqi)
+	}
+}
+
+func TestSetupAirQuality(t *testing.T) {
+	defer SetupAirQuality(DefaultAirQuality)
+
+	err := SetupAirQuality(AirQuality{Coefficient: -1})
+	assert.True(t, errors.Is(err, ErrInvalidAirQuality))
+
+	err = SetupAirQuality(AirQuality{Breakpoints: []AirQualityBreakpoint{{AQI: 0, Score: 100}, {AQI: 0, Score: 50}}})
+	assert.True(t, errors.Is(err, ErrInvalidAirQuality))
+
+	err = SetupAirQuality(AirQuality{Breakpoints: []AirQualityBreakpoint{{AQI: 0, Score: 120}}})
+	assert.True(t, errors.Is(err, ErrInvalidAirQuality))
+
+	assert.NoError(t, SetupAirQuality(AirQuality{Coefficient: 0.2}))
+	assert.Equal(t, 0.2, GetAirQuality().Coefficient)
+	assert.Equal(t, DefaultAirQualityBreakpoints, GetAirQuality().Breakpoints)
+	assert.Equal(t, 0.2, formulaV1{}.DefaultCoefficient().AirQuality)
+}
+
+func TestFormulaV1TotalScoreWithAirQuality(t *testing.T) {
+	coefficient := schema.ScoreCoefficient{
+		Symptoms:   0.25,
+		Behaviors:  0.25,
+		Confirms:   0.5,
+		AirQuality: 1,
+	}
+	metric := schema.Metric{
+		Details: schema.Details{
+			Symptoms:   schema.SymptomDetail{Score: 40},
+			Behaviors:  schema.BehaviorDetail{Score: 60},
+			Confirm:    schema.ConfirmDetail{Score: 50},
+			AirQuality: schema.AirQualityDetail{AQI: 100, Available: true, Score: 80},
+		},
+	}
+
+	// (0.25*40 + 0.25*60 + 0.5*50 + 1*80) / 2
+	assert.InDelta(t, 65, formulaV1{}.TotalScore(&coefficient, metric), 0.000001)
+
+	explanation := formulaV1{}.Explain(metric, &coefficient)
+	assert.Len(t, explanation.Components, 4)
+	assert.Equal(t, "air_quality", explanation.Components[3].Name)
+	assert.InDelta(t, 0.5, explanation.Components[3].Coefficient, 0.000001)
+	total := float64(0)
+	for _, c := range explanation.Components {
+		total += c.Contribution
+	}
+	assert.InDelta(t, 65, total, 0.000001)
+
+	// the air quality is left out if it is not known
+	metric.Details.AirQuality.Available = false
+	assert.InDelta(t, 50, formulaV1{}.TotalScore(&coefficient, metric), 0.000001)
+	assert.Len(t, formulaV1{}.Explain(metric, &coefficient).Components, 3)
+
+	// the air quality is left out if its coefficient is not set
+	metric.Details.AirQuality.Available = true
+	coefficient.AirQuality = 0
+	assert.InDelta(t, 50, formulaV1{}.TotalScore(&coefficient, metric), 0.000001)
+}
+
+func TestFormulaV1CalculateMetricWithAirQuality(t *testing.T) {
+	defer SetupAirQuality(DefaultAirQuality)
+	assert.NoError(t, SetupAirQuality(AirQuality{Coefficient: 1}))
+
+	metric := formulaV1{}.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			AirQuality: schema.AirQualityDetail{AQI: 50, Available: true},
+		},
+	}, nil)
+	assert.Equal(t, float64(80), metric.Details.AirQuality.Score)
+
+	withoutAirQuality := formulaV1{}.CalculateMetric(schema.Metric{}, nil)
+	assert.Equal(t, float64(0), withoutAirQuality.Details.AirQuality.Score)
+	assert.InDelta(t, (withoutAirQuality.Score+80)/2, metric.Score, 0.000001)
+}
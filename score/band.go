@@ -0,0 +1,118 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrInvalidScoreBands = fmt.Errorf("invalid score bands")
+)
+
+// DefaultScoreBands are the colors of scores
+// Red:     0 ~ 33
+// Yellow: 34 ~ 66
+// Green:  67 ~ 100
+var DefaultScoreBands = []schema.ScoreBand{
+	{Name: "red", Lower: 0, Upper: 34},
+	{Name: "yellow", Lower: 34, Upper: 67},
+	{Name: "green", Lower: 67, Upper: 100},
+}
+
+var (
+	bandLock   sync.RWMutex
+	scoreBands = DefaultScoreBands
+)
+
+// SetupScoreBands configures the bands ordered from the lowest scores to the highest.
+// The bands must be named and adjacent to each other. The default bands are used
+// if no band is given.
+func SetupScoreBands(bands []schema.ScoreBand) error {
+	if len(bands) == 0 {
+		bands = DefaultScoreBands
+	}
+
+	names := make(map[string]struct{})
+	for i, b := range bands {
+		if b.Name == "" {
+			return fmt.Errorf("%w: band %d has no name", ErrInvalidScoreBands, i)
+		}
+		if _, ok := names[b.Name]; ok {
+			return fmt.Errorf("%w: duplicated band %s", ErrInvalidScoreBands, b.Name)
+		}
+		names[b.Name] = struct{}{}
+
+		if b.Lower >= b.Upper {
+			return fmt.Errorf("%w: empty band %s", ErrInvalidScoreBands, b.Name)
+		}
+		if i > 0 && bands[i-1].Upper != b.Lower {
+			return fmt.Errorf("%w: band %s is not adjacent to band %s", ErrInvalidScoreBands, b.Name, bands[i-1].Name)
+		}
+	}
+
+	bandLock.Lock()
+	defer bandLock.Unlock()
+
+	scoreBands = append([]schema.ScoreBand{}, bands...)
+	return nil
+}
+
+// ScoreBands returns the configured bands ordered from the lowest scores to the highest
+func ScoreBands() []schema.ScoreBand {
+	bandLock.RLock()
+	defer bandLock.RUnlock()
+
+	return append([]schema.ScoreBand{}, scoreBands...)
+}
+
+// scoreBandIndex returns the index of the band a score falls in. Scores out of
+// the bands fall in the lowest or the highest band.
+func scoreBandIndex(bands []schema.ScoreBand, score float64) int {
+	if score < bands[0].Lower {
+		return 0
+	}
+
+	for i, b := range bands {
+		if score < b.Upper {
+			return i
+		}
+	}
+
+	return len(bands) - 1
+}
+
+// GetScoreBand returns the band a score falls in
+func GetScoreBand(score float64) schema.ScoreBand {
+	bands := ScoreBands()
+	return bands[scoreBandIndex(bands, score)]
+}
+
+// IsTopScoreBand returns if a band is the one of the highest scores
+func IsTopScoreBand(band schema.ScoreBand) bool {
+	bands := ScoreBands()
+	return bands[len(bands)-1].Name == band.Name
+}
+
+// CheckScoreBandTransition returns the transition if a score moves to another band
+func CheckScoreBandTransition(oldScore, newScore float64) (schema.ScoreBandTransition, bool) {
+	bands := ScoreBands()
+	oldIndex := scoreBandIndex(bands, oldScore)
+	newIndex := scoreBandIndex(bands, newScore)
+
+	if oldIndex == newIndex {
+		return schema.ScoreBandTransition{}, false
+	}
+
+	direction := schema.ScoreBandImproved
+	if newIndex < oldIndex {
+		direction = schema.ScoreBandWorsened
+	}
+
+	return schema.ScoreBandTransition{
+		Old:       bands[oldIndex],
+		New:       bands[newIndex],
+		Direction: direction,
+	}, true
+}
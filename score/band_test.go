@@ -0,0 +1,81 @@
+package score
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestGetScoreBand(t *testing.T) {
+	testCases := []struct {
+		score float64
+		band  string
+	}{
+		{-1, "red"},
+		{0, "red"},
+		{33.9, "red"},
+		{34, "yellow"},
+		{66.5, "yellow"},
+		{67, "green"},
+		{100, "green"},
+		{101, "green"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.band, GetScoreBand(tc.score).Name, tc.score)
+	}
+}
+
+func TestCheckScoreBandTransition(t *testing.T) {
+	transition, changed := CheckScoreBandTransition(20, 80)
+	assert.True(t, changed)
+	assert.Equal(t, "red", transition.Old.Name)
+	assert.Equal(t, "green", transition.New.Name)
+	assert.Equal(t, schema.ScoreBandImproved, transition.Direction)
+
+	transition, changed = CheckScoreBandTransition(67, 66)
+	assert.True(t, changed)
+	assert.Equal(t, "green", transition.Old.Name)
+	assert.Equal(t, "yellow", transition.New.Name)
+	assert.Equal(t, schema.ScoreBandWorsened, transition.Direction)
+
+	_, changed = CheckScoreBandTransition(40, 60)
+	assert.False(t, changed)
+}
+
+func TestSetupScoreBands(t *testing.T) {
+	defer SetupScoreBands(nil)
+
+	err := SetupScoreBands([]schema.ScoreBand{
+		{Name: "bad", Lower: 0, Upper: 50},
+		{Name: "good", Lower: 60, Upper: 100},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidScoreBands))
+
+	err = SetupScoreBands([]schema.ScoreBand{
+		{Name: "bad", Lower: 0, Upper: 50},
+		{Name: "bad", Lower: 50, Upper: 100},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidScoreBands))
+
+	err = SetupScoreBands([]schema.ScoreBand{
+		{Name: "empty", Lower: 50, Upper: 50},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidScoreBands))
+
+	assert.NoError(t, SetupScoreBands([]schema.ScoreBand{
+		{Name: "bad", Lower: 0, Upper: 50},
+		{Name: "good", Lower: 50, Upper: 100},
+	}))
+	assert.Equal(t, "bad", GetScoreBand(49).Name)
+	assert.Equal(t, "good", GetScoreBand(50).Name)
+	assert.True(t, IsTopScoreBand(GetScoreBand(50)))
+	assert.True(t, CheckScoreColorChange(49, 50))
+	assert.False(t, CheckScoreColorChange(33, 34))
+
+	assert.NoError(t, SetupScoreBands(nil))
+	assert.Equal(t, DefaultScoreBands, ScoreBands())
+}
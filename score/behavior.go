@@ -6,34 +6,98 @@ import (
 	"github.com/bitmark-inc/autonomy-api/schema"
 )
 
-func UpdateBehaviorMetrics(metric *schema.Metric) {
-	todayTotal := 0
-	yesterdayTotal := 0
+// MergeBehaviorWeights applies customized weights over the default behavior weights,
+// so that official behaviors left out of customized weights keep their default weights.
+func MergeBehaviorWeights(weights schema.BehaviorWeights) schema.BehaviorWeights {
+	merged := make(schema.BehaviorWeights, len(schema.DefaultBehaviorWeights))
+	for behaviorID, w := range schema.DefaultBehaviorWeights {
+		merged[behaviorID] = w
+	}
+	for behaviorID, w := range weights {
+		merged[behaviorID] = w
+	}
+	return merged
+}
+
+// behaviorWeighing is how the behaviors reported today weigh in the behavior score
+type behaviorWeighing struct {
+	contributions  []schema.BehaviorContribution
+	weightedSum    float64
+	maxWeightedSum float64
+	todayTotal     float64
+}
+
+// weighBehaviors weighs the behaviors reported today. A non-official behavior weighs 1, and the
+// weighted sum of non-official behaviors is capped to half of the max weighted sum. The contribution
+// of a behavior is the points it adds to the behavior score.
+func weighBehaviors(rawData schema.BehaviorDetail, weights schema.BehaviorWeights) behaviorWeighing {
+	weights = MergeBehaviorWeights(weights)
+
+	totalWeight := float64(0)
+	for _, w := range weights {
+		totalWeight += w
+	}
+
+	var w behaviorWeighing
+	w.contributions = make([]schema.BehaviorContribution, 0, len(rawData.TodayDistribution))
 	officialWeightedSum := float64(0)
 	nonOfficialWeightedSum := float64(0)
-
-	for behaviorID, cnt := range metric.Details.Behaviors.TodayDistribution {
-		w, ok := schema.DefaultBehaviorWeightMatrix[schema.GoodBehaviorType(behaviorID)]
+	for behaviorID, cnt := range rawData.TodayDistribution {
+		weight, ok := weights[behaviorID]
 		if ok {
-			officialWeightedSum += w.Weight * float64(cnt)
+			officialWeightedSum += weight * cnt
 		} else {
-			nonOfficialWeightedSum += float64(cnt)
+			weight = 1
+			nonOfficialWeightedSum += cnt
+		}
+
+		w.todayTotal += cnt
+		w.contributions = append(w.contributions, schema.BehaviorContribution{
+			ID:       behaviorID,
+			Count:    cnt,
+			Weight:   weight,
+			Official: ok,
+		})
+	}
+
+	w.maxWeightedSum = rawData.ReportTimes*totalWeight + nonOfficialWeightedSum
+	// cap weighted sum of non-official behaviors
+	cappedNonOfficialWeightedSum := math.Min(nonOfficialWeightedSum, w.maxWeightedSum/2)
+	w.weightedSum = officialWeightedSum + cappedNonOfficialWeightedSum
+
+	if w.maxWeightedSum > 0 {
+		// non-official behaviors share the capped weighted sum
+		nonOfficialRatio := float64(1)
+		if nonOfficialWeightedSum > 0 {
+			nonOfficialRatio = cappedNonOfficialWeightedSum / nonOfficialWeightedSum
 		}
 
-		todayTotal += cnt
+		for i, c := range w.contributions {
+			contribution := 100 * c.Count * c.Weight / w.maxWeightedSum
+			if !c.Official {
+				contribution *= nonOfficialRatio
+			}
+			w.contributions[i].Contribution = contribution
+		}
 	}
+
+	return w
+}
+
+// UpdateBehaviorMetrics calculates the behavior score with given behavior weights.
+// Customized weights are applied over the default behavior weights.
+func UpdateBehaviorMetrics(metric *schema.Metric, weights schema.BehaviorWeights) {
+	weighing := weighBehaviors(metric.Details.Behaviors, weights)
+
+	yesterdayTotal := float64(0)
 	for _, cnt := range metric.Details.Behaviors.YesterdayDistribution {
 		yesterdayTotal += cnt
 	}
 
-	maxWeightedSum := float64(metric.Details.Behaviors.ReportTimes)*schema.TotalOfficialBehaviorWeight + nonOfficialWeightedSum
-	// cap weighted sum of non-official behaviors
-	nonOfficialWeightedSum = math.Min(nonOfficialWeightedSum, maxWeightedSum/2)
-	weightedSum := officialWeightedSum + nonOfficialWeightedSum
-	if maxWeightedSum > 0 {
-		metric.Details.Behaviors.Score = 100 * weightedSum / maxWeightedSum
+	if weighing.maxWeightedSum > 0 {
+		metric.Details.Behaviors.Score = 100 * weighing.weightedSum / weighing.maxWeightedSum
 	}
 
-	metric.BehaviorCount = float64(todayTotal)
-	metric.BehaviorDelta = ChangeRate(float64(todayTotal), float64(yesterdayTotal))
+	metric.BehaviorCount = weighing.todayTotal
+	metric.BehaviorDelta = ChangeRate(weighing.todayTotal, yesterdayTotal)
 }
@@ -14,7 +14,7 @@ func TestUpdateBehaviorMetrics(t *testing.T) {
 		Details: schema.Details{
 			Behaviors: schema.BehaviorDetail{
 				ReportTimes: 50,
-				TodayDistribution: map[string]int{
+				TodayDistribution: map[string]float64{
 					"clean_hand":        20,
 					"social_distancing": 10,
 					"touch_face":        10,
@@ -24,13 +24,13 @@ func TestUpdateBehaviorMetrics(t *testing.T) {
 					"new_behavior_1":    5,
 					"new_behavior_2":    5,
 				},
-				YesterdayDistribution: map[string]int{
+				YesterdayDistribution: map[string]float64{
 					"clean_hand": 20,
 				},
 			},
 		},
 	}
-	UpdateBehaviorMetrics(metric)
+	UpdateBehaviorMetrics(metric, nil)
 	assert.Equal(t, "25.81", fmt.Sprintf("%.2f", metric.Details.Behaviors.Score))
 	assert.Equal(t, 80.0, metric.BehaviorCount)
 	assert.Equal(t, 300.0, metric.BehaviorDelta)
@@ -41,14 +41,14 @@ func TestCalculateBehaviorScoreNoReportToday(t *testing.T) {
 		Details: schema.Details{
 			Behaviors: schema.BehaviorDetail{
 				ReportTimes:       0,
-				TodayDistribution: map[string]int{},
-				YesterdayDistribution: map[string]int{
+				TodayDistribution: map[string]float64{},
+				YesterdayDistribution: map[string]float64{
 					"clean_hand": 20,
 				},
 			},
 		},
 	}
-	UpdateBehaviorMetrics(metric)
+	UpdateBehaviorMetrics(metric, nil)
 	assert.Equal(t, 0.0, metric.Details.Behaviors.Score)
 	assert.Equal(t, 0.0, metric.BehaviorCount)
 	assert.Equal(t, -100.0, metric.BehaviorDelta)
@@ -58,13 +58,13 @@ func TestCalculateBehaviorScoreNoReportToday(t *testing.T) {
 			Behaviors: schema.BehaviorDetail{
 				ReportTimes:       100,
 				TodayDistribution: nil,
-				YesterdayDistribution: map[string]int{
+				YesterdayDistribution: map[string]float64{
 					"clean_hand": 20,
 				},
 			},
 		},
 	}
-	UpdateBehaviorMetrics(metric)
+	UpdateBehaviorMetrics(metric, nil)
 	assert.Equal(t, 0.0, metric.Details.Behaviors.Score)
 	assert.Equal(t, 0.0, metric.BehaviorCount)
 	assert.Equal(t, -100.0, metric.BehaviorDelta)
@@ -75,20 +75,75 @@ func TestCalculateBehaviorScoreSignificantNonOfficialBehaviors(t *testing.T) {
 		Details: schema.Details{
 			Behaviors: schema.BehaviorDetail{
 				ReportTimes: 10,
-				TodayDistribution: map[string]int{
+				TodayDistribution: map[string]float64{
 					"clean_hand":     5,
 					"new_behavior_1": 30,
 					"new_behavior_2": 20,
 					"new_behavior_3": 20,
 				},
-				YesterdayDistribution: map[string]int{
+				YesterdayDistribution: map[string]float64{
 					"clean_hand": 20,
 				},
 			},
 		},
 	}
-	UpdateBehaviorMetrics(metric)
+	UpdateBehaviorMetrics(metric, nil)
 	assert.Equal(t, "53.85", fmt.Sprintf("%.2f", metric.Details.Behaviors.Score))
 	assert.Equal(t, 75.0, metric.BehaviorCount)
 	assert.Equal(t, 275.0, metric.BehaviorDelta)
 }
+
+func TestCalculateBehaviorScoreCustomizedWeights(t *testing.T) {
+	metric := &schema.Metric{
+		Details: schema.Details{
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes: 10,
+				TodayDistribution: map[string]float64{
+					"clean_hand":        5,
+					"social_distancing": 10,
+				},
+			},
+		},
+	}
+	UpdateBehaviorMetrics(metric, schema.BehaviorWeights{
+		"clean_hand":        2,
+		"social_distancing": 0,
+		"touch_face":        0,
+		"wear_mask":         0,
+		"covering_coughs":   0,
+		"clean_surface":     0,
+	})
+	assert.Equal(t, 50.0, metric.Details.Behaviors.Score)
+	assert.Equal(t, 15.0, metric.BehaviorCount)
+}
+
+func TestUpdateBehaviorMetricsPartialWeights(t *testing.T) {
+	newMetric := func() *schema.Metric {
+		return &schema.Metric{
+			Details: schema.Details{
+				Behaviors: schema.BehaviorDetail{
+					ReportTimes: 10,
+					TodayDistribution: map[string]float64{
+						"clean_hand": 10,
+						"wear_mask":  5,
+					},
+				},
+			},
+		}
+	}
+
+	partial := newMetric()
+	UpdateBehaviorMetrics(partial, schema.BehaviorWeights{"clean_hand": 3})
+
+	full := newMetric()
+	weights := schema.BehaviorWeights{}
+	for behaviorID, w := range schema.DefaultBehaviorWeights {
+		weights[behaviorID] = w
+	}
+	weights["clean_hand"] = 3
+	UpdateBehaviorMetrics(full, weights)
+
+	// official behaviors left out of the weights keep their default weights
+	assert.Equal(t, full.Details.Behaviors.Score, partial.Details.Behaviors.Score)
+	assert.Equal(t, "43.75", fmt.Sprintf("%.2f", partial.Details.Behaviors.Score))
+}
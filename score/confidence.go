@@ -0,0 +1,98 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrInvalidConfidenceRule = fmt.Errorf("invalid confidence rule")
+)
+
+// ConfidenceRule grades the confidence of a metric. The confidence is high if at least
+// HighReporters users have reported in the area, medium if at least MediumReporters
+// users have, and low otherwise. The confidence is lowered by one level if there is no
+// confirmed case data or the data is older than MaxConfirmDataAge days.
+type ConfidenceRule struct {
+	MediumReporters   int `mapstructure:"medium_reporters"`
+	HighReporters     int `mapstructure:"high_reporters"`
+	MaxConfirmDataAge int `mapstructure:"max_confirm_data_age"`
+}
+
+var DefaultConfidenceRule = ConfidenceRule{
+	MediumReporters:   5,
+	HighReporters:     20,
+	MaxConfirmDataAge: 3,
+}
+
+var (
+	confidenceRuleLock sync.RWMutex
+	confidenceRule     = DefaultConfidenceRule
+)
+
+var confidenceLevels = []schema.ConfidenceLevel{
+	schema.ConfidenceLow,
+	schema.ConfidenceMedium,
+	schema.ConfidenceHigh,
+}
+
+// SetupConfidenceRule configures the confidence rule. Fields not given use the default values.
+func SetupConfidenceRule(r ConfidenceRule) error {
+	if r.MediumReporters == 0 {
+		r.MediumReporters = DefaultConfidenceRule.MediumReporters
+	}
+	if r.HighReporters == 0 {
+		r.HighReporters = DefaultConfidenceRule.HighReporters
+	}
+	if r.MaxConfirmDataAge == 0 {
+		r.MaxConfirmDataAge = DefaultConfidenceRule.MaxConfirmDataAge
+	}
+
+	if r.MediumReporters < 0 || r.HighReporters < r.MediumReporters || r.MaxConfirmDataAge < 0 {
+		return fmt.Errorf("%w: %+v", ErrInvalidConfidenceRule, r)
+	}
+
+	confidenceRuleLock.Lock()
+	defer confidenceRuleLock.Unlock()
+
+	confidenceRule = r
+	return nil
+}
+
+// GetConfidenceRule returns the configured confidence rule
+func GetConfidenceRule() ConfidenceRule {
+	confidenceRuleLock.RLock()
+	defer confidenceRuleLock.RUnlock()
+
+	return confidenceRule
+}
+
+// Level grades a confidence by its reporters and confirmed case data
+func (r ConfidenceRule) Level(c schema.Confidence) schema.ConfidenceLevel {
+	level := 0
+	switch {
+	case c.Reporters >= r.HighReporters:
+		level = 2
+	case c.Reporters >= r.MediumReporters:
+		level = 1
+	}
+
+	if (c.ConfirmDataAge < 0 || c.ConfirmDataAge > r.MaxConfirmDataAge) && level > 0 {
+		level--
+	}
+
+	return confidenceLevels[level]
+}
+
+// UpdateConfidence grades the confidence of a metric by the configured confidence rule
+func UpdateConfidence(metric *schema.Metric) {
+	metric.Confidence.Level = GetConfidenceRule().Level(metric.Confidence)
+}
+
+// IsLowConfidence tells if a metric is calculated from too little data to stand behind.
+// Metrics calculated before the confidence is graded are not seen as low confidence.
+func IsLowConfidence(metric schema.Metric) bool {
+	return metric.Confidence.Level == schema.ConfidenceLow
+}
@@ -0,0 +1,66 @@
+package score
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestSetupConfidenceRule(t *testing.T) {
+	defer SetupConfidenceRule(DefaultConfidenceRule)
+
+	assert.NoError(t, SetupConfidenceRule(ConfidenceRule{}))
+	assert.Equal(t, DefaultConfidenceRule, GetConfidenceRule())
+
+	assert.NoError(t, SetupConfidenceRule(ConfidenceRule{HighReporters: 50}))
+	assert.Equal(t, ConfidenceRule{MediumReporters: 5, HighReporters: 50, MaxConfirmDataAge: 3}, GetConfidenceRule())
+
+	assert.Error(t, SetupConfidenceRule(ConfidenceRule{MediumReporters: 30, HighReporters: 10}))
+	assert.Error(t, SetupConfidenceRule(ConfidenceRule{MaxConfirmDataAge: -1}))
+	assert.Equal(t, 50, GetConfidenceRule().HighReporters)
+}
+
+func TestConfidenceRuleLevel(t *testing.T) {
+	r := DefaultConfidenceRule
+
+	testCases := []struct {
+		confidence schema.Confidence
+		level      schema.ConfidenceLevel
+	}{
+		{schema.Confidence{Reporters: 0, ConfirmDataAge: 0}, schema.ConfidenceLow},
+		{schema.Confidence{Reporters: 4, ConfirmDataAge: 0}, schema.ConfidenceLow},
+		{schema.Confidence{Reporters: 5, ConfirmDataAge: 0}, schema.ConfidenceMedium},
+		{schema.Confidence{Reporters: 20, ConfirmDataAge: 3}, schema.ConfidenceHigh},
+		{schema.Confidence{Reporters: 20, ConfirmDataAge: 4}, schema.ConfidenceMedium},
+		{schema.Confidence{Reporters: 20, ConfirmDataAge: -1}, schema.ConfidenceMedium},
+		{schema.Confidence{Reporters: 5, ConfirmDataAge: -1}, schema.ConfidenceLow},
+		{schema.Confidence{Reporters: 0, ConfirmDataAge: -1}, schema.ConfidenceLow},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.level, r.Level(tc.confidence), "%+v", tc.confidence)
+	}
+}
+
+func TestFormulaV1CalculateMetricConfidence(t *testing.T) {
+	metric := formulaV1{}.CalculateMetric(schema.Metric{
+		Radius:     1000,
+		Confidence: schema.Confidence{Reporters: 30, Reports: 80, ConfirmDataAge: 1},
+	}, nil)
+	assert.Equal(t, 1000, metric.Radius)
+	assert.Equal(t, schema.Confidence{
+		Reporters:      30,
+		Reports:        80,
+		ConfirmDataAge: 1,
+		Level:          schema.ConfidenceHigh,
+	}, metric.Confidence)
+	assert.False(t, IsLowConfidence(metric))
+
+	metric = formulaV1{}.CalculateMetric(schema.Metric{}, nil)
+	assert.Equal(t, schema.ConfidenceLow, metric.Confidence.Level)
+	assert.True(t, IsLowConfidence(metric))
+
+	assert.False(t, IsLowConfidence(schema.Metric{}))
+}
@@ -1,12 +1,74 @@
 package score
 
 import (
+	"fmt"
 	"math"
+	"sync"
 
 	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
 )
 
+const (
+	// ConfirmModeRaw scores confirmed cases by the number of cases
+	ConfirmModeRaw = "raw"
+	// ConfirmModePer100K scores confirmed cases by the number of cases per 100k people
+	ConfirmModePer100K = "per_100k"
+)
+
+var (
+	ErrUnknownConfirmMode = fmt.Errorf("unknown confirm mode")
+)
+
+var (
+	confirmModeLock sync.RWMutex
+	confirmMode     = ConfirmModeRaw
+)
+
+// SetupConfirmMode configures how confirmed cases are scored. The raw mode is used if no mode is given.
+func SetupConfirmMode(mode string) error {
+	switch mode {
+	case "":
+		mode = ConfirmModeRaw
+	case ConfirmModeRaw, ConfirmModePer100K:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownConfirmMode, mode)
+	}
+
+	confirmModeLock.Lock()
+	defer confirmModeLock.Unlock()
+
+	confirmMode = mode
+	return nil
+}
+
+// GetConfirmMode returns the configured confirm mode
+func GetConfirmMode() string {
+	confirmModeLock.RLock()
+	defer confirmModeLock.RUnlock()
+
+	return confirmMode
+}
+
+// confirmScoreMode returns the mode which confirmed cases of an area are scored by.
+// Cases are scored by the raw mode if the population of the area is unknown.
+func confirmScoreMode(population float64) string {
+	mode := GetConfirmMode()
+	if mode == ConfirmModePer100K && population <= 0 {
+		return ConfirmModeRaw
+	}
+	return mode
+}
+
+// confirmCasesScale returns the ratio to scale confirmed cases by the confirm mode.
+// Cases are not scaled if the population is unknown.
+func confirmCasesScale(population float64) float64 {
+	if confirmScoreMode(population) == ConfirmModePer100K {
+		return 100000 / population
+	}
+	return 1
+}
+
 func CalculateConfirmScore(metric *schema.Metric) {
 	details := &metric.Details.Confirm
 	dataset := details.ContinuousData
@@ -22,12 +84,14 @@ func CalculateConfirmScore(metric *schema.Metric) {
 		}
 		details.ContinuousData = dataset
 	}
+	scale := confirmCasesScale(details.Population)
 	numerator := float64(0)
 	denominator := float64(0)
 	for idx, val := range dataset {
-		power := (float64(idx) + 1) / 2
-		numerator = numerator + math.Exp(power)*val.Cases
-		denominator = denominator + math.Exp(power)*(val.Cases+1)
+		weight := confirmDayWeight(idx)
+		cases := val.Cases * scale
+		numerator = numerator + weight*cases
+		denominator = denominator + weight*(cases+1)
 	}
 
 	if denominator > 0 {
@@ -35,3 +99,10 @@ func CalculateConfirmScore(metric *schema.Metric) {
 	}
 	metric.Details.Confirm.Score = score * 100
 }
+
+// confirmDayWeight returns the exponential weight of the idx-th day in the confirm
+// score window. The latest day has the largest weight.
+func confirmDayWeight(idx int) float64 {
+	power := (float64(idx) + 1) / 2
+	return math.Exp(power)
+}
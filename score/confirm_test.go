@@ -0,0 +1,78 @@
+package score
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func confirmMetric(population float64, cases ...float64) *schema.Metric {
+	data := make([]schema.CDSScoreDataSet, 0)
+	for _, c := range cases {
+		data = append(data, schema.CDSScoreDataSet{Name: "test", Cases: c})
+	}
+	return &schema.Metric{
+		Details: schema.Details{
+			Confirm: schema.ConfirmDetail{
+				ContinuousData: data,
+				Population:     population,
+			},
+		},
+	}
+}
+
+func TestCalculateConfirmScoreRawMode(t *testing.T) {
+	small := confirmMetric(10000, 10, 10, 10)
+	large := confirmMetric(10000000, 10, 10, 10)
+	CalculateConfirmScore(small)
+	CalculateConfirmScore(large)
+	assert.Equal(t, small.Details.Confirm.Score, large.Details.Confirm.Score)
+
+	empty := confirmMetric(10000)
+	CalculateConfirmScore(empty)
+	assert.Equal(t, 0.0, empty.Details.Confirm.Score)
+}
+
+func TestCalculateConfirmScorePer100KMode(t *testing.T) {
+	assert.NoError(t, SetupConfirmMode(ConfirmModePer100K))
+	defer SetupConfirmMode("")
+
+	testCases := []struct {
+		name       string
+		population float64
+		cases      float64
+	}{
+		{"small county", 10000, 10},
+		{"large county", 10000000, 10},
+		{"unknown population", 0, 10},
+	}
+
+	scores := make(map[string]float64)
+	for _, tc := range testCases {
+		metric := confirmMetric(tc.population, tc.cases, tc.cases, tc.cases)
+		CalculateConfirmScore(metric)
+		scores[tc.name] = metric.Details.Confirm.Score
+	}
+
+	// 10 cases in 10k people are 100 cases per 100k people
+	assert.Less(t, scores["small county"], scores["large county"])
+
+	// cases are not scaled without population
+	raw := confirmMetric(0, 10, 10, 10)
+	assert.NoError(t, SetupConfirmMode(ConfirmModeRaw))
+	CalculateConfirmScore(raw)
+	assert.Equal(t, raw.Details.Confirm.Score, scores["unknown population"])
+}
+
+func TestSetupConfirmMode(t *testing.T) {
+	defer SetupConfirmMode("")
+
+	assert.True(t, errors.Is(SetupConfirmMode("per_person"), ErrUnknownConfirmMode))
+	assert.NoError(t, SetupConfirmMode(ConfirmModePer100K))
+	assert.Equal(t, ConfirmModePer100K, GetConfirmMode())
+	assert.NoError(t, SetupConfirmMode(""))
+	assert.Equal(t, ConfirmModeRaw, GetConfirmMode())
+}
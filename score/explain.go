@@ -0,0 +1,88 @@
+package score
+
+import (
+	"sort"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+const (
+	explainTopSymptomsSize = 5
+)
+
+// explainComponent returns how much a sub-score contributes to the total score
+func explainComponent(name string, coefficient, score float64) schema.ScoreComponentExplanation {
+	return schema.ScoreComponentExplanation{
+		Name:         name,
+		Coefficient:  coefficient,
+		Score:        score,
+		Contribution: coefficient * score,
+	}
+}
+
+// explainSymptoms lists the reported symptoms which take the most points off the symptom score.
+// The symptoms are weighed as the symptom score is calculated.
+func explainSymptoms(metric schema.Metric, weights schema.SymptomWeights) schema.SymptomExplanation {
+	contributions := weighSymptoms(metric.Details.Symptoms, weights).contributions
+
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Penalty != contributions[j].Penalty {
+			return contributions[i].Penalty > contributions[j].Penalty
+		}
+		return contributions[i].ID < contributions[j].ID
+	})
+
+	if len(contributions) > explainTopSymptomsSize {
+		contributions = contributions[:explainTopSymptomsSize]
+	}
+
+	return schema.SymptomExplanation{
+		TotalPeople: metric.Details.Symptoms.TotalPeople,
+		TopSymptoms: contributions,
+	}
+}
+
+// explainBehaviors lists the reported behaviors counted in the behavior score.
+// The behaviors are weighed as the behavior score is calculated.
+func explainBehaviors(metric schema.Metric, weights schema.BehaviorWeights) schema.BehaviorExplanation {
+	contributions := weighBehaviors(metric.Details.Behaviors, weights).contributions
+
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Contribution != contributions[j].Contribution {
+			return contributions[i].Contribution > contributions[j].Contribution
+		}
+		return contributions[i].ID < contributions[j].ID
+	})
+
+	return schema.BehaviorExplanation{
+		ReportTimes: metric.Details.Behaviors.ReportTimes,
+		Behaviors:   contributions,
+	}
+}
+
+// explainConfirm lists the confirmed cases in the confirm score window with their weights
+func explainConfirm(metric schema.Metric) schema.ConfirmExplanation {
+	dataset := metric.Details.Confirm.ContinuousData
+	scale := confirmCasesScale(metric.Details.Confirm.Population)
+
+	var population *float64
+	if p := metric.Details.Confirm.Population; p > 0 {
+		population = &p
+	}
+
+	window := make([]schema.ConfirmDayExplanation, 0, len(dataset))
+	for idx, val := range dataset {
+		window = append(window, schema.ConfirmDayExplanation{
+			DaysAgo:     len(dataset) - 1 - idx,
+			Cases:       val.Cases,
+			ScoredCases: val.Cases * scale,
+			Weight:      confirmDayWeight(idx),
+		})
+	}
+
+	return schema.ConfirmExplanation{
+		Mode:       confirmScoreMode(metric.Details.Confirm.Population),
+		Population: population,
+		Window:     window,
+	}
+}
@@ -0,0 +1,165 @@
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/consts"
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestFormulaV1Explain(t *testing.T) {
+	f := formulaV1{}
+	metric := f.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: 10,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: schema.SymptomDistribution{
+						"cough":       3,
+						"fever":       7,
+						"new-symptom": 1,
+					},
+				},
+			},
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes: 10,
+				TodayDistribution: map[string]float64{
+					"clean_hand":     5,
+					"new_behavior_1": 30,
+				},
+			},
+			Confirm: schema.ConfirmDetail{
+				ContinuousData: []schema.CDSScoreDataSet{
+					{Name: "test", Cases: 10},
+					{Name: "test", Cases: 20},
+				},
+			},
+		},
+	}, nil)
+
+	explanation := f.Explain(metric, nil)
+	assert.Equal(t, FormulaV1, explanation.FormulaVersion)
+	assert.Equal(t, metric.Score, explanation.Score)
+
+	totalContribution := float64(0)
+	for _, c := range explanation.Components {
+		totalContribution += c.Contribution
+	}
+	assert.Equal(t, fmt.Sprintf("%.6f", metric.Score), fmt.Sprintf("%.6f", totalContribution))
+
+	// the symptom penalty is explained by the top symptoms
+	assert.Len(t, explanation.Symptoms.TopSymptoms, 3)
+	assert.Equal(t, "fever", explanation.Symptoms.TopSymptoms[0].ID)
+	totalPenalty := float64(0)
+	for _, s := range explanation.Symptoms.TopSymptoms {
+		totalPenalty += s.Penalty
+	}
+	assert.Equal(t, fmt.Sprintf("%.6f", metric.Details.Symptoms.Score), fmt.Sprintf("%.6f", 100-totalPenalty))
+
+	// the non-official behaviors are capped
+	assert.Len(t, explanation.Behaviors.Behaviors, 2)
+	totalBehaviorContribution := float64(0)
+	for _, b := range explanation.Behaviors.Behaviors {
+		totalBehaviorContribution += b.Contribution
+	}
+	assert.Equal(t, fmt.Sprintf("%.6f", metric.Details.Behaviors.Score), fmt.Sprintf("%.6f", totalBehaviorContribution))
+
+	// the confirm window is padded and the latest day weighs the most
+	window := explanation.Confirm.Window
+	assert.Len(t, window, consts.ConfirmScoreWindowSize)
+	assert.Equal(t, 0, window[len(window)-1].DaysAgo)
+	assert.Equal(t, 20.0, window[len(window)-1].Cases)
+	for i := 1; i < len(window); i++ {
+		assert.Greater(t, window[i].Weight, window[i-1].Weight)
+	}
+}
+
+func TestFormulaV1ExplainTopSymptomsLimit(t *testing.T) {
+	distribution := schema.SymptomDistribution{}
+	for i := 0; i < explainTopSymptomsSize+2; i++ {
+		distribution[fmt.Sprintf("symptom-%d", i)] = float64(i + 1)
+	}
+
+	f := formulaV1{}
+	metric := f.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: 10,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: distribution,
+				},
+			},
+		},
+	}, nil)
+
+	explanation := f.Explain(metric, nil)
+	assert.Len(t, explanation.Symptoms.TopSymptoms, explainTopSymptomsSize)
+	assert.Equal(t, fmt.Sprintf("symptom-%d", explainTopSymptomsSize+1), explanation.Symptoms.TopSymptoms[0].ID)
+}
+
+func TestFormulaV1ExplainCustomizedWeights(t *testing.T) {
+	f := formulaV1{}
+	coefficient := f.DefaultCoefficient()
+	coefficient.SymptomWeights = schema.SymptomWeights{"cough": 2}
+	coefficient.BehaviorWeights = schema.BehaviorWeights{"clean_hand": 3}
+
+	metric := f.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: 10,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: schema.SymptomDistribution{
+						"cough": 3,
+						"fever": 7,
+					},
+				},
+			},
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes: 10,
+				TodayDistribution: map[string]float64{
+					"clean_hand":        5,
+					"social_distancing": 4,
+				},
+			},
+		},
+	}, &coefficient)
+
+	// the breakdown is weighed with the same weights as the score
+	explanation := f.Explain(metric, &coefficient)
+	totalPenalty := float64(0)
+	for _, s := range explanation.Symptoms.TopSymptoms {
+		totalPenalty += s.Penalty
+	}
+	assert.Equal(t, fmt.Sprintf("%.6f", metric.Details.Symptoms.Score), fmt.Sprintf("%.6f", 100-totalPenalty))
+
+	totalBehaviorContribution := float64(0)
+	for _, b := range explanation.Behaviors.Behaviors {
+		assert.True(t, b.Official)
+		totalBehaviorContribution += b.Contribution
+	}
+	assert.Equal(t, fmt.Sprintf("%.6f", metric.Details.Behaviors.Score), fmt.Sprintf("%.6f", totalBehaviorContribution))
+}
+
+func TestExplainConfirmPer100KMode(t *testing.T) {
+	assert.NoError(t, SetupConfirmMode(ConfirmModePer100K))
+	defer SetupConfirmMode("")
+
+	explanation := explainConfirm(*confirmMetric(10000, 10))
+	assert.Equal(t, ConfirmModePer100K, explanation.Mode)
+	assert.Equal(t, 10000.0, *explanation.Population)
+	assert.Equal(t, 100.0, explanation.Window[0].ScoredCases)
+
+	// cases are scored by the raw mode and the population is absent if it is unknown
+	explanation = explainConfirm(*confirmMetric(0, 10))
+	assert.Equal(t, ConfirmModeRaw, explanation.Mode)
+	assert.Nil(t, explanation.Population)
+	assert.Equal(t, 10.0, explanation.Window[0].ScoredCases)
+
+	data, err := json.Marshal(explanation)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "population")
+}
@@ -0,0 +1,307 @@
+package score
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/autonomy-api/consts"
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+const (
+	ForecastModelHolt  = "holt"
+	ForecastModelNaive = "naive"
+)
+
+var (
+	ErrInvalidForecast        = fmt.Errorf("invalid forecast")
+	ErrUnknownForecastModel   = fmt.Errorf("unknown forecast model")
+	ErrForecastModelDuplicate = fmt.Errorf("forecast model already registered")
+)
+
+// Forecast configures how metrics are projected. Horizon is the number of days projected, and
+// Interval is the probability which the prediction intervals cover. Alpha and Beta are the
+// smoothing factors of the level and the trend of the models which smooth the series.
+type Forecast struct {
+	Model    string  `mapstructure:"model"`
+	Horizon  int     `mapstructure:"horizon"`
+	Interval float64 `mapstructure:"interval"`
+	Alpha    float64 `mapstructure:"alpha"`
+	Beta     float64 `mapstructure:"beta"`
+}
+
+var DefaultForecast = Forecast{
+	Model:    ForecastModelHolt,
+	Horizon:  7,
+	Interval: 0.8,
+	Alpha:    0.5,
+	Beta:     0.3,
+}
+
+// ForecastModel projects a daily series
+type ForecastModel interface {
+	// Forecast projects the values of the days after a series, along with
+	// the standard errors of the projections
+	Forecast(series []float64, horizon int) (values []float64, errors []float64)
+}
+
+// ForecastModelFactory creates a model by the forecast configuration
+type ForecastModelFactory func(f Forecast) ForecastModel
+
+var (
+	forecastLock sync.RWMutex
+
+	forecastModels = map[string]ForecastModelFactory{}
+	forecast       = DefaultForecast
+)
+
+func init() {
+	if err := RegisterForecastModel(ForecastModelHolt, func(f Forecast) ForecastModel {
+		return holtLinear{alpha: f.Alpha, beta: f.Beta}
+	}); err != nil {
+		panic(err)
+	}
+	if err := RegisterForecastModel(ForecastModelNaive, func(Forecast) ForecastModel {
+		return naive{}
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterForecastModel adds a forecast model into the registry keyed by its name
+func RegisterForecastModel(name string, factory ForecastModelFactory) error {
+	forecastLock.Lock()
+	defer forecastLock.Unlock()
+
+	if _, ok := forecastModels[name]; ok {
+		return ErrForecastModelDuplicate
+	}
+	forecastModels[name] = factory
+	return nil
+}
+
+// SetupForecast configures the forecast. Fields not given use the default values.
+func SetupForecast(f Forecast) error {
+	if f.Model == "" {
+		f.Model = DefaultForecast.Model
+	}
+	if f.Horizon == 0 {
+		f.Horizon = DefaultForecast.Horizon
+	}
+	if f.Interval == 0 {
+		f.Interval = DefaultForecast.Interval
+	}
+	if f.Alpha == 0 {
+		f.Alpha = DefaultForecast.Alpha
+	}
+	if f.Beta == 0 {
+		f.Beta = DefaultForecast.Beta
+	}
+
+	if f.Horizon < 0 || f.Interval <= 0 || f.Interval >= 1 || f.Alpha < 0 || f.Alpha > 1 || f.Beta < 0 || f.Beta > 1 {
+		return fmt.Errorf("%w: %+v", ErrInvalidForecast, f)
+	}
+
+	forecastLock.Lock()
+	defer forecastLock.Unlock()
+
+	if _, ok := forecastModels[f.Model]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownForecastModel, f.Model)
+	}
+
+	forecast = f
+	return nil
+}
+
+// GetForecast returns the configured forecast
+func GetForecast() Forecast {
+	forecastLock.RLock()
+	defer forecastLock.RUnlock()
+
+	return forecast
+}
+
+// model returns the model of the forecast. The default model is used if the model is not registered.
+func (f Forecast) model() ForecastModel {
+	forecastLock.RLock()
+	defer forecastLock.RUnlock()
+
+	factory, ok := forecastModels[f.Model]
+	if !ok {
+		factory = forecastModels[DefaultForecast.Model]
+	}
+	return factory(f)
+}
+
+// ForecastMetric projects a metric calculated by a formula for the days after today. Confirmed cases
+// are projected from the continuous confirm data of the metric, and symptom and behavior scores are
+// projected from their daily history before today followed by the scores of the metric. The score of
+// a day is calculated from the projected confirmed cases and sub-scores, and its bounds come from
+// the bounds of them.
+func (f Forecast) ForecastMetric(formula ScoreFormula, metric schema.Metric, coefficient *schema.ScoreCoefficient,
+	history []schema.MetricHistoryPoint, today time.Time) schema.Forecast {
+	model := f.model()
+	z := math.Sqrt2 * math.Erfinv(f.Interval)
+
+	confirmData := metric.Details.Confirm.ContinuousData
+	cases := make([]float64, len(confirmData))
+	for i, d := range confirmData {
+		cases[i] = d.Cases
+	}
+
+	symptomScores := make([]float64, 0, len(history)+1)
+	behaviorScores := make([]float64, 0, len(history)+1)
+	for _, p := range history {
+		symptomScores = append(symptomScores, p.SymptomScore)
+		behaviorScores = append(behaviorScores, p.BehaviorScore)
+	}
+	symptomScores = append(symptomScores, metric.Details.Symptoms.Score)
+	behaviorScores = append(behaviorScores, metric.Details.Behaviors.Score)
+
+	projectedCases := forecastValues(model, cases, f.Horizon, z, 0, math.Inf(1))
+	projectedSymptoms := forecastValues(model, symptomScores, f.Horizon, z, 0, 100)
+	projectedBehaviors := forecastValues(model, behaviorScores, f.Horizon, z, 0, 100)
+
+	days := make([]schema.ForecastDay, f.Horizon)
+	for i := range days {
+		caseSeries := func(pick func(schema.ForecastValue) float64) []float64 {
+			series := append([]float64(nil), cases...)
+			for _, c := range projectedCases[:i+1] {
+				series = append(series, pick(c))
+			}
+			return series
+		}
+
+		// more confirmed cases, fewer symptoms and more behaviors make a higher score
+		lower := projectedMetric(metric, caseSeries(upperValue), projectedSymptoms[i].Lower, projectedBehaviors[i].Lower)
+		value := projectedMetric(metric, caseSeries(centerValue), projectedSymptoms[i].Value, projectedBehaviors[i].Value)
+		upper := projectedMetric(metric, caseSeries(lowerValue), projectedSymptoms[i].Upper, projectedBehaviors[i].Upper)
+
+		days[i] = schema.ForecastDay{
+			Date:      today.AddDate(0, 0, i+1).Format("2006-01-02"),
+			Confirm:   projectedCases[i],
+			Symptoms:  projectedSymptoms[i],
+			Behaviors: projectedBehaviors[i],
+			Score: schema.ForecastValue{
+				Value: formula.TotalScore(coefficient, value),
+				Lower: formula.TotalScore(coefficient, lower),
+				Upper: formula.TotalScore(coefficient, upper),
+			},
+		}
+	}
+
+	return schema.Forecast{
+		Model:    f.Model,
+		Interval: f.Interval,
+		Days:     days,
+	}
+}
+
+func lowerValue(v schema.ForecastValue) float64  { return v.Lower }
+func centerValue(v schema.ForecastValue) float64 { return v.Value }
+func upperValue(v schema.ForecastValue) float64  { return v.Upper }
+
+// forecastValues projects a series by a model, where the values and their bounds are clamped in [min, max]
+func forecastValues(model ForecastModel, series []float64, horizon int, z, min, max float64) []schema.ForecastValue {
+	values, errors := model.Forecast(series, horizon)
+
+	result := make([]schema.ForecastValue, horizon)
+	for i := range result {
+		result[i] = schema.ForecastValue{
+			Value: math.Max(min, math.Min(max, values[i])),
+			Lower: math.Max(min, math.Min(max, values[i]-z*errors[i])),
+			Upper: math.Max(min, math.Min(max, values[i]+z*errors[i])),
+		}
+	}
+	return result
+}
+
+// projectedMetric returns a metric whose sub-scores are replaced by the projected ones, where the
+// confirm score is calculated from the latest days of the projected confirmed cases
+func projectedMetric(metric schema.Metric, cases []float64, symptomScore, behaviorScore float64) schema.Metric {
+	if len(cases) > consts.ConfirmScoreWindowSize {
+		cases = cases[len(cases)-consts.ConfirmScoreWindowSize:]
+	}
+
+	confirmData := make([]schema.CDSScoreDataSet, len(cases))
+	for i, c := range cases {
+		confirmData[i] = schema.CDSScoreDataSet{Cases: c}
+	}
+
+	metric.Details.Confirm.ContinuousData = confirmData
+	CalculateConfirmScore(&metric)
+	metric.Details.Symptoms.Score = symptomScore
+	metric.Details.Behaviors.Score = behaviorScore
+
+	return metric
+}
+
+// holtLinear is the Holt's linear trend method, which smooths the level of a series by alpha
+// and its trend by beta, and projects the series by the latest level and trend
+type holtLinear struct {
+	alpha float64
+	beta  float64
+}
+
+func (m holtLinear) Forecast(series []float64, horizon int) ([]float64, []float64) {
+	if len(series) < 2 {
+		return naive{}.Forecast(series, horizon)
+	}
+
+	level, trend := series[0], series[1]-series[0]
+	squaredErrors := 0.0
+	for _, y := range series[1:] {
+		e := y - (level + trend)
+		squaredErrors += e * e
+
+		previousLevel := level
+		level = m.alpha*y + (1-m.alpha)*(level+trend)
+		trend = m.beta*(level-previousLevel) + (1-m.beta)*trend
+	}
+	sigma := math.Sqrt(squaredErrors / float64(len(series)-1))
+
+	values := make([]float64, horizon)
+	errors := make([]float64, horizon)
+	variance := 1.0
+	for h := 1; h <= horizon; h++ {
+		if h > 1 {
+			c := m.alpha * (1 + float64(h-1)*m.beta)
+			variance += c * c
+		}
+		values[h-1] = level + float64(h)*trend
+		errors[h-1] = sigma * math.Sqrt(variance)
+	}
+
+	return values, errors
+}
+
+// naive projects a series by its latest value, where the errors grow
+// with the days by the changes between consecutive days
+type naive struct{}
+
+func (naive) Forecast(series []float64, horizon int) ([]float64, []float64) {
+	values := make([]float64, horizon)
+	errors := make([]float64, horizon)
+	if len(series) == 0 {
+		return values, errors
+	}
+
+	squaredChanges := 0.0
+	for i := 1; i < len(series); i++ {
+		d := series[i] - series[i-1]
+		squaredChanges += d * d
+	}
+	sigma := 0.0
+	if len(series) > 1 {
+		sigma = math.Sqrt(squaredChanges / float64(len(series)-1))
+	}
+
+	for h := 1; h <= horizon; h++ {
+		values[h-1] = series[len(series)-1]
+		errors[h-1] = sigma * math.Sqrt(float64(h))
+	}
+
+	return values, errors
+}
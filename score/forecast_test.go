@@ -0,0 +1,104 @@
+package score
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestSetupForecast(t *testing.T) {
+	defer SetupForecast(DefaultForecast)
+
+	assert.NoError(t, SetupForecast(Forecast{}))
+	assert.Equal(t, DefaultForecast, GetForecast())
+
+	assert.NoError(t, SetupForecast(Forecast{Model: ForecastModelNaive, Horizon: 3}))
+	assert.Equal(t, Forecast{Model: ForecastModelNaive, Horizon: 3, Interval: 0.8, Alpha: 0.5, Beta: 0.3}, GetForecast())
+
+	assert.Error(t, SetupForecast(Forecast{Model: "arima"}))
+	assert.Error(t, SetupForecast(Forecast{Interval: 1}))
+	assert.Error(t, SetupForecast(Forecast{Alpha: 1.5}))
+	assert.Error(t, SetupForecast(Forecast{Horizon: -1}))
+	assert.Equal(t, ForecastModelNaive, GetForecast().Model)
+}
+
+func TestRegisterForecastModel(t *testing.T) {
+	assert.Equal(t, ErrForecastModelDuplicate, RegisterForecastModel(ForecastModelHolt, func(Forecast) ForecastModel {
+		return naive{}
+	}))
+}
+
+func TestHoltLinearForecast(t *testing.T) {
+	m := holtLinear{alpha: 0.5, beta: 0.3}
+
+	// a linear series is projected along its line without errors
+	values, errors := m.Forecast([]float64{1, 3, 5, 7, 9}, 3)
+	assert.InDeltaSlice(t, []float64{11, 13, 15}, values, 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 0, 0}, errors, 1e-9)
+
+	// the errors of a noisy series grow with the days
+	values, errors = m.Forecast([]float64{10, 14, 11, 16, 13, 18}, 3)
+	assert.Len(t, values, 3)
+	assert.True(t, errors[0] > 0)
+	assert.True(t, errors[1] > errors[0])
+	assert.True(t, errors[2] > errors[1])
+
+	// a series too short to have a trend is projected naively
+	values, errors = m.Forecast([]float64{5}, 2)
+	assert.Equal(t, []float64{5, 5}, values)
+	assert.Equal(t, []float64{0, 0}, errors)
+}
+
+func TestNaiveForecast(t *testing.T) {
+	values, errors := naive{}.Forecast([]float64{1, 3, 1, 3}, 4)
+	assert.Equal(t, []float64{3, 3, 3, 3}, values)
+	assert.InDeltaSlice(t, []float64{2, 2 * 1.4142135623730951, 2 * 1.7320508075688772, 4}, errors, 1e-9)
+
+	values, errors = naive{}.Forecast(nil, 2)
+	assert.Equal(t, []float64{0, 0}, values)
+	assert.Equal(t, []float64{0, 0}, errors)
+}
+
+func TestForecastMetric(t *testing.T) {
+	formula := DefaultFormula()
+
+	confirmData := make([]schema.CDSScoreDataSet, 14)
+	for i := range confirmData {
+		confirmData[i] = schema.CDSScoreDataSet{Cases: float64(10 + i*2 + i%2)}
+	}
+	metric := formula.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			Confirm: schema.ConfirmDetail{ContinuousData: confirmData},
+		},
+	}, nil)
+
+	history := []schema.MetricHistoryPoint{
+		{SymptomScore: 90, BehaviorScore: 40},
+		{SymptomScore: 85, BehaviorScore: 45},
+		{SymptomScore: 88, BehaviorScore: 42},
+	}
+
+	today := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	forecast := DefaultForecast.ForecastMetric(formula, metric, nil, history, today)
+
+	assert.Equal(t, ForecastModelHolt, forecast.Model)
+	assert.Equal(t, 0.8, forecast.Interval)
+	assert.Len(t, forecast.Days, 7)
+	assert.Equal(t, "2020-06-02", forecast.Days[0].Date)
+	assert.Equal(t, "2020-06-08", forecast.Days[6].Date)
+
+	for i, d := range forecast.Days {
+		for _, v := range []schema.ForecastValue{d.Confirm, d.Symptoms, d.Behaviors, d.Score} {
+			assert.True(t, v.Lower <= v.Value && v.Value <= v.Upper, "day %d: %+v", i, v)
+		}
+		assert.True(t, d.Score.Lower >= 0 && d.Score.Upper <= 100)
+
+		// confirmed cases keep increasing
+		if i > 0 {
+			assert.True(t, d.Confirm.Value > forecast.Days[i-1].Confirm.Value)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+const (
+	FormulaV1 = "v1"
+)
+
+var (
+	ErrUnknownFormula   = fmt.Errorf("unknown score formula")
+	ErrFormulaDuplicate = fmt.Errorf("score formula already registered")
+)
+
+// ScoreFormula defines how an autonomy score is calculated from collected raw metrics
+type ScoreFormula interface {
+	// Version returns the version which is recorded on every metric produced by the formula
+	Version() string
+	// DefaultCoefficient returns the coefficient used when an account has no customized one
+	DefaultCoefficient() schema.ScoreCoefficient
+	// CalculateMetric calculates, summarizes and returns a metric based on collected raw metrics
+	CalculateMetric(rawMetrics schema.Metric, coefficient *schema.ScoreCoefficient) schema.Metric
+	// TotalScore combines the sub-scores of a calculated metric into a total score
+	TotalScore(coefficient *schema.ScoreCoefficient, metric schema.Metric) float64
+	// Explain breaks down a metric calculated by the formula into what drives its score
+	Explain(metric schema.Metric, coefficient *schema.ScoreCoefficient) schema.ScoreExplanation
+}
+
+var (
+	formulaLock sync.RWMutex
+
+	formulas = map[string]ScoreFormula{}
+
+	defaultFormulaVersion  = FormulaV1
+	accountFormulaVersions = map[string]string{}
+)
+
+func init() {
+	if err := RegisterFormula(formulaV1{}); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterFormula adds a formula into the registry keyed by its version
+func RegisterFormula(f ScoreFormula) error {
+	formulaLock.Lock()
+	defer formulaLock.Unlock()
+
+	if _, ok := formulas[f.Version()]; ok {
+		return ErrFormulaDuplicate
+	}
+	formulas[f.Version()] = f
+	return nil
+}
+
+// GetFormula returns a registered formula of a given version
+func GetFormula(version string) (ScoreFormula, error) {
+	formulaLock.RLock()
+	defer formulaLock.RUnlock()
+
+	f, ok := formulas[version]
+	if !ok {
+		return nil, ErrUnknownFormula
+	}
+	return f, nil
+}
+
+// SetupFormula configures the formula used by the deployment and the formulas
+// assigned to specific accounts. It is used for rolling out a new formula to a
+// subset of accounts before switching the whole deployment.
+func SetupFormula(defaultVersion string, accountVersions map[string]string) error {
+	if defaultVersion == "" {
+		defaultVersion = FormulaV1
+	}
+
+	if _, err := GetFormula(defaultVersion); err != nil {
+		return fmt.Errorf("%w: %s", err, defaultVersion)
+	}
+
+	versions := make(map[string]string)
+	for accountNumber, version := range accountVersions {
+		if _, err := GetFormula(version); err != nil {
+			return fmt.Errorf("%w: %s", err, version)
+		}
+		versions[accountNumber] = version
+	}
+
+	formulaLock.Lock()
+	defer formulaLock.Unlock()
+
+	defaultFormulaVersion = defaultVersion
+	accountFormulaVersions = versions
+	return nil
+}
+
+// DefaultFormula returns the formula used by the deployment
+func DefaultFormula() ScoreFormula {
+	formulaLock.RLock()
+	defer formulaLock.RUnlock()
+
+	return formulas[defaultFormulaVersion]
+}
+
+// AccountFormula returns the formula assigned to an account. The deployment
+// formula is returned if the account is not assigned to any formula.
+func AccountFormula(accountNumber string) ScoreFormula {
+	formulaLock.RLock()
+	defer formulaLock.RUnlock()
+
+	if version, ok := accountFormulaVersions[accountNumber]; ok {
+		return formulas[version]
+	}
+	return formulas[defaultFormulaVersion]
+}
+
+// MetricFormulaVersion returns the version of the formula which produced a metric.
+// Metrics calculated before formulas are versioned are seen as v1.
+func MetricFormulaVersion(metric schema.Metric) string {
+	if metric.FormulaVersion == "" {
+		return FormulaV1
+	}
+	return metric.FormulaVersion
+}
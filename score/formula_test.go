@@ -0,0 +1,106 @@
+package score
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type fixedFormula struct {
+	version string
+	score   float64
+}
+
+func (f fixedFormula) Version() string {
+	return f.version
+}
+
+func (f fixedFormula) DefaultCoefficient() schema.ScoreCoefficient {
+	return schema.ScoreCoefficient{}
+}
+
+func (f fixedFormula) CalculateMetric(rawMetrics schema.Metric, coefficient *schema.ScoreCoefficient) schema.Metric {
+	rawMetrics.Score = f.score
+	rawMetrics.FormulaVersion = f.version
+	return rawMetrics
+}
+
+func (f fixedFormula) TotalScore(coefficient *schema.ScoreCoefficient, metric schema.Metric) float64 {
+	return f.score
+}
+
+func (f fixedFormula) Explain(metric schema.Metric, coefficient *schema.ScoreCoefficient) schema.ScoreExplanation {
+	return schema.ScoreExplanation{FormulaVersion: f.version, Score: metric.Score}
+}
+
+func TestFormulaRegistry(t *testing.T) {
+	f, err := GetFormula(FormulaV1)
+	assert.NoError(t, err)
+	assert.Equal(t, FormulaV1, f.Version())
+
+	_, err = GetFormula("not-exist")
+	assert.Equal(t, ErrUnknownFormula, err)
+
+	assert.Equal(t, ErrFormulaDuplicate, RegisterFormula(formulaV1{}))
+}
+
+func TestSetupFormula(t *testing.T) {
+	assert.NoError(t, RegisterFormula(fixedFormula{version: "test-fixed", score: 42}))
+	defer func() {
+		formulaLock.Lock()
+		delete(formulas, "test-fixed")
+		formulaLock.Unlock()
+	}()
+	defer SetupFormula(FormulaV1, nil)
+
+	err := SetupFormula("not-exist", nil)
+	assert.True(t, errors.Is(err, ErrUnknownFormula))
+
+	err = SetupFormula(FormulaV1, map[string]string{"account-a": "not-exist"})
+	assert.True(t, errors.Is(err, ErrUnknownFormula))
+
+	assert.NoError(t, SetupFormula("", map[string]string{"account-a": "test-fixed"}))
+	assert.Equal(t, FormulaV1, DefaultFormula().Version())
+	assert.Equal(t, "test-fixed", AccountFormula("account-a").Version())
+	assert.Equal(t, FormulaV1, AccountFormula("account-b").Version())
+
+	metric := AccountFormula("account-a").CalculateMetric(schema.Metric{}, nil)
+	assert.Equal(t, 42.0, metric.Score)
+	assert.Equal(t, "test-fixed", metric.FormulaVersion)
+}
+
+func TestFormulaV1CalculateMetric(t *testing.T) {
+	metric := formulaV1{}.CalculateMetric(schema.Metric{
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: 10,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: schema.SymptomDistribution{
+						"cough": 3,
+						"fever": 7,
+					},
+				},
+			},
+		},
+	}, nil)
+	assert.Equal(t, FormulaV1, metric.FormulaVersion)
+	assert.Equal(t, DefaultTotalScore(metric.Details.Symptoms.Score, metric.Details.Behaviors.Score, metric.Details.Confirm.Score), metric.Score)
+
+	coefficient := &schema.ScoreCoefficient{Symptoms: 1}
+	assert.Equal(t, metric.Details.Symptoms.Score, formulaV1{}.TotalScore(coefficient, metric))
+
+	customized := formulaV1{}.CalculateMetric(metric, &schema.ScoreCoefficient{
+		Symptoms:       1,
+		SymptomWeights: schema.SymptomWeights{"cough": 2, "fever": 10},
+	})
+	assert.Less(t, customized.Details.Symptoms.Score, metric.Details.Symptoms.Score)
+	assert.Equal(t, customized.Details.Symptoms.Score, customized.Score)
+}
+
+func TestMetricFormulaVersion(t *testing.T) {
+	assert.Equal(t, FormulaV1, MetricFormulaVersion(schema.Metric{}))
+	assert.Equal(t, "v2", MetricFormulaVersion(schema.Metric{FormulaVersion: "v2"}))
+}
@@ -0,0 +1,84 @@
+package score
+
+import (
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+// formulaV1 is the formula which sums up weighted symptom, behavior and confirm scores
+type formulaV1 struct{}
+
+func (formulaV1) Version() string {
+	return FormulaV1
+}
+
+func (formulaV1) DefaultCoefficient() schema.ScoreCoefficient {
+	return schema.ScoreCoefficient{
+		Symptoms:        DefaultScoreV1SymptomCoefficient,
+		Behaviors:       DefaultScoreV1BehaviorCoefficient,
+		Confirms:        DefaultScoreV1ConfirmCoefficient,
+		AirQuality:      GetAirQuality().Coefficient,
+		SymptomWeights:  schema.DefaultSymptomWeights,
+		BehaviorWeights: schema.DefaultBehaviorWeights,
+	}
+}
+
+func (f formulaV1) CalculateMetric(rawMetrics schema.Metric, coefficient *schema.ScoreCoefficient) schema.Metric {
+	metric := rawMetrics
+
+	var symptomWeights schema.SymptomWeights
+	var behaviorWeights schema.BehaviorWeights
+	if coefficient != nil {
+		symptomWeights = coefficient.SymptomWeights
+		behaviorWeights = coefficient.BehaviorWeights
+	}
+
+	UpdateSymptomMetrics(&metric, symptomWeights)
+	UpdateBehaviorMetrics(&metric, behaviorWeights)
+	CalculateConfirmScore(&metric)
+	CalculateAirQualityScore(&metric)
+	UpdateConfidence(&metric)
+
+	metric.Score = f.TotalScore(coefficient, metric)
+	metric.FormulaVersion = f.Version()
+
+	return metric
+}
+
+// TotalScore sums up the weighted sub-scores. The air quality sub-score is blended in if its
+// coefficient is set and the air quality of the metric is known.
+func (f formulaV1) TotalScore(coefficient *schema.ScoreCoefficient, metric schema.Metric) float64 {
+	if coefficient == nil {
+		c := f.DefaultCoefficient()
+		total := DefaultTotalScore(metric.Details.Symptoms.Score, metric.Details.Behaviors.Score, metric.Details.Confirm.Score)
+		return blendAirQuality(total, c, metric)
+	}
+	total := TotalScoreV1(*coefficient, metric.Details.Symptoms.Score, metric.Details.Behaviors.Score, metric.Details.Confirm.Score)
+	return blendAirQuality(total, *coefficient, metric)
+}
+
+func (f formulaV1) Explain(metric schema.Metric, coefficient *schema.ScoreCoefficient) schema.ScoreExplanation {
+	c := f.DefaultCoefficient()
+	if coefficient != nil {
+		c = *coefficient
+	}
+
+	// the coefficients are rescaled when air quality is a part of the score
+	scale := airQualityScale(c, metric)
+	components := []schema.ScoreComponentExplanation{
+		explainComponent("symptoms", scale*c.Symptoms, metric.Details.Symptoms.Score),
+		explainComponent("behaviors", scale*c.Behaviors, metric.Details.Behaviors.Score),
+		explainComponent("confirm", scale*c.Confirms, metric.Details.Confirm.Score),
+	}
+	if scale != 1 {
+		components = append(components, explainComponent("air_quality", scale*c.AirQuality, metric.Details.AirQuality.Score))
+	}
+
+	return schema.ScoreExplanation{
+		FormulaVersion: f.Version(),
+		Score:          metric.Score,
+		Components:     components,
+		Symptoms:       explainSymptoms(metric, c.SymptomWeights),
+		Behaviors:      explainBehaviors(metric, c.BehaviorWeights),
+		Confirm:        explainConfirm(metric),
+	}
+}
@@ -0,0 +1,78 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/consts"
+)
+
+var (
+	ErrInvalidNeighborhood = fmt.Errorf("invalid neighborhood")
+)
+
+// Neighborhood configures the area around a location, in meters, where reports are collected.
+// If AdaptiveRadii are given, the radius is widened to each of them in order until
+// at least MinReporters users have reported in the area. The last one is the maximum radius.
+type Neighborhood struct {
+	Radius        int   `mapstructure:"radius"`
+	CohortRadius  int   `mapstructure:"cohort_radius"`
+	AdaptiveRadii []int `mapstructure:"adaptive_radii"`
+	MinReporters  int   `mapstructure:"min_reporters"`
+}
+
+var DefaultNeighborhood = Neighborhood{
+	Radius:       consts.NEARBY_DISTANCE_RANGE,
+	CohortRadius: consts.CORHORT_DISTANCE_RANGE,
+	MinReporters: 5,
+}
+
+var (
+	neighborhoodLock sync.RWMutex
+	neighborhood     = DefaultNeighborhood
+)
+
+// SetupNeighborhood configures the neighborhood. Fields not given use the default values.
+func SetupNeighborhood(n Neighborhood) error {
+	if n.Radius == 0 {
+		n.Radius = DefaultNeighborhood.Radius
+	}
+	if n.CohortRadius == 0 {
+		n.CohortRadius = DefaultNeighborhood.CohortRadius
+	}
+	if n.MinReporters == 0 {
+		n.MinReporters = DefaultNeighborhood.MinReporters
+	}
+
+	if n.Radius < 0 || n.CohortRadius < 0 || n.MinReporters < 0 {
+		return fmt.Errorf("%w: %+v", ErrInvalidNeighborhood, n)
+	}
+
+	radius := n.Radius
+	for _, r := range n.AdaptiveRadii {
+		if r <= radius {
+			return fmt.Errorf("%w: adaptive radius %d is not wider than %d", ErrInvalidNeighborhood, r, radius)
+		}
+		radius = r
+	}
+
+	neighborhoodLock.Lock()
+	defer neighborhoodLock.Unlock()
+
+	n.AdaptiveRadii = append([]int(nil), n.AdaptiveRadii...)
+	neighborhood = n
+	return nil
+}
+
+// GetNeighborhood returns the configured neighborhood
+func GetNeighborhood() Neighborhood {
+	neighborhoodLock.RLock()
+	defer neighborhoodLock.RUnlock()
+
+	return neighborhood
+}
+
+// Radii returns the radii to collect reports with, from the narrowest to the widest
+func (n Neighborhood) Radii() []int {
+	return append([]int{n.Radius}, n.AdaptiveRadii...)
+}
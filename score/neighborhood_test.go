@@ -0,0 +1,33 @@
+package score
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupNeighborhood(t *testing.T) {
+	defer SetupNeighborhood(DefaultNeighborhood)
+
+	assert.NoError(t, SetupNeighborhood(Neighborhood{}))
+	assert.Equal(t, DefaultNeighborhood, GetNeighborhood())
+	assert.Equal(t, []int{1000}, GetNeighborhood().Radii())
+
+	assert.NoError(t, SetupNeighborhood(Neighborhood{
+		Radius:        500,
+		AdaptiveRadii: []int{2000, 5000},
+		MinReporters:  10,
+	}))
+	assert.Equal(t, Neighborhood{
+		Radius:        500,
+		CohortRadius:  DefaultNeighborhood.CohortRadius,
+		AdaptiveRadii: []int{2000, 5000},
+		MinReporters:  10,
+	}, GetNeighborhood())
+	assert.Equal(t, []int{500, 2000, 5000}, GetNeighborhood().Radii())
+
+	assert.Error(t, SetupNeighborhood(Neighborhood{Radius: -1}))
+	assert.Error(t, SetupNeighborhood(Neighborhood{AdaptiveRadii: []int{5000, 2000}}))
+	assert.Error(t, SetupNeighborhood(Neighborhood{AdaptiveRadii: []int{1000}}))
+	assert.Equal(t, []int{500, 2000, 5000}, GetNeighborhood().Radii())
+}
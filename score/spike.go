@@ -0,0 +1,141 @@
+package score
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrInvalidSpikeDetector = fmt.Errorf("invalid spike detector")
+)
+
+// SpikeDetector configures how a symptom spike is detected. The baseline of a symptom
+// is the exponentially weighted moving average (EWMA) of its daily counts in the last
+// BaselineDays days. Since the counts are seen as Poisson distributed, a symptom spikes
+// if its count today is at least MinCount and its z-score against the baseline is at
+// least Sensitivity. A lower sensitivity detects more spikes.
+type SpikeDetector struct {
+	BaselineDays int     `mapstructure:"baseline_days"`
+	MinCount     int     `mapstructure:"min_count"`
+	Sensitivity  float64 `mapstructure:"sensitivity"`
+	Smoothing    float64 `mapstructure:"smoothing"`
+}
+
+var DefaultSpikeDetector = SpikeDetector{
+	BaselineDays: 7,
+	MinCount:     3,
+	Sensitivity:  3,
+	Smoothing:    0.3,
+}
+
+var (
+	spikeDetectorLock sync.RWMutex
+	spikeDetector     = DefaultSpikeDetector
+)
+
+// SetupSpikeDetector configures the spike detector. Fields not given use the default values.
+func SetupSpikeDetector(d SpikeDetector) error {
+	if d.BaselineDays == 0 {
+		d.BaselineDays = DefaultSpikeDetector.BaselineDays
+	}
+	if d.MinCount == 0 {
+		d.MinCount = DefaultSpikeDetector.MinCount
+	}
+	if d.Sensitivity == 0 {
+		d.Sensitivity = DefaultSpikeDetector.Sensitivity
+	}
+	if d.Smoothing == 0 {
+		d.Smoothing = DefaultSpikeDetector.Smoothing
+	}
+
+	if d.BaselineDays < 0 || d.MinCount < 0 || d.Sensitivity < 0 || d.Smoothing < 0 || d.Smoothing > 1 {
+		return fmt.Errorf("%w: %+v", ErrInvalidSpikeDetector, d)
+	}
+
+	spikeDetectorLock.Lock()
+	defer spikeDetectorLock.Unlock()
+
+	spikeDetector = d
+	return nil
+}
+
+// GetSpikeDetector returns the configured spike detector
+func GetSpikeDetector() SpikeDetector {
+	spikeDetectorLock.RLock()
+	defer spikeDetectorLock.RUnlock()
+
+	return spikeDetector
+}
+
+// Baseline returns the EWMA of the daily counts of a symptom. The distributions are
+// ordered from the oldest day to the latest.
+func (d SpikeDetector) Baseline(symptomID string, distributions []schema.SymptomDistribution) float64 {
+	baseline := float64(0)
+	for i, distribution := range distributions {
+		count := distribution[symptomID]
+		if i == 0 {
+			baseline = count
+			continue
+		}
+		baseline = d.Smoothing*count + (1-d.Smoothing)*baseline
+	}
+	return baseline
+}
+
+// Severity grades a z-score which exceeds the sensitivity
+func (d SpikeDetector) Severity(zScore float64) schema.SpikeSeverity {
+	switch {
+	case zScore >= 2*d.Sensitivity:
+		return schema.SpikeSeverityHigh
+	case zScore >= 1.5*d.Sensitivity:
+		return schema.SpikeSeverityMedium
+	default:
+		return schema.SpikeSeverityLow
+	}
+}
+
+// Detect returns the spiking symptoms of today against the baseline distributions
+// which are ordered from the oldest day to yesterday. The most severe spikes come first.
+func (d SpikeDetector) Detect(baselineDistributions []schema.SymptomDistribution, today schema.SymptomDistribution) []schema.SymptomSpike {
+	spikes := make([]schema.SymptomSpike, 0)
+
+	for symptomID, count := range today {
+		if count < float64(d.MinCount) {
+			continue
+		}
+
+		baseline := d.Baseline(symptomID, baselineDistributions)
+		// the variance of a Poisson distribution equals to its mean. The variance is
+		// at least 1 so that a symptom which is rarely reported does not spike easily.
+		zScore := (count - baseline) / math.Sqrt(math.Max(baseline, 1))
+		if zScore < d.Sensitivity {
+			continue
+		}
+
+		spikes = append(spikes, schema.SymptomSpike{
+			ID:       symptomID,
+			Count:    count,
+			Baseline: baseline,
+			ZScore:   zScore,
+			Severity: d.Severity(zScore),
+		})
+	}
+
+	sort.Slice(spikes, func(i, j int) bool {
+		if spikes[i].ZScore != spikes[j].ZScore {
+			return spikes[i].ZScore > spikes[j].ZScore
+		}
+		return spikes[i].ID < spikes[j].ID
+	})
+
+	return spikes
+}
+
+// DetectSymptomSpikes detects symptom spikes by the configured spike detector
+func DetectSymptomSpikes(baselineDistributions []schema.SymptomDistribution, today schema.SymptomDistribution) []schema.SymptomSpike {
+	return GetSpikeDetector().Detect(baselineDistributions, today)
+}
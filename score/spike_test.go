@@ -0,0 +1,79 @@
+package score
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestSpikeDetectorBaseline(t *testing.T) {
+	d := SpikeDetector{Smoothing: 0.5}
+	assert.Equal(t, 0.0, d.Baseline("cough", nil))
+	assert.Equal(t, 4.0, d.Baseline("cough", []schema.SymptomDistribution{{"cough": 4}}))
+	// 4 -> 0.5*0 + 0.5*4 = 2 -> 0.5*8 + 0.5*2 = 5
+	assert.Equal(t, 5.0, d.Baseline("cough", []schema.SymptomDistribution{
+		{"cough": 4},
+		{"fever": 1},
+		{"cough": 8},
+	}))
+}
+
+func TestSpikeDetectorDetect(t *testing.T) {
+	d := DefaultSpikeDetector
+	stable := []schema.SymptomDistribution{
+		{"cough": 10, "fever": 2},
+		{"cough": 10, "fever": 2},
+		{"cough": 10, "fever": 2},
+	}
+
+	testCases := []struct {
+		name     string
+		baseline []schema.SymptomDistribution
+		today    schema.SymptomDistribution
+		spikes   []string
+		severity []schema.SpikeSeverity
+	}{
+		{"slight increase", stable, schema.SymptomDistribution{"cough": 11, "fever": 3}, []string{}, nil},
+		{"new symptom under min count", stable, schema.SymptomDistribution{"breath": 1}, []string{}, nil},
+		{"new symptom reaching min count", stable, schema.SymptomDistribution{"breath": 3}, []string{"breath"}, []schema.SpikeSeverity{schema.SpikeSeverityLow}},
+		{"no baseline", nil, schema.SymptomDistribution{"cough": 5}, []string{"cough"}, []schema.SpikeSeverity{schema.SpikeSeverityMedium}},
+		{"large increase", stable, schema.SymptomDistribution{"cough": 30, "fever": 8}, []string{"cough", "fever"}, []schema.SpikeSeverity{schema.SpikeSeverityHigh, schema.SpikeSeverityLow}},
+	}
+
+	for _, tc := range testCases {
+		spikes := d.Detect(tc.baseline, tc.today)
+		ids := make([]string, 0)
+		for i, s := range spikes {
+			ids = append(ids, s.ID)
+			assert.Equal(t, tc.severity[i], s.Severity, fmt.Sprintf("%s: %s", tc.name, s.ID))
+		}
+		assert.Equal(t, tc.spikes, ids, tc.name)
+	}
+}
+
+func TestSpikeDetectorSensitivity(t *testing.T) {
+	baseline := []schema.SymptomDistribution{{"cough": 10}}
+	today := schema.SymptomDistribution{"cough": 18}
+
+	assert.Len(t, SpikeDetector{MinCount: 3, Sensitivity: 3, Smoothing: 0.3}.Detect(baseline, today), 0)
+	assert.Len(t, SpikeDetector{MinCount: 3, Sensitivity: 2, Smoothing: 0.3}.Detect(baseline, today), 1)
+	assert.Len(t, SpikeDetector{MinCount: 20, Sensitivity: 2, Smoothing: 0.3}.Detect(baseline, today), 0)
+}
+
+func TestSetupSpikeDetector(t *testing.T) {
+	defer SetupSpikeDetector(SpikeDetector{})
+
+	assert.True(t, errors.Is(SetupSpikeDetector(SpikeDetector{Smoothing: 2}), ErrInvalidSpikeDetector))
+	assert.True(t, errors.Is(SetupSpikeDetector(SpikeDetector{MinCount: -1}), ErrInvalidSpikeDetector))
+
+	assert.NoError(t, SetupSpikeDetector(SpikeDetector{Sensitivity: 2}))
+	d := GetSpikeDetector()
+	assert.Equal(t, 2.0, d.Sensitivity)
+	assert.Equal(t, DefaultSpikeDetector.BaselineDays, d.BaselineDays)
+	assert.Equal(t, DefaultSpikeDetector.MinCount, d.MinCount)
+	assert.Equal(t, DefaultSpikeDetector.Smoothing, d.Smoothing)
+}
@@ -6,51 +6,115 @@ import (
 	"github.com/bitmark-inc/autonomy-api/schema"
 )
 
-func UpdateSymptomMetrics(metric *schema.Metric) {
-	rawData := metric.Details.Symptoms
+// MergeSymptomWeights applies customized weights over the default symptom weights,
+// so that official symptoms left out of customized weights keep their default weights.
+func MergeSymptomWeights(weights schema.SymptomWeights) schema.SymptomWeights {
+	merged := make(schema.SymptomWeights, len(schema.DefaultSymptomWeights))
+	for symptomID, w := range schema.DefaultSymptomWeights {
+		merged[symptomID] = w
+	}
+	for symptomID, w := range weights {
+		merged[symptomID] = w
+	}
+	return merged
+}
+
+// symptomWeighing is how the symptoms reported today weigh in the symptom score
+type symptomWeighing struct {
+	contributions    []schema.SymptomContribution
+	weightedSum      float64
+	maxWeightedSum   float64
+	officialCount    float64
+	nonOfficialCount float64
+}
+
+// weighSymptoms weighs the symptoms reported today. A non-official symptom weighs 1, and
+// the penalty of a symptom is the points it takes off the symptom score.
+func weighSymptoms(rawData schema.SymptomDetail, weights schema.SymptomWeights) symptomWeighing {
+	weights = MergeSymptomWeights(weights)
 
 	totalWeight := float64(0)
-	for _, w := range schema.DefaultSymptomWeights {
+	for _, w := range weights {
 		totalWeight += w
 	}
 
-	weightedSum := float64(0)
-	officialCount := 0
-	nonOfficialCount := 0
+	var w symptomWeighing
+	w.contributions = make([]schema.SymptomContribution, 0, len(rawData.TodayData.WeightDistribution))
 	for symptomID, cnt := range rawData.TodayData.WeightDistribution {
-		weight, ok := schema.DefaultSymptomWeights[symptomID]
+		weight, ok := weights[symptomID]
 		if ok {
-			officialCount += cnt
+			w.officialCount += cnt
 		} else {
 			weight = 1
-			nonOfficialCount += cnt
+			w.nonOfficialCount += cnt
 		}
 
-		weightedSum += float64(cnt) * weight
+		w.weightedSum += cnt * weight
+		w.contributions = append(w.contributions, schema.SymptomContribution{
+			ID:       symptomID,
+			Count:    cnt,
+			Weight:   weight,
+			Official: ok,
+		})
+	}
+
+	w.maxWeightedSum = rawData.TotalPeople*totalWeight + w.nonOfficialCount
+	if w.maxWeightedSum > 0 {
+		for i, c := range w.contributions {
+			w.contributions[i].Penalty = 100 * c.Count * c.Weight / w.maxWeightedSum
+		}
 	}
 
+	return w
+}
+
+// UpdateSymptomMetrics calculates the symptom score with given symptom weights.
+// The default symptom weights are applied if weights are not provided.
+func UpdateSymptomMetrics(metric *schema.Metric, weights schema.SymptomWeights) {
+	rawData := metric.Details.Symptoms
+
+	weighing := weighSymptoms(rawData, weights)
+	officialCount, nonOfficialCount := weighing.officialCount, weighing.nonOfficialCount
+
 	totalCountToday := officialCount + nonOfficialCount
-	totalCountYesterday := 0
+	totalCountYesterday := float64(0)
 	for _, cnt := range rawData.YesterdayData.WeightDistribution {
 		totalCountYesterday += cnt
 	}
 
-	maxWeightedSum := float64(rawData.TotalPeople)*totalWeight + float64(nonOfficialCount)
 	score := 100.0
-	if maxWeightedSum > 0 {
-		score = 100 * (1 - weightedSum/maxWeightedSum)
+	if weighing.maxWeightedSum > 0 {
+		score = 100 * (1 - weighing.weightedSum/weighing.maxWeightedSum)
 	}
 
-	spikeList := CheckSymptomSpike(rawData.YesterdayData.WeightDistribution, rawData.TodayData.WeightDistribution)
+	// use yesterday as the baseline if daily distributions are not collected
+	baselineData := rawData.BaselineData
+	if len(baselineData) == 0 && rawData.YesterdayData.WeightDistribution != nil {
+		baselineData = []schema.SymptomDistribution{rawData.YesterdayData.WeightDistribution}
+	}
+
+	latestDayData := rawData.LatestDayData
+	if latestDayData == nil {
+		latestDayData = rawData.TodayData.WeightDistribution
+	}
+
+	spikes := DetectSymptomSpikes(baselineData, latestDayData)
+	spikeList := make([]string, 0, len(spikes))
+	for _, spike := range spikes {
+		spikeList = append(spikeList, spike.ID)
+	}
 
-	metric.SymptomCount = float64(totalCountToday)
-	metric.SymptomDelta = ChangeRate(float64(totalCountToday), float64(totalCountYesterday))
+	metric.SymptomCount = totalCountToday
+	metric.SymptomDelta = ChangeRate(totalCountToday, totalCountYesterday)
 	metric.Details.Symptoms = schema.SymptomDetail{
 		Score:           score,
-		TotalPeople:     float64(rawData.TotalPeople),
+		TotalPeople:     rawData.TotalPeople,
 		TodayData:       metric.Details.Symptoms.TodayData,
 		YesterdayData:   metric.Details.Symptoms.YesterdayData,
+		BaselineData:    metric.Details.Symptoms.BaselineData,
+		LatestDayData:   metric.Details.Symptoms.LatestDayData,
 		LastSpikeList:   spikeList,
+		LastSpikes:      spikes,
 		LastSpikeUpdate: time.Now().UTC(),
 	}
 }
@@ -15,13 +15,13 @@ func TestUpdateSymptomMetrics(t *testing.T) {
 			Symptoms: schema.SymptomDetail{
 				TotalPeople: 10,
 				TodayData: schema.NearestSymptomData{
-					WeightDistribution: map[string]int{
+					WeightDistribution: map[string]float64{
 						"cough":       3, // weight 2
 						"fever":       7, // weight 3
 						"new-symptom": 1, // weight 1
 					}},
 				YesterdayData: schema.NearestSymptomData{
-					WeightDistribution: map[string]int{
+					WeightDistribution: map[string]float64{
 						"cough":       1,
 						"fever":       1,
 						"new-symptom": 2,
@@ -29,14 +29,14 @@ func TestUpdateSymptomMetrics(t *testing.T) {
 			},
 		},
 	}
-	UpdateSymptomMetrics(metric)
+	UpdateSymptomMetrics(metric, nil)
 	assert.Equal(t, "76.86", fmt.Sprintf("%.2f", metric.Details.Symptoms.Score))
 	assert.Equal(t, 10.0, metric.Details.Symptoms.TotalPeople)
 	assert.Equal(t, 11.0, metric.SymptomCount)
 	assert.Equal(t, 175.0, metric.SymptomDelta)
 
 	// the function must be idempotent
-	UpdateSymptomMetrics(metric)
+	UpdateSymptomMetrics(metric, nil)
 	assert.Equal(t, "76.86", fmt.Sprintf("%.2f", metric.Details.Symptoms.Score))
 	assert.Equal(t, 10.0, metric.Details.Symptoms.TotalPeople)
 	assert.Equal(t, 11.0, metric.SymptomCount)
@@ -57,7 +57,7 @@ func TestUpdateSymptomMetricsNoReportYesterday(t *testing.T) {
 			},
 		},
 	}
-	UpdateSymptomMetrics(metric)
+	UpdateSymptomMetrics(metric, nil)
 	assert.Equal(t, "48.39", fmt.Sprintf("%.2f", metric.Details.Symptoms.Score))
 	assert.Equal(t, 5.0, metric.Details.Symptoms.TotalPeople)
 	assert.Equal(t, 12.0, metric.SymptomCount)
@@ -78,9 +78,85 @@ func TestUpdateSymptomMetricsNoReportToday(t *testing.T) {
 			},
 		},
 	}
-	UpdateSymptomMetrics(metric)
+	UpdateSymptomMetrics(metric, nil)
 	assert.Equal(t, 100.0, metric.Details.Symptoms.Score)
 	assert.Equal(t, 5.0, metric.Details.Symptoms.TotalPeople)
 	assert.Equal(t, 0.0, metric.SymptomCount)
 	assert.Equal(t, -100.0, metric.SymptomDelta)
 }
+
+func TestUpdateSymptomMetricsCustomizedWeights(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights schema.SymptomWeights
+		score   string
+	}{
+		{"default weights", nil, "77.50"},
+		{"heavier fever", schema.SymptomWeights{"cough": 2, "fever": 10}, "60.00"},
+		{"ignore fever", schema.SymptomWeights{"cough": 2, "fever": 0, "breath": 10}, "96.67"},
+		{"fever keeps its default weight", schema.SymptomWeights{"cough": 1}, "78.18"},
+	}
+
+	for _, tc := range testCases {
+		metric := &schema.Metric{
+			Details: schema.Details{
+				Symptoms: schema.SymptomDetail{
+					TotalPeople: 10,
+					TodayData: schema.NearestSymptomData{
+						WeightDistribution: schema.SymptomDistribution{
+							"cough": 3,
+							"fever": 7,
+						}},
+				},
+			},
+		}
+		UpdateSymptomMetrics(metric, tc.weights)
+		assert.Equal(t, tc.score, fmt.Sprintf("%.2f", metric.Details.Symptoms.Score), tc.name)
+		assert.Equal(t, 10.0, metric.SymptomCount, tc.name)
+	}
+}
+
+func TestUpdateSymptomMetricsSpikes(t *testing.T) {
+	metric := &schema.Metric{
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: 20,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: schema.SymptomDistribution{
+						"cough": 11,
+						"fever": 9,
+						"nasal": 1,
+					}},
+				YesterdayData: schema.NearestSymptomData{
+					WeightDistribution: schema.SymptomDistribution{
+						"cough": 10,
+						"fever": 1,
+					}},
+			},
+		},
+	}
+
+	// yesterday is the baseline if daily distributions are not given
+	UpdateSymptomMetrics(metric, nil)
+	assert.Equal(t, []string{"fever"}, metric.Details.Symptoms.LastSpikeList)
+	assert.Len(t, metric.Details.Symptoms.LastSpikes, 1)
+	assert.Equal(t, schema.SpikeSeverityHigh, metric.Details.Symptoms.LastSpikes[0].Severity)
+
+	// fever is usual in the last days
+	metric.Details.Symptoms.BaselineData = []schema.SymptomDistribution{
+		{"cough": 10, "fever": 8},
+		{"cough": 10, "fever": 9},
+		{"cough": 10, "fever": 1},
+	}
+	UpdateSymptomMetrics(metric, nil)
+	assert.Empty(t, metric.Details.Symptoms.LastSpikeList)
+	assert.Empty(t, metric.Details.Symptoms.LastSpikes)
+
+	// spikes are detected from the latest day rather than the whole score window
+	metric.Details.Symptoms.LatestDayData = schema.SymptomDistribution{
+		"cough": 30,
+		"fever": 9,
+	}
+	UpdateSymptomMetrics(metric, nil)
+	assert.Equal(t, []string{"cough"}, metric.Details.Symptoms.LastSpikeList)
+}
@@ -0,0 +1,85 @@
+package score
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrInvalidScoreWindow = fmt.Errorf("invalid score window")
+)
+
+// DefaultScoreWindow compares the reports of today with the reports of yesterday
+var DefaultScoreWindow = schema.ScoreWindow{
+	Mode: schema.ScoreWindowCalendar,
+}
+
+var (
+	scoreWindowLock sync.RWMutex
+	scoreWindows    = map[string]schema.ScoreWindow{}
+)
+
+// SetupScoreWindows configures the score windows of formulas keyed by formula versions.
+// Formulas not given use the default window.
+func SetupScoreWindows(windows map[string]schema.ScoreWindow) error {
+	configured := make(map[string]schema.ScoreWindow)
+	for version, w := range windows {
+		if _, err := GetFormula(version); err != nil {
+			return fmt.Errorf("%w: %s", err, version)
+		}
+
+		switch w.Mode {
+		case "", schema.ScoreWindowCalendar:
+			w = DefaultScoreWindow
+		case schema.ScoreWindowRolling:
+			if w.Hours <= 0 || w.HalfLifeHours < 0 {
+				return fmt.Errorf("%w: %+v", ErrInvalidScoreWindow, w)
+			}
+		default:
+			return fmt.Errorf("%w: unknown mode %s", ErrInvalidScoreWindow, w.Mode)
+		}
+		configured[version] = w
+	}
+
+	scoreWindowLock.Lock()
+	defer scoreWindowLock.Unlock()
+
+	scoreWindows = configured
+	return nil
+}
+
+// FormulaScoreWindow returns the score window of a formula
+func FormulaScoreWindow(version string) schema.ScoreWindow {
+	scoreWindowLock.RLock()
+	defer scoreWindowLock.RUnlock()
+
+	if w, ok := scoreWindows[version]; ok {
+		return w
+	}
+	return DefaultScoreWindow
+}
+
+// ReportPeriod is a time range [Start, End) of reports
+type ReportPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ReportPeriods returns the current period of reports collected by a window and
+// the previous period which the current one is compared with.
+func ReportPeriods(window schema.ScoreWindow, now time.Time) (current, previous ReportPeriod) {
+	if window.Mode == schema.ScoreWindowRolling {
+		length := time.Duration(window.Hours) * time.Hour
+		current = ReportPeriod{Start: now.Add(-length), End: now}
+		previous = ReportPeriod{Start: now.Add(-2 * length), End: now.Add(-length)}
+		return
+	}
+
+	todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	current = ReportPeriod{Start: todayStartAt, End: todayStartAt.AddDate(0, 0, 1)}
+	previous = ReportPeriod{Start: todayStartAt.AddDate(0, 0, -1), End: todayStartAt}
+	return
+}
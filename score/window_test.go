@@ -0,0 +1,53 @@
+package score
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestSetupScoreWindows(t *testing.T) {
+	defer SetupScoreWindows(nil)
+
+	rolling := schema.ScoreWindow{Mode: schema.ScoreWindowRolling, Hours: 72, HalfLifeHours: 24}
+	assert.NoError(t, SetupScoreWindows(map[string]schema.ScoreWindow{FormulaV1: rolling}))
+	assert.Equal(t, rolling, FormulaScoreWindow(FormulaV1))
+	assert.Equal(t, DefaultScoreWindow, FormulaScoreWindow("v0"))
+	assert.Equal(t, 24*time.Hour, FormulaScoreWindow(FormulaV1).HalfLife())
+
+	assert.Error(t, SetupScoreWindows(map[string]schema.ScoreWindow{"v0": rolling}))
+	assert.Error(t, SetupScoreWindows(map[string]schema.ScoreWindow{FormulaV1: {Mode: schema.ScoreWindowRolling}}))
+	assert.Error(t, SetupScoreWindows(map[string]schema.ScoreWindow{FormulaV1: {Mode: "weekly"}}))
+	assert.Equal(t, rolling, FormulaScoreWindow(FormulaV1))
+
+	assert.NoError(t, SetupScoreWindows(map[string]schema.ScoreWindow{FormulaV1: {HalfLifeHours: 24}}))
+	assert.Equal(t, DefaultScoreWindow, FormulaScoreWindow(FormulaV1))
+	assert.Equal(t, time.Duration(0), FormulaScoreWindow(FormulaV1).HalfLife())
+}
+
+func TestReportPeriods(t *testing.T) {
+	now := time.Date(2020, 6, 10, 15, 30, 0, 0, time.UTC)
+
+	current, previous := ReportPeriods(DefaultScoreWindow, now)
+	assert.Equal(t, ReportPeriod{
+		Start: time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2020, 6, 11, 0, 0, 0, 0, time.UTC),
+	}, current)
+	assert.Equal(t, ReportPeriod{
+		Start: time.Date(2020, 6, 9, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC),
+	}, previous)
+
+	current, previous = ReportPeriods(schema.ScoreWindow{Mode: schema.ScoreWindowRolling, Hours: 72, HalfLifeHours: 24}, now)
+	assert.Equal(t, ReportPeriod{
+		Start: time.Date(2020, 6, 7, 15, 30, 0, 0, time.UTC),
+		End:   now,
+	}, current)
+	assert.Equal(t, ReportPeriod{
+		Start: time.Date(2020, 6, 4, 15, 30, 0, 0, time.UTC),
+		End:   time.Date(2020, 6, 7, 15, 30, 0, 0, time.UTC),
+	}, previous)
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -35,6 +36,24 @@ func aggStageReportedBetween(start, end int64) bson.M {
 	}
 }
 
+// aggExprTimeDecayWeight returns the expression of the weight of a report, which halves every
+// halfLife before the reference time. Every report weighs 1 if halfLife is not positive.
+func aggExprTimeDecayWeight(ref int64, halfLife time.Duration) interface{} {
+	if halfLife <= 0 {
+		// a bare 1 in $project would include a field instead of the value
+		return bson.M{"$literal": 1}
+	}
+
+	return bson.M{
+		"$exp": bson.M{
+			"$multiply": bson.A{
+				-math.Ln2 / halfLife.Seconds(),
+				bson.M{"$subtract": bson.A{ref, "$ts"}},
+			},
+		},
+	}
+}
+
 func aggStagePreventNullArray(fields ...string) bson.M {
 	targets := bson.M{}
 	for _, field := range fields {
@@ -47,9 +66,47 @@ func specifyField(fieldName string) string {
 	return fmt.Sprintf("$%s", fieldName)
 }
 
-func getStartTimeOfConsecutiveDays(now time.Time) (yesterdayStartAt time.Time, todayStartAt time.Time, tomorrowStartAt time.Time) {
-	todayStartAt = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// aggExprLocalDate returns the expression of the date of a report in a time zone,
+// where the offset of the time zone is taken at the given time.
+func aggExprLocalDate(t time.Time) bson.M {
+	return bson.M{
+		"$dateToString": bson.M{
+			"format": "%Y-%m-%d",
+			"date": bson.M{
+				"$toDate": bson.M{
+					"$multiply": bson.A{"$ts", 1000},
+				},
+			},
+			"timezone": t.Format("-07:00"),
+		},
+	}
+}
+
+// getStartTimeOfConsecutiveDays returns the start time of yesterday, today and tomorrow in the given location
+func getStartTimeOfConsecutiveDays(now time.Time, tz *time.Location) (yesterdayStartAt time.Time, todayStartAt time.Time, tomorrowStartAt time.Time) {
+	now = now.In(tz)
+	todayStartAt = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
 	yesterdayStartAt = todayStartAt.AddDate(0, 0, -1)
 	tomorrowStartAt = todayStartAt.AddDate(0, 0, 1)
 	return
 }
+
+// aggStageGeoWithin matches the reports located within any of the given geometries
+func aggStageGeoWithin(geometries []schema.Geometry) bson.M {
+	within := make(bson.A, 0, len(geometries))
+	for _, g := range geometries {
+		within = append(within, bson.M{
+			"location": bson.M{
+				"$geoWithin": bson.M{
+					"$geometry": g,
+				},
+			},
+		})
+	}
+
+	return bson.M{
+		"$match": bson.M{
+			"$or": within,
+		},
+	}
+}
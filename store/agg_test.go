@@ -0,0 +1,18 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAggExprTimeDecayWeight(t *testing.T) {
+	// every report weighs 1 without a half life, which is not a field inclusion in $project
+	assert.Equal(t, bson.M{"$literal": 1}, aggExprTimeDecayWeight(100, 0))
+
+	weight, ok := aggExprTimeDecayWeight(100, time.Hour).(bson.M)
+	assert.True(t, ok)
+	assert.Contains(t, weight, "$exp")
+}
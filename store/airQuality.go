@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/external/aqi"
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+var (
+	aqiClientLock sync.RWMutex
+	aqiClient     aqi.AQI
+)
+
+// SetAQIClient sets the client which air quality is looked up from. Air quality
+// is left out of metrics if no client is set.
+func SetAQIClient(client aqi.AQI) {
+	aqiClientLock.Lock()
+	defer aqiClientLock.Unlock()
+
+	aqiClient = client
+}
+
+func getAQIClient() aqi.AQI {
+	aqiClientLock.RLock()
+	defer aqiClientLock.RUnlock()
+
+	return aqiClient
+}
+
+// collectAirQualityMetrics gathers the air quality of a location into a metric.
+// The air quality is marked unavailable if it can not be looked up.
+func (m *mongoDB) collectAirQualityMetrics(location schema.Location, metric *schema.Metric) {
+	client := getAQIClient()
+	if client == nil {
+		return
+	}
+
+	index, err := m.cellAirQuality(client, location, time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"prefix":   mongoLogPrefix,
+			"location": location,
+			"err":      err,
+		}).Warn("collect air quality")
+		return
+	}
+
+	metric.Details.AirQuality = schema.AirQualityDetail{
+		AQI:       index,
+		Available: true,
+	}
+}
+
+// cellAirQuality returns the air quality of the geohash cell a location falls in. The air quality
+// of a cell is looked up at the center of the cell and cached until it is expired.
+func (m *mongoDB) cellAirQuality(client aqi.AQI, location schema.Location, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	hash := utils.GeohashEncode(location.Latitude, location.Longitude, schema.AirQualityCellPrecision)
+	c := m.client.Database(m.database).Collection(schema.AirQualityCollection)
+
+	var cached schema.AirQuality
+	err := c.FindOne(ctx, bson.M{
+		"_id":        hash,
+		"updated_at": bson.M{"$gt": now.Add(-schema.AirQualityTTL)},
+	}).Decode(&cached)
+	if err == nil {
+		return cached.AQI, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+
+	box, _ := utils.GeohashDecode(hash)
+	index, err := client.Get((box.MinLatitude+box.MaxLatitude)/2, (box.MinLongitude+box.MaxLongitude)/2)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := c.ReplaceOne(ctx, bson.M{"_id": hash}, schema.AirQuality{
+		Geohash:   hash,
+		AQI:       index,
+		UpdatedAt: now.UTC(),
+	}, options.Replace().SetUpsert(true)); err != nil {
+		// the air quality is still usable even if it is not cached
+		log.WithFields(log.Fields{
+			"prefix":  mongoLogPrefix,
+			"geohash": hash,
+			"err":     err,
+		}).Warn("cache air quality")
+	}
+
+	return index, nil
+}
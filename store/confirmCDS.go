@@ -2,7 +2,6 @@ package store
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -24,25 +23,26 @@ var (
 )
 
 type ConfirmCDS interface {
-	ReplaceCDS(result []schema.CDSData, country string) error
-	CreateCDS(result []schema.CDSData, country string) error
+	ReplaceCDS(result []schema.CDSData) error
+	CreateCDS(result []schema.CDSData) error
 	GetCDSActive(loc schema.Location) (float64, float64, float64, error)
 	DeleteCDSUnused(country string, timeBefore int64) error
 	ContinuousDataCDSConfirm(loc schema.Location, num int64, timeBefore int64) ([]schema.CDSScoreDataSet, error)
+	ConfirmPopulation(loc schema.Location, confirmData []schema.CDSScoreDataSet) (float64, error)
+	PreviousCDS(record schema.CDSData) (*schema.CDSData, error)
 }
 
-func (m *mongoDB) ReplaceCDS(result []schema.CDSData, country string) error {
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(country)]
-	if !ok {
-		return errors.New("no cds country availible")
-	}
+// ReplaceCDS saves the confirmed case records of any country. A record replaces the
+// one of the same country, state, county and report time.
+func (m *mongoDB) ReplaceCDS(result []schema.CDSData) error {
 	if len(result) <= 0 {
 		log.WithFields(log.Fields{"prefix": mongoLogPrefix}).Debug("no record to update")
 		return nil
 	}
 
+	collection := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection)
 	for _, v := range result {
-		filter := bson.M{"name": v.Name, "report_ts": v.ReportTime}
+		filter := bson.M{"country": v.Country, "state": v.State, "county": v.County, "report_ts": v.ReportTime}
 		replacement := bson.M{
 			"name":        v.Name,
 			"city":        v.City,
@@ -54,6 +54,7 @@ func (m *mongoDB) ReplaceCDS(result []schema.CDSData, country string) error {
 			"deaths":      v.Deaths,
 			"recovered":   v.Recovered,
 			"active":      v.Active,
+			"population":  v.Population,
 			"report_ts":   v.ReportTime,
 			"update_ts":   v.UpdateTime,
 			"report_date": v.ReportTimeDate,
@@ -64,29 +65,27 @@ func (m *mongoDB) ReplaceCDS(result []schema.CDSData, country string) error {
 			"tz":          v.Timezone,
 		}
 		opts := options.Replace().SetUpsert(true)
-		_, err := m.client.Database(m.database).Collection(collection).ReplaceOne(context.Background(), filter, replacement, opts)
+		_, err := collection.ReplaceOne(context.Background(), filter, replacement, opts)
 		if err != nil {
 			if errs, hasErr := err.(mongo.BulkWriteException); hasErr {
 				if 1 == len(errs.WriteErrors) && DuplicateKeyCode == errs.WriteErrors[0].Code {
 					log.WithField("prefix", mongoLogPrefix).Warnf("cds update with error: %s", err)
+					continue
 				}
 			}
+			return err
 		}
 	}
 	return nil
 }
 
-func (m *mongoDB) CreateCDS(result []schema.CDSData, country string) error {
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(country)]
-	if !ok {
-		return errors.New("no cds country availible")
-	}
+func (m *mongoDB) CreateCDS(result []schema.CDSData) error {
 	data := make([]interface{}, len(result))
 	for i, v := range result {
 		data[i] = v
 	}
 	opts := options.InsertMany().SetOrdered(false)
-	res, err := m.client.Database(m.database).Collection(collection).InsertMany(context.Background(), data, opts)
+	res, err := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection).InsertMany(context.Background(), data, opts)
 	if err != nil {
 		if errs, hasErr := err.(mongo.BulkWriteException); hasErr {
 			if 1 == len(errs.WriteErrors) && DuplicateKeyCode == errs.WriteErrors[0].Code {
@@ -101,12 +100,8 @@ func (m *mongoDB) CreateCDS(result []schema.CDSData, country string) error {
 	return nil
 }
 func (m *mongoDB) DeleteCDSUnused(country string, timeBefore int64) error {
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(country)]
-	if !ok {
-		return errors.New("no cds country availible")
-	}
-	filter := bson.M{"report_ts": bson.D{{"$lte", timeBefore}}}
-	res, err := m.client.Database(m.database).Collection(collection).DeleteMany(context.Background(), filter)
+	filter := bson.M{"country": country, "report_ts": bson.D{{"$lte", timeBefore}}}
+	res, err := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection).DeleteMany(context.Background(), filter)
 	if err != nil {
 		log.WithField("prefix", mongoLogPrefix).Warnf("cds delete unused record wih error: %s", err)
 		return err
@@ -114,47 +109,99 @@ func (m *mongoDB) DeleteCDSUnused(country string, timeBefore int64) error {
 	log.WithFields(log.Fields{"prefix": mongoLogPrefix, "records": res.DeletedCount}).Debug("DeleteCDSUnused delete data")
 	return nil
 }
-func (m mongoDB) GetCDSActive(loc schema.Location) (float64, float64, float64, error) {
-	log.WithFields(log.Fields{"prefix": mongoLogPrefix, "country": loc.Country, "state": loc.State, "county": loc.County}).Debug("GetCDSConfirm geo info")
+
+// PreviousCDS returns the latest record of the area of a record reported before it.
+// It returns nil if the area has no record before.
+func (m *mongoDB) PreviousCDS(record schema.CDSData) (*schema.CDSData, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
-	var results []schema.CDSData
-	var c *mongo.Collection
-	var cur *mongo.Cursor
-	switch loc.Country { //  Currently this function support only USA data
-	case schema.CdsTaiwan:
-		c = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)])
-		opts := options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(2)
-		filter := bson.M{}
-		curTW, err := c.Find(context.Background(), filter, opts)
-		if nil != err {
-			log.WithField("prefix", mongoLogPrefix).Errorf("CDS confirm data find  error: %s", err)
-			return 0, 0, 0, ErrConfirmDataFetch
+
+	filter := bson.M{
+		"country":   record.Country,
+		"state":     record.State,
+		"county":    record.County,
+		"report_ts": bson.M{"$lt": record.ReportTime},
+	}
+	opts := options.FindOne().SetSort(bson.M{"report_ts": -1})
+
+	var previous schema.CDSData
+	if err := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection).FindOne(ctx, filter, opts).Decode(&previous); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
 		}
-		cur = curTW
-	case schema.CdsIceland:
-		c = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsIceland)])
-		opts := options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(2)
-		filter := bson.M{}
-		curIceland, err := c.Find(context.Background(), filter, opts)
-		if nil != err {
-			log.WithField("prefix", mongoLogPrefix).Errorf("CDS confirm data find  error: %s", err)
-			return 0, 0, 0, ErrConfirmDataFetch
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// cdsLevelFilter returns the filter of the confirmed case records of a location at a boundary level.
+// It returns false if the location has no area at the level.
+func cdsLevelFilter(loc schema.Location, level string) (bson.M, bool) {
+	switch level {
+	case schema.CDSLevelCounty:
+		if loc.County == "" {
+			return nil, false
+		}
+		return bson.M{"level": level, "country": loc.Country, "state": loc.State, "county": loc.County}, true
+	case schema.CDSLevelState:
+		if loc.State == "" {
+			return nil, false
+		}
+		return bson.M{"level": level, "country": loc.Country, "state": loc.State, "county": ""}, true
+	case schema.CDSLevelCountry:
+		return bson.M{"level": level, "country": loc.Country, "state": "", "county": ""}, true
+	}
+	return nil, false
+}
+
+// cdsArea returns the boundary level and the filter of the confirmed case records of a location.
+// The most specific level which has records of the location is used, so that a country is
+// supported once the records of its level are saved.
+func (m *mongoDB) cdsArea(ctx context.Context, loc schema.Location) (string, bson.M, error) {
+	if loc.Country == "" {
+		return "", nil, ErrNoConfirmDataset
+	}
+
+	c := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection)
+	for _, level := range schema.CDSLevels {
+		filter, ok := cdsLevelFilter(loc, level)
+		if !ok {
+			continue
+		}
+
+		err := c.FindOne(ctx, filter).Err()
+		if err == nil {
+			return level, filter, nil
 		}
-		cur = curIceland
-	case schema.CdsUSA:
-		c = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsUSA)])
-		opts := options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(2)
-		filter := bson.M{"county": loc.County, "state": loc.State}
-		curUSA, err := c.Find(context.Background(), filter, opts)
-		if nil != err {
-			return 0, 0, 0, ErrConfirmDataFetch
+		if err != mongo.ErrNoDocuments {
+			log.WithField("prefix", mongoLogPrefix).Errorf("CDS confirm data find  error: %s", err)
+			return "", nil, ErrConfirmDataFetch
 		}
-		cur = curUSA
-	default:
-		return 0, 0, 0, ErrNoConfirmDataset
 	}
 
+	return "", nil, ErrNoConfirmDataset
+}
+
+func (m *mongoDB) GetCDSActive(loc schema.Location) (float64, float64, float64, error) {
+	log.WithFields(log.Fields{"prefix": mongoLogPrefix, "country": loc.Country, "state": loc.State, "county": loc.County}).Debug("GetCDSConfirm geo info")
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	var results []schema.CDSData
+
+	_, filter, err := m.cdsArea(ctx, loc)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	c := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection)
+	opts := options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(2)
+	cur, err := c.Find(ctx, filter, opts)
+	if nil != err {
+		log.WithField("prefix", mongoLogPrefix).Errorf("CDS confirm data find  error: %s", err)
+		return 0, 0, 0, ErrConfirmDataFetch
+	}
+	defer cur.Close(ctx)
+
 	for cur.Next(ctx) {
 		var result schema.CDSData
 		if errDecode := cur.Decode(&result); errDecode != nil {
@@ -187,42 +234,24 @@ func (m mongoDB) GetCDSActive(loc schema.Location) (float64, float64, float64, e
 	return 0, 0, 0, ErrInvalidConfirmDataset
 }
 
-func (m mongoDB) ContinuousDataCDSConfirm(loc schema.Location, windowSize int64, timeBefore int64) ([]schema.CDSScoreDataSet, error) {
+func (m *mongoDB) ContinuousDataCDSConfirm(loc schema.Location, windowSize int64, timeBefore int64) ([]schema.CDSScoreDataSet, error) {
 	log.WithFields(log.Fields{"prefix": mongoLogPrefix, "country": loc.Country, "lv1": loc.State, "lv2": loc.County}).Debug("ContinuousDataCDSConfirm geo info")
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
-	var col *mongo.Collection
-	var filter bson.M
-	var opts *options.FindOptions
-	switch loc.Country { //  Currently this function support only USA data
-	case schema.CdsTaiwan:
-		col = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)])
-		opts = options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(windowSize + 1)
-		filter = bson.M{}
-		if timeBefore > 0 {
-			filter = bson.M{"report_ts": bson.D{{"$lte", timeBefore}}}
-		}
-	case schema.CdsIceland:
-		col = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsIceland)])
-		opts = options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(windowSize + 1)
-		filter = bson.M{}
-		if timeBefore > 0 {
-			filter = bson.M{"report_ts": bson.D{{"$lte", timeBefore}}}
-		}
-	case schema.CdsUSA:
-		col = m.client.Database(m.database).Collection(schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsUSA)])
-		opts = options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(windowSize + 1)
-		filter = bson.M{"county": loc.County, "state": loc.State}
-		if timeBefore > 0 {
-			filter = bson.M{"county": loc.County, "state": loc.State, "report_ts": bson.D{{"$lte", timeBefore}}}
-		}
-	default:
-		return nil, ErrNoConfirmDataset
+	_, filter, err := m.cdsArea(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	if timeBefore > 0 {
+		filter["report_ts"] = bson.D{{"$lte", timeBefore}}
 	}
 
+	col := m.client.Database(m.database).Collection(schema.ConfirmCDSCollection)
+	opts := options.Find().SetSort(bson.M{"report_ts": -1}).SetLimit(windowSize + 1)
+
 	var results []schema.CDSScoreDataSet
-	cur, err := col.Find(context.Background(), filter, opts)
+	cur, err := col.Find(ctx, filter, opts)
 	if nil != err {
 		log.WithField("prefix", mongoLogPrefix).Errorf("%v: %s", ErrConfirmDataFetch, err)
 		return nil, ErrConfirmDataFetch
@@ -238,7 +267,7 @@ func (m mongoDB) ContinuousDataCDSConfirm(loc schema.Location, windowSize int64,
 		}
 		if len(now.Name) > 0 { // now data is valid
 			head := make([]schema.CDSScoreDataSet, 1)
-			head[0] = schema.CDSScoreDataSet{Name: now.Name, Cases: now.Cases - result.Cases}
+			head[0] = schema.CDSScoreDataSet{Name: now.Name, Cases: now.Cases - result.Cases, Population: now.Population, ReportTime: now.ReportTime}
 			results = append(head, results...)
 		}
 		now = result
@@ -250,3 +279,51 @@ func (m mongoDB) ContinuousDataCDSConfirm(loc schema.Location, windowSize int64,
 	cur.Close(ctx)
 	return results, nil
 }
+
+// ConfirmPopulation returns the population of the area where the confirmed cases of a
+// location are reported. The population reported with the confirmed cases is preferred.
+// Otherwise, it is summed up from the boundaries of the area.
+func (m *mongoDB) ConfirmPopulation(loc schema.Location, confirmData []schema.CDSScoreDataSet) (float64, error) {
+	for i := len(confirmData) - 1; i >= 0; i-- {
+		if confirmData[i].Population > 0 {
+			return confirmData[i].Population, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	level, _, err := m.cdsArea(ctx, loc)
+	if err != nil {
+		return 0, err
+	}
+
+	// sum up the boundaries of the area which the confirmed cases are reported by
+	filter := bson.M{"country": loc.Country}
+	if level != schema.CDSLevelCountry && loc.State != "" {
+		filter["state"] = loc.State
+	}
+	if level == schema.CDSLevelCounty {
+		filter["county"] = loc.County
+	}
+
+	cursor, err := m.client.Database(m.database).Collection(schema.BoundaryCollection).Aggregate(ctx, []bson.M{
+		{"$match": filter},
+		{"$group": bson.M{"_id": nil, "population": bson.M{"$sum": "$population"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Population float64 `bson:"population"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+
+	return result.Population, cursor.Err()
+}
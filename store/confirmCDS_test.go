@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -105,12 +106,8 @@ func (s *ConfirmCDSTestSuite) LoadMongoDBFixtures() error {
 	if data[0].Name != schema.CdsTaiwan {
 		return fmt.Errorf("not a expected data set")
 	}
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)]
-	if !ok {
-		s.T().Fatal("not a supported collection")
-	}
 	for i := 0; i < len(data); i++ {
-		s.testDatabase.Collection(collection).InsertOne(context.Background(), data[i])
+		s.testDatabase.Collection(schema.ConfirmCDSCollection).InsertOne(context.Background(), data[i])
 	}
 	return nil
 }
@@ -130,11 +127,11 @@ func (s *ConfirmCDSTestSuite) LoadConfirmFixtures(file string) ([]schema.CDSData
 }
 
 func (s *ConfirmCDSTestSuite) RemoveAllDocument() error {
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)]
-	if !ok {
-		s.T().Fatal("not a supported collection")
+	_, err := s.testDatabase.Collection(schema.ConfirmCDSCollection).DeleteMany(context.Background(), bson.M{})
+	if err != nil {
+		return err
 	}
-	_, err := s.testDatabase.Collection(collection).DeleteMany(context.Background(), bson.M{})
+	_, err = s.testDatabase.Collection(schema.ConfirmQuarantineCollection).DeleteMany(context.Background(), bson.M{})
 	if err != nil {
 		return err
 	}
@@ -142,9 +139,7 @@ func (s *ConfirmCDSTestSuite) RemoveAllDocument() error {
 }
 
 func (s *ConfirmCDSTestSuite) ExpectDocCount(country string, expectCount int64) {
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)]
-	s.True(ok)
-	count, err := s.testDatabase.Collection(collection).CountDocuments(context.Background(), bson.M{})
+	count, err := s.testDatabase.Collection(schema.ConfirmCDSCollection).CountDocuments(context.Background(), bson.M{"country": country})
 	s.NoError(err)
 	s.Equal(expectCount, count)
 }
@@ -196,18 +191,17 @@ func (s *ConfirmCDSTestSuite) TestCreateCDS() {
 	s.NoError(err)
 	s.Equal(numberOfConfirmTaiwan, len(data))
 	store := NewMongoStore(s.mongoClient, s.testDBName)
-	err = store.CreateCDS(data, schema.CdsTaiwan)
+	err = store.CreateCDS(data)
 	s.NoError(err)
 	// Test Duplicate
-	err = store.CreateCDS(data, schema.CdsTaiwan)
+	err = store.CreateCDS(data)
 	s.NoError(err)
 	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan)
 }
 
 func (s *ConfirmCDSTestSuite) TestReplaceCDS() {
 	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan)
-	collection, ok := schema.CDSCountyCollectionMatrix[schema.CDSCountryType(schema.CdsTaiwan)]
-	s.True(ok)
+	collection := schema.ConfirmCDSCollection
 	opts := options.Find().SetLimit(2)
 	filter := bson.M{}
 	ctx := context.Background()
@@ -228,7 +222,7 @@ func (s *ConfirmCDSTestSuite) TestReplaceCDS() {
 		originalCases[i] = results[i].Cases
 		results[i].Cases = replaceCases[i]
 	}
-	err = store.ReplaceCDS(results, schema.CdsTaiwan)
+	err = store.ReplaceCDS(results)
 	for i := 0; i < len(results); i++ {
 		filter = bson.M{"name": results[i].Name, "report_ts": results[i].ReportTime}
 		cur, err = s.testDatabase.Collection(collection).Find(ctx, filter)
@@ -239,7 +233,7 @@ func (s *ConfirmCDSTestSuite) TestReplaceCDS() {
 		s.False(cur.Next(ctx))
 		cur.Close(ctx)
 		queryReturn.Cases = originalCases[i]
-		store.ReplaceCDS([]schema.CDSData{queryReturn}, schema.CdsTaiwan)
+		store.ReplaceCDS([]schema.CDSData{queryReturn})
 	}
 	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan)
 }
@@ -263,6 +257,30 @@ func (s *ConfirmCDSTestSuite) TestGetCDSActive() {
 	s.Equal(s.ConfirmExpected.ExpectActiveNoDataSet.RateChangeRoundEven, math.RoundToEven(changeRate))
 }
 
+func (s *ConfirmCDSTestSuite) TestGetCDSActiveByCounty() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	err := store.ReplaceCDS([]schema.CDSData{
+		{Name: "Los Angeles County, California, United States", County: "Los Angeles County", State: "California",
+			Country: schema.CdsUSA, Level: schema.CDSLevelCounty, Active: 10, ReportTime: 1590364800},
+		{Name: "Los Angeles County, California, United States", County: "Los Angeles County", State: "California",
+			Country: schema.CdsUSA, Level: schema.CDSLevelCounty, Active: 15, ReportTime: 1590451200},
+	})
+	s.NoError(err)
+	s.ExpectDocCount(schema.CdsUSA, 2)
+	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan)
+
+	loc := schema.Location{AddressComponent: schema.AddressComponent{Country: schema.CdsUSA, State: "California", County: "Los Angeles County"}}
+	active, delta, _, err := store.GetCDSActive(loc)
+	s.NoError(err)
+	s.Equal(float64(15), active)
+	s.Equal(float64(5), delta)
+
+	// confirmed cases of the united states are not reported by state
+	loc = schema.Location{AddressComponent: schema.AddressComponent{Country: schema.CdsUSA, State: "California"}}
+	_, _, _, err = store.GetCDSActive(loc)
+	s.Equal(ErrNoConfirmDataset, err)
+}
+
 func (s *ConfirmCDSTestSuite) TestContinuousDataCDSConfirm() {
 	loc := schema.Location{AddressComponent: schema.AddressComponent{Country: schema.CdsTaiwan}}
 	store := NewMongoStore(s.mongoClient, s.testDBName)
@@ -289,6 +307,56 @@ func (s *ConfirmCDSTestSuite) TestDeleteCDSUnused() {
 	s.NoError(err)
 	s.ExpectDocCount(schema.CdsTaiwan, 0)
 }
+
+func (s *ConfirmCDSTestSuite) TestPreviousCDS() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	record := schema.CDSData{Country: schema.CdsTaiwan, ReportTime: 1590451200}
+	previous, err := store.PreviousCDS(record)
+	s.NoError(err)
+	s.Equal(int64(1590249600), previous.ReportTime)
+
+	record.Country = "Neverland"
+	previous, err = store.PreviousCDS(record)
+	s.NoError(err)
+	s.Nil(previous)
+}
+
+func (s *ConfirmCDSTestSuite) TestReviewConfirmQuarantine() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	record := schema.CDSData{Name: "Taipei City", County: "Taipei City", Country: schema.CdsTaiwan, Level: schema.CDSLevelCounty,
+		Cases: 10, Active: -1, ReportTime: 1590451200}
+	err := store.QuarantineCDS([]schema.ConfirmQuarantine{
+		{Source: "twcdc", Record: record, Reasons: []string{schema.QuarantineNegativeCount}, Status: schema.QuarantinePending},
+		{Source: "twcdc", Record: record, Reasons: []string{schema.QuarantineDuplicate}, Status: schema.QuarantinePending},
+	})
+	s.NoError(err)
+
+	pending, err := store.GetConfirmQuarantines(schema.QuarantinePending, 0)
+	s.NoError(err)
+	s.Len(pending, 2)
+
+	approved, err := store.ReviewConfirmQuarantine(pending[0].ID, true)
+	s.NoError(err)
+	s.Equal(schema.QuarantineApproved, approved.Status)
+	s.NotNil(approved.ReviewedAt)
+	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan+1)
+
+	_, err = store.ReviewConfirmQuarantine(pending[0].ID, false)
+	s.Equal(ErrQuarantineReviewed, err)
+
+	rejected, err := store.ReviewConfirmQuarantine(pending[1].ID, false)
+	s.NoError(err)
+	s.Equal(schema.QuarantineRejected, rejected.Status)
+	s.ExpectDocCount(schema.CdsTaiwan, numberOfConfirmTaiwan+1)
+
+	_, err = store.ReviewConfirmQuarantine(primitive.NewObjectID(), true)
+	s.Equal(ErrQuarantineNotFound, err)
+
+	pending, err = store.GetConfirmQuarantines(schema.QuarantinePending, 0)
+	s.NoError(err)
+	s.Empty(pending)
+}
+
 func TestConfirmTestSuite(t *testing.T) {
 	suite.Run(t, NewConfirmTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
 }
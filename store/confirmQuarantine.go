@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrQuarantineNotFound = fmt.Errorf("quarantined confirm record not found")
+	ErrQuarantineReviewed = fmt.Errorf("quarantined confirm record has been reviewed")
+)
+
+type ConfirmQuarantine interface {
+	QuarantineCDS(records []schema.ConfirmQuarantine) error
+	GetConfirmQuarantines(status string, limit int64) ([]schema.ConfirmQuarantine, error)
+	ReviewConfirmQuarantine(id primitive.ObjectID, approve bool) (*schema.ConfirmQuarantine, error)
+}
+
+// QuarantineCDS saves the confirmed case records which fail the validation for review. A record is
+// quarantined once for its source, area and report time. Re-quarantining a pending record refreshes
+// it, while a reviewed record keeps its review.
+func (m *mongoDB) QuarantineCDS(records []schema.ConfirmQuarantine) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.ConfirmQuarantineCollection)
+	for _, r := range records {
+		filter := bson.M{
+			"source":           r.Source,
+			"record.country":   r.Record.Country,
+			"record.state":     r.Record.State,
+			"record.county":    r.Record.County,
+			"record.report_ts": r.Record.ReportTime,
+		}
+
+		pending := bson.M{"status": schema.QuarantinePending}
+		for k, v := range filter {
+			pending[k] = v
+		}
+		result, err := c.UpdateOne(ctx, pending, bson.M{
+			"$set": bson.M{
+				"record":   r.Record,
+				"previous": r.Previous,
+				"reasons":  r.Reasons,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			continue
+		}
+
+		r.ID = primitive.NilObjectID
+		if _, err := c.UpdateOne(ctx, filter, bson.M{"$setOnInsert": r}, options.Update().SetUpsert(true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetConfirmQuarantines returns the quarantined records of a review status from the newest.
+// Records of any status are returned if the status is empty.
+func (m *mongoDB) GetConfirmQuarantines(status string, limit int64) ([]schema.ConfirmQuarantine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := m.client.Database(m.database).Collection(schema.ConfirmQuarantineCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := make([]schema.ConfirmQuarantine, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ReviewConfirmQuarantine approves or rejects a pending quarantined record. An approved record is
+// saved as the confirmed case data before it is marked approved, so it stays pending if the save fails.
+func (m *mongoDB) ReviewConfirmQuarantine(id primitive.ObjectID, approve bool) (*schema.ConfirmQuarantine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	status := schema.QuarantineRejected
+	if approve {
+		status = schema.QuarantineApproved
+	}
+
+	c := m.client.Database(m.database).Collection(schema.ConfirmQuarantineCollection)
+	var record schema.ConfirmQuarantine
+	if err := c.FindOne(ctx, bson.M{"_id": id}).Decode(&record); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuarantineNotFound
+		}
+		return nil, err
+	}
+	if record.Status != schema.QuarantinePending {
+		return nil, ErrQuarantineReviewed
+	}
+
+	if approve {
+		if err := m.ReplaceCDS([]schema.CDSData{record.Record}); err != nil {
+			return nil, err
+		}
+	}
+
+	err := c.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": schema.QuarantinePending},
+		bson.M{"$set": bson.M{"status": status, "reviewed_at": time.Now().UTC()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuarantineReviewed
+		}
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{"prefix": mongoLogPrefix, "id": id.Hex(), "status": status}).Info("review quarantined confirm record")
+	return &record, nil
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type ConfirmQuarantineTestSuite struct {
+	suite.Suite
+	connURI      string
+	testDBName   string
+	mongoClient  *mongo.Client
+	testDatabase *mongo.Database
+}
+
+func NewConfirmQuarantineTestSuite(connURI, dbName string) *ConfirmQuarantineTestSuite {
+	return &ConfirmQuarantineTestSuite{
+		connURI:    connURI,
+		testDBName: dbName,
+	}
+}
+
+var confirmQuarantineTestCollections = []string{
+	schema.ConfirmQuarantineCollection,
+	schema.ConfirmCDSCollection,
+}
+
+func (s *ConfirmQuarantineTestSuite) SetupSuite() {
+	if s.connURI == "" || s.testDBName == "" {
+		s.T().Fatal("invalid test suite configuration")
+	}
+
+	opts := options.Client().ApplyURI(s.connURI)
+	mongoClient, err := mongo.NewClient(opts)
+	if nil != err {
+		s.T().Fatalf("create mongo client with error: %s", err)
+	}
+
+	if err = mongoClient.Connect(context.Background()); nil != err {
+		s.T().Fatalf("connect mongo database with error: %s", err.Error())
+	}
+
+	s.mongoClient = mongoClient
+	s.testDatabase = mongoClient.Database(s.testDBName)
+}
+
+func (s *ConfirmQuarantineTestSuite) SetupTest() {
+	for _, c := range confirmQuarantineTestCollections {
+		if _, err := s.testDatabase.Collection(c).DeleteMany(context.Background(), bson.M{}); err != nil {
+			s.T().Fatal(err)
+		}
+	}
+}
+
+func (s *ConfirmQuarantineTestSuite) TearDownSuite() {
+	for _, c := range confirmQuarantineTestCollections {
+		if err := s.testDatabase.Collection(c).Drop(context.Background()); err != nil {
+			s.T().Fatal(err)
+		}
+	}
+}
+
+func quarantinedTaiwanRecord(active float64) schema.ConfirmQuarantine {
+	return schema.ConfirmQuarantine{
+		Source: "cds:Taiwan",
+		Record: schema.CDSData{Name: schema.CdsTaiwan, Country: schema.CdsTaiwan, Level: schema.CDSLevelCountry,
+			Cases: 410, Active: active, ReportTime: 1590451200},
+		Reasons:   []string{schema.QuarantineSharpDrop},
+		Status:    schema.QuarantinePending,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// TestQuarantineCDSUpsert tests a record is quarantined once for its source, area and report time
+func (s *ConfirmQuarantineTestSuite) TestQuarantineCDSUpsert() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	s.NoError(store.QuarantineCDS([]schema.ConfirmQuarantine{quarantinedTaiwanRecord(5)}))
+	s.NoError(store.QuarantineCDS([]schema.ConfirmQuarantine{quarantinedTaiwanRecord(6)}))
+
+	records, err := store.GetConfirmQuarantines("", 0)
+	s.NoError(err)
+	s.Len(records, 1)
+	s.Equal(float64(6), records[0].Record.Active)
+	s.Equal(schema.QuarantinePending, records[0].Status)
+
+	// a reviewed record keeps its review when it is quarantined again
+	_, err = store.ReviewConfirmQuarantine(records[0].ID, false)
+	s.NoError(err)
+	s.NoError(store.QuarantineCDS([]schema.ConfirmQuarantine{quarantinedTaiwanRecord(7)}))
+
+	records, err = store.GetConfirmQuarantines("", 0)
+	s.NoError(err)
+	s.Len(records, 1)
+	s.Equal(schema.QuarantineRejected, records[0].Status)
+	s.Equal(float64(6), records[0].Record.Active)
+
+	// a record of another report time is quarantined separately
+	another := quarantinedTaiwanRecord(5)
+	another.Record.ReportTime += 86400
+	s.NoError(store.QuarantineCDS([]schema.ConfirmQuarantine{another}))
+
+	records, err = store.GetConfirmQuarantines(schema.QuarantinePending, 0)
+	s.NoError(err)
+	s.Len(records, 1)
+	s.Equal(another.Record.ReportTime, records[0].Record.ReportTime)
+}
+
+func (s *ConfirmQuarantineTestSuite) TestReviewConfirmQuarantine() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	s.NoError(store.QuarantineCDS([]schema.ConfirmQuarantine{quarantinedTaiwanRecord(5)}))
+	records, err := store.GetConfirmQuarantines(schema.QuarantinePending, 0)
+	s.NoError(err)
+	s.Len(records, 1)
+
+	record, err := store.ReviewConfirmQuarantine(records[0].ID, true)
+	s.NoError(err)
+	s.Equal(schema.QuarantineApproved, record.Status)
+	s.NotNil(record.ReviewedAt)
+
+	var saved schema.CDSData
+	s.NoError(s.testDatabase.Collection(schema.ConfirmCDSCollection).FindOne(context.Background(), bson.M{
+		"country":   schema.CdsTaiwan,
+		"report_ts": records[0].Record.ReportTime,
+	}).Decode(&saved))
+	s.Equal(float64(5), saved.Active)
+
+	_, err = store.ReviewConfirmQuarantine(records[0].ID, false)
+	s.Equal(ErrQuarantineReviewed, err)
+
+	_, err = store.ReviewConfirmQuarantine(primitive.NewObjectID(), true)
+	s.Equal(ErrQuarantineNotFound, err)
+}
+
+func TestConfirmQuarantineTestSuite(t *testing.T) {
+	suite.Run(t, NewConfirmQuarantineTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
+}
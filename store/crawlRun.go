@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type CrawlRun interface {
+	AddCrawlRun(run schema.CrawlRun) error
+}
+
+// AddCrawlRun records a run of the crawler of a confirm source
+func (m *mongoDB) AddCrawlRun(run schema.CrawlRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	_, err := m.client.Database(m.database).Collection(schema.CrawlRunCollection).InsertOne(ctx, run)
+	return err
+}
@@ -30,12 +30,12 @@ type GoodBehaviorReport interface {
 	CreateBehavior(behavior schema.Behavior) (string, error)
 	GoodBehaviorSave(data *schema.BehaviorReportData) error
 	FindBehaviorsByIDs(ids []string) ([]schema.Behavior, error)
-	FindNearbyBehaviorDistribution(dist int, loc schema.Location, start, end int64) (map[string]int, error)
-	FindNearbyBehaviorReportTimes(dist int, loc schema.Location, start, end int64) (int, error)
+	FindNearbyBehaviorDistribution(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (map[string]float64, error)
+	FindNearbyBehaviorReportTimes(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (float64, error)
 	FindNearbyNonOfficialBehaviors(dist int, loc schema.Location) ([]schema.Behavior, error)
 	ListOfficialBehavior(string) ([]schema.Behavior, error)
 	ListCustomizedBehaviors() ([]schema.Behavior, error)
-	GetBehaviorCount(profileID string, loc *schema.Location, dist int, now time.Time) (int, int, error)
+	GetBehaviorCount(profileID string, loc *schema.Location, dist int, now time.Time, tz *time.Location) (int, int, error)
 }
 
 func (m *mongoDB) ListOfficialBehavior(lang string) ([]schema.Behavior, error) {
@@ -193,18 +193,27 @@ func (m *mongoDB) FindBehaviorsByIDs(ids []string) ([]schema.Behavior, error) {
 // | userB | [clean_hand] 		                         |
 //
 // behavior_distribution = {social_distancing: 2, clean_hand: 5, touch_face: 1}
-func (m *mongoDB) FindNearbyBehaviorDistribution(dist int, loc schema.Location, start, end int64) (map[string]int, error) {
+//
+// If halfLife is positive, each report is weighted by a weight which halves every halfLife
+// before the end of the time range.
+func (m *mongoDB) FindNearbyBehaviorDistribution(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (map[string]float64, error) {
+	return m.behaviorDistribution(aggStageGeoProximity(dist, loc), start, end, halfLife)
+}
+
+// behaviorDistribution returns the behavior distribution of the reports in the area matched by the given stage
+func (m *mongoDB) behaviorDistribution(area bson.M, start, end int64, halfLife time.Duration) (map[string]float64, error) {
 	c := m.client.Database(m.database).Collection(schema.BehaviorReportCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
 	pipeline := []bson.M{
-		aggStageGeoProximity(dist, loc),
+		area,
 		aggStageReportedBetween(start, end),
 		{
 			"$project": bson.M{
 				"profile_id":     1,
 				"account_number": 1,
+				"weight":         aggExprTimeDecayWeight(end, halfLife),
 				"behaviors": bson.M{
 					"$concatArrays": bson.A{
 						bson.M{"$ifNull": bson.A{"$official_behaviors", bson.A{}}},
@@ -224,7 +233,7 @@ func (m *mongoDB) FindNearbyBehaviorDistribution(dist int, loc schema.Location,
 			"$group": bson.M{
 				"_id": "$behaviors._id",
 				"count": bson.M{
-					"$sum": 1,
+					"$sum": "$weight",
 				},
 			},
 		},
@@ -235,10 +244,10 @@ func (m *mongoDB) FindNearbyBehaviorDistribution(dist int, loc schema.Location,
 		return nil, err
 	}
 	var aggItem struct {
-		BehaviorID string `bson:"_id"`
-		Count      int    `bson:"count"`
+		BehaviorID string  `bson:"_id"`
+		Count      float64 `bson:"count"`
 	}
-	result := make(map[string]int)
+	result := make(map[string]float64)
 	for cursor.Next(ctx) {
 		if err := cursor.Decode(&aggItem); err != nil {
 			return nil, err
@@ -253,16 +262,28 @@ func (m *mongoDB) FindNearbyBehaviorDistribution(dist int, loc schema.Location,
 //
 // Take the same case described in the above function FindNearbyBehaviorDistribution for example,
 // the result is 5.
-func (m *mongoDB) FindNearbyBehaviorReportTimes(dist int, loc schema.Location, start, end int64) (int, error) {
+//
+// If halfLife is positive, each report is weighted as it is in FindNearbyBehaviorDistribution.
+func (m *mongoDB) FindNearbyBehaviorReportTimes(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (float64, error) {
+	return m.behaviorReportTimes(aggStageGeoProximity(dist, loc), start, end, halfLife)
+}
+
+// behaviorReportTimes returns the number of behavior report times in the area matched by the given stage
+func (m *mongoDB) behaviorReportTimes(area bson.M, start, end int64, halfLife time.Duration) (float64, error) {
 	c := m.client.Database(m.database).Collection(schema.BehaviorReportCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
 	pipeline := []bson.M{
-		aggStageGeoProximity(dist, loc),
+		area,
 		aggStageReportedBetween(start, end),
 		{
-			"$count": "count",
+			"$group": bson.M{
+				"_id": nil,
+				"count": bson.M{
+					"$sum": aggExprTimeDecayWeight(end, halfLife),
+				},
+			},
 		},
 	}
 	cursor, err := c.Aggregate(ctx, pipeline)
@@ -275,7 +296,7 @@ func (m *mongoDB) FindNearbyBehaviorReportTimes(dist int, loc schema.Location, s
 	}
 
 	var result struct {
-		Count int `bson:"count"`
+		Count float64 `bson:"count"`
 	}
 	if err := cursor.Decode(&result); err != nil {
 		return 0, err
@@ -285,8 +306,17 @@ func (m *mongoDB) FindNearbyBehaviorReportTimes(dist int, loc schema.Location, s
 }
 
 // FindNearbyNonOfficialBehaviors returns non-official behaviors in the specified area.
+// Behaviors reported by too few users are guarded by the privacy guard, and noise is added if it is enabled.
 func (m *mongoDB) FindNearbyNonOfficialBehaviors(dist int, loc schema.Location) ([]schema.Behavior, error) {
-	distribution, err := m.FindNearbyBehaviorDistribution(dist, loc, 0, 9223372036854775807)
+	distribution, err := m.FindNearbyBehaviorDistribution(dist, loc, 0, 9223372036854775807, 0)
+	if err != nil {
+		return nil, err
+	}
+	reporters, err := m.nearbyReportingUserCount(schema.ReportTypeBehavior, dist, loc, 0, 9223372036854775807)
+	if err != nil {
+		return nil, err
+	}
+	distribution, err = m.guardDistribution(distribution, reporters, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -319,14 +349,14 @@ func (m *mongoDB) FindNearbyNonOfficialBehaviors(dist int, loc schema.Location)
 	return behaviors, nil
 }
 
-// GetBehaviorCount returns the number of reported behaviors for today and yesterday.
+// GetBehaviorCount returns the number of reported behaviors for today and yesterday in the given location.
 //
 // Either profileID of loc is required.
 // If profileID is provided, returned values are personal metrics.
 // Otherwise, if location is provided, returned values are community metrics.
 //
 // Either profileID of loc is required.
-func (m *mongoDB) GetBehaviorCount(profileID string, loc *schema.Location, dist int, now time.Time) (int, int, error) {
+func (m *mongoDB) GetBehaviorCount(profileID string, loc *schema.Location, dist int, now time.Time, tz *time.Location) (int, int, error) {
 	c := m.client.Database(m.database).Collection(schema.BehaviorReportCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -345,19 +375,14 @@ func (m *mongoDB) GetBehaviorCount(profileID string, loc *schema.Location, dist
 		return 0, 0, errors.New("either profile ID or location not provided")
 	}
 
-	yesterdayStartAt, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now)
+	yesterdayStartAt, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now, tz)
 
 	pipeline := []bson.M{
 		filter,
 		aggStageReportedBetween(yesterdayStartAt.Unix(), tomorrowStartAt.Unix()),
 		{
 			"$project": bson.M{
-				"day": bson.M{
-					"$dateToString": bson.M{
-						"format": "%Y-%m-%d",
-						"date":   bson.M{"$toDate": bson.M{"$multiply": bson.A{"$ts", 1000}}},
-					},
-				},
+				"day": aggExprLocalDate(todayStartAt),
 				"count": bson.M{
 					"$add": bson.A{
 						bson.M{"$size": bson.M{"$ifNull": bson.A{"$official_behaviors", bson.A{}}}},
@@ -397,8 +422,8 @@ func (m *mongoDB) GetBehaviorCount(profileID string, loc *schema.Location, dist
 	return result[today], result[yesterday], nil
 }
 
-func todayStartAt() int64 {
-	curTime := time.Now().UTC()
-	start := time.Date(curTime.Year(), curTime.Month(), curTime.Day(), 0, 0, 0, 0, time.UTC)
+func todayStartAt(tz *time.Location) int64 {
+	curTime := time.Now().In(tz)
+	start := time.Date(curTime.Year(), curTime.Month(), curTime.Day(), 0, 0, 0, 0, tz)
 	return start.Unix()
 }
@@ -151,9 +151,9 @@ func (s *BehaviorTestSuite) TestFindNearbyBehaviorDistribution() {
 		schema.Location{
 			Longitude: locationBitmark.Coordinates[0],
 			Latitude:  locationBitmark.Coordinates[1],
-		}, start, end)
+		}, start, end, 0)
 	s.NoError(err)
-	s.Equal(map[string]int{
+	s.Equal(map[string]float64{
 		"clean_hand":        2,
 		"social_distancing": 2,
 		"touch_face":        1,
@@ -171,33 +171,79 @@ func (s *BehaviorTestSuite) TestFindNearbyBehaviorReportTimes() {
 		schema.Location{
 			Longitude: locationBitmark.Coordinates[0],
 			Latitude:  locationBitmark.Coordinates[1],
-		}, start, end)
+		}, start, end, 0)
 	s.NoError(err)
-	s.Equal(3, count)
+	s.Equal(float64(3), count)
+}
+
+func (s *BehaviorTestSuite) TestFindNearbyBehaviorDistributionWithTimeDecay() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	start := time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC).UTC().Unix()
+	end := time.Date(2020, 5, 26, 21, 0, 0, 0, time.UTC).UTC().Unix()
+	distribution, err := store.FindNearbyBehaviorDistribution(
+		s.neighborhoodRadius,
+		schema.Location{
+			Longitude: locationBitmark.Coordinates[0],
+			Latitude:  locationBitmark.Coordinates[1],
+		}, start, end, 4*time.Hour)
+	s.NoError(err)
+	// reports in the morning weigh 0.125 and reports in the evening weigh 0.5
+	s.InDelta(0.625, distribution["clean_hand"], 1e-9)
+	s.InDelta(0.625, distribution["social_distancing"], 1e-9)
+	s.InDelta(0.125, distribution["touch_face"], 1e-9)
+	s.InDelta(0.125, distribution["new_behavior"], 1e-9)
+
+	count, err := store.FindNearbyBehaviorReportTimes(
+		s.neighborhoodRadius,
+		schema.Location{
+			Longitude: locationBitmark.Coordinates[0],
+			Latitude:  locationBitmark.Coordinates[1],
+		}, start, end, 4*time.Hour)
+	s.NoError(err)
+	s.InDelta(0.75, count, 1e-9)
 }
 
 func (s *BehaviorTestSuite) TestGetBehaviorCountForIndividual() {
 	store := NewMongoStore(s.mongoClient, s.testDBName)
 
 	now := time.Date(2020, 5, 25, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err := store.GetBehaviorCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err := store.GetBehaviorCount("userA", nil, 0, now, time.UTC)
 	s.NoError(err)
 	s.Equal(2, todayCount)
 	s.Equal(0, yesterdayCount)
 
 	now = time.Date(2020, 5, 26, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetBehaviorCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err = store.GetBehaviorCount("userA", nil, 0, now, time.UTC)
 	s.NoError(err)
 	s.Equal(4, todayCount)
 	s.Equal(2, yesterdayCount)
 
 	now = time.Date(2020, 5, 27, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetBehaviorCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err = store.GetBehaviorCount("userA", nil, 0, now, time.UTC)
 	s.NoError(err)
 	s.Equal(0, todayCount)
 	s.Equal(4, yesterdayCount)
 }
 
+func (s *BehaviorTestSuite) TestGetBehaviorCountForIndividualInLocalTime() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	tz := time.FixedZone("GMT+8", 8*60*60)
+
+	// the report in the evening of May 26 in UTC is reported on May 27 in GMT+8
+	now := time.Date(2020, 5, 26, 12, 0, 0, 0, time.UTC)
+	todayCount, yesterdayCount, err := store.GetBehaviorCount("userA", nil, 0, now, tz)
+	s.NoError(err)
+	s.Equal(2, todayCount)
+	s.Equal(2, yesterdayCount)
+
+	now = time.Date(2020, 5, 27, 0, 0, 0, 0, time.UTC)
+	todayCount, yesterdayCount, err = store.GetBehaviorCount("userA", nil, 0, now, tz)
+	s.NoError(err)
+	s.Equal(2, todayCount)
+	s.Equal(2, yesterdayCount)
+}
+
 func (s *BehaviorTestSuite) TestGetBehaviorCountForCommunity() {
 	store := NewMongoStore(s.mongoClient, s.testDBName)
 
@@ -207,19 +253,19 @@ func (s *BehaviorTestSuite) TestGetBehaviorCountForCommunity() {
 	}
 
 	now := time.Date(2020, 5, 25, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err := store.GetBehaviorCount("", loc, s.neighborhoodRadius, now)
+	todayCount, yesterdayCount, err := store.GetBehaviorCount("", loc, s.neighborhoodRadius, now, time.UTC)
 	s.NoError(err)
 	s.Equal(2, todayCount)
 	s.Equal(0, yesterdayCount)
 
 	now = time.Date(2020, 5, 26, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetBehaviorCount("", loc, s.neighborhoodRadius, now)
+	todayCount, yesterdayCount, err = store.GetBehaviorCount("", loc, s.neighborhoodRadius, now, time.UTC)
 	s.NoError(err)
 	s.Equal(6, todayCount)
 	s.Equal(2, yesterdayCount)
 
 	now = time.Date(2020, 5, 27, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetBehaviorCount("", loc, s.neighborhoodRadius, now)
+	todayCount, yesterdayCount, err = store.GetBehaviorCount("", loc, s.neighborhoodRadius, now, time.UTC)
 	s.NoError(err)
 	s.Equal(0, todayCount)
 	s.Equal(6, yesterdayCount)
@@ -236,7 +282,7 @@ func (s *BehaviorTestSuite) TestGetNearbyReportingBehaviorsUserCount() {
 			Longitude: locationBitmark.Coordinates[0],
 			Latitude:  locationBitmark.Coordinates[1],
 		},
-		now)
+		now, time.UTC)
 	s.NoError(err)
 	s.Equal(2, count)
 
@@ -247,7 +293,7 @@ func (s *BehaviorTestSuite) TestGetNearbyReportingBehaviorsUserCount() {
 		schema.Location{
 			Longitude: locationTaipeiTrainStation.Coordinates[0],
 			Latitude:  locationTaipeiTrainStation.Coordinates[1],
-		}, now)
+		}, now, time.UTC)
 	s.NoError(err)
 	s.Equal(1, count)
 }
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+const (
+	// maxHeatmapCells is the maximum number of cells returned by a heatmap query
+	maxHeatmapCells = 5000
+	// maxHeatmapPrefixes is the maximum number of geohash prefixes a heatmap query is made of
+	maxHeatmapPrefixes = 1024
+)
+
+type Heatmap interface {
+	FindReportedGeohashes(precision int, since int64) ([]string, error)
+	GetHeatmapCell(geohash string) (*schema.HeatmapCell, error)
+	UpdateHeatmapCell(cell schema.HeatmapCell) error
+	FindHeatmapCells(box utils.GeohashBox, precision int) ([]schema.HeatmapCell, bool, error)
+}
+
+// FindReportedGeohashes returns the geohashes of the given precision of the cells
+// where symptoms or behaviors are reported since the given time.
+func (m *mongoDB) FindReportedGeohashes(precision int, since int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	height, width := utils.GeohashCellSize(precision)
+
+	// a cell of a geohash is indexed by the numbers of cells from the south-west corner of the world
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"ts": bson.M{"$gte": since},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"lat": bson.M{
+						"$floor": bson.M{
+							"$divide": bson.A{bson.M{"$add": bson.A{bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 1}}, 90}}, height},
+						},
+					},
+					"lon": bson.M{
+						"$floor": bson.M{
+							"$divide": bson.A{bson.M{"$add": bson.A{bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 0}}, 180}}, width},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	geohashes := make(map[string]struct{})
+	for _, collection := range []string{schema.SymptomReportCollection, schema.BehaviorReportCollection} {
+		cursor, err := m.client.Database(m.database).Collection(collection).Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+
+		for cursor.Next(ctx) {
+			var aggItem struct {
+				ID struct {
+					Latitude  float64 `bson:"lat"`
+					Longitude float64 `bson:"lon"`
+				} `bson:"_id"`
+			}
+			if err := cursor.Decode(&aggItem); err != nil {
+				cursor.Close(ctx)
+				return nil, err
+			}
+
+			if math.IsNaN(aggItem.ID.Latitude) || math.IsNaN(aggItem.ID.Longitude) {
+				continue
+			}
+
+			lat := -90 + (aggItem.ID.Latitude+0.5)*height
+			lon := -180 + (aggItem.ID.Longitude+0.5)*width
+			geohashes[utils.GeohashEncode(lat, lon, precision)] = struct{}{}
+		}
+		cursor.Close(ctx)
+	}
+
+	result := make([]string, 0, len(geohashes))
+	for geohash := range geohashes {
+		result = append(result, geohash)
+	}
+
+	return result, nil
+}
+
+// GetHeatmapCell returns the cell of a geohash. It returns nil if the cell is not calculated.
+func (m *mongoDB) GetHeatmapCell(geohash string) (*schema.HeatmapCell, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.HeatmapCellCollection)
+
+	var cell schema.HeatmapCell
+	if err := c.FindOne(ctx, bson.M{"_id": geohash}).Decode(&cell); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &cell, nil
+}
+
+// UpdateHeatmapCell saves the score of a cell
+func (m *mongoDB) UpdateHeatmapCell(cell schema.HeatmapCell) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.HeatmapCellCollection)
+	_, err := c.ReplaceOne(ctx, bson.M{"_id": cell.Geohash}, cell, options.Replace().SetUpsert(true))
+	return err
+}
+
+// FindHeatmapCells returns the cells in the cells of the given precision which overlap the box, along with
+// whether the cells are truncated to the maximum number of cells. A coarser precision is queried if the box
+// overlaps too many cells of the precision.
+func (m *mongoDB) FindHeatmapCells(box utils.GeohashBox, precision int) ([]schema.HeatmapCell, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	prefixes := utils.GeohashesInBox(box, precision)
+	for len(prefixes) > maxHeatmapPrefixes && precision > 1 {
+		precision--
+		prefixes = utils.GeohashesInBox(box, precision)
+	}
+
+	// the geohashes of a prefix are ranged from the prefix to the prefix followed by a
+	// character greater than any character of geohashes
+	ranges := make(bson.A, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		ranges = append(ranges, bson.M{
+			"_id": bson.M{
+				"$gte": prefix,
+				"$lt":  prefix + "{",
+			},
+		})
+	}
+
+	c := m.client.Database(m.database).Collection(schema.HeatmapCellCollection)
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(maxHeatmapCells + 1)
+	cursor, err := c.Find(ctx, bson.M{"$or": ranges}, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	defer cursor.Close(ctx)
+
+	cells := make([]schema.HeatmapCell, 0)
+	for cursor.Next(ctx) {
+		var cell schema.HeatmapCell
+		if err := cursor.Decode(&cell); err != nil {
+			return nil, false, err
+		}
+		cells = append(cells, cell)
+	}
+
+	if len(cells) > maxHeatmapCells {
+		return cells[:maxHeatmapCells], true, nil
+	}
+	return cells, false, nil
+}
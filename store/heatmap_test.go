@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+type HeatmapTestSuite struct {
+	suite.Suite
+	connURI      string
+	testDBName   string
+	mongoClient  *mongo.Client
+	testDatabase *mongo.Database
+}
+
+func NewHeatmapTestSuite(connURI, dbName string) *HeatmapTestSuite {
+	return &HeatmapTestSuite{
+		connURI:    connURI,
+		testDBName: dbName,
+	}
+}
+
+func (s *HeatmapTestSuite) SetupSuite() {
+	if s.connURI == "" || s.testDBName == "" {
+		s.T().Fatal("invalid test suite configuration")
+	}
+
+	opts := options.Client().ApplyURI(s.connURI)
+	mongoClient, err := mongo.NewClient(opts)
+	if nil != err {
+		s.T().Fatalf("create mongo client with error: %s", err)
+	}
+
+	if err = mongoClient.Connect(context.Background()); nil != err {
+		s.T().Fatalf("connect mongo database with error: %s", err.Error())
+	}
+
+	s.mongoClient = mongoClient
+	s.testDatabase = mongoClient.Database(s.testDBName)
+}
+
+func (s *HeatmapTestSuite) SetupTest() {
+	if _, err := s.testDatabase.Collection(schema.HeatmapCellCollection).DeleteMany(context.Background(), bson.M{}); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *HeatmapTestSuite) TearDownSuite() {
+	if err := s.testDatabase.Collection(schema.HeatmapCellCollection).Drop(context.Background()); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *HeatmapTestSuite) insertCells(geohashes ...string) {
+	cells := make([]interface{}, 0, len(geohashes))
+	for _, geohash := range geohashes {
+		box, _ := utils.GeohashDecode(geohash)
+		lat, lon := box.Center()
+		cells = append(cells, schema.HeatmapCell{
+			Geohash:   geohash,
+			Center:    schema.GeoJSON{Type: "Point", Coordinates: []float64{lon, lat}},
+			Score:     50,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+
+	if _, err := s.testDatabase.Collection(schema.HeatmapCellCollection).InsertMany(context.Background(), cells); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func heatmapCellGeohashes(cells []schema.HeatmapCell) []string {
+	geohashes := make([]string, 0, len(cells))
+	for _, c := range cells {
+		geohashes = append(geohashes, c.Geohash)
+	}
+	return geohashes
+}
+
+func (s *HeatmapTestSuite) TestFindHeatmapCells() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	// Taipei, Denmark and New York
+	s.insertCells("wsqqqm", "u4pruy", "dr5reg")
+
+	// a box larger than a hemisphere
+	world := utils.GeohashBox{MinLatitude: -90, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180}
+	cells, truncated, err := store.FindHeatmapCells(world, 1)
+	s.NoError(err)
+	s.False(truncated)
+	s.ElementsMatch([]string{"wsqqqm", "u4pruy", "dr5reg"}, heatmapCellGeohashes(cells))
+
+	// a box across the edge of the cell in Taipei
+	box, _ := utils.GeohashDecode("wsqqqm")
+	cells, truncated, err = store.FindHeatmapCells(utils.GeohashBox{
+		MinLatitude:  box.MinLatitude - 0.001,
+		MinLongitude: box.MinLongitude - 0.001,
+		MaxLatitude:  box.MinLatitude + 0.001,
+		MaxLongitude: box.MinLongitude + 0.001,
+	}, schema.HeatmapCellPrecision)
+	s.NoError(err)
+	s.False(truncated)
+	s.Equal([]string{"wsqqqm"}, heatmapCellGeohashes(cells))
+
+	// a box of the eastern hemisphere only
+	cells, _, err = store.FindHeatmapCells(utils.GeohashBox{MinLatitude: -90, MinLongitude: 0, MaxLatitude: 90, MaxLongitude: 180}, 2)
+	s.NoError(err)
+	s.ElementsMatch([]string{"wsqqqm", "u4pruy"}, heatmapCellGeohashes(cells))
+}
+
+func (s *HeatmapTestSuite) TestFindHeatmapCellsTruncated() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	base32 := strings.Split("0123456789bcdefghjkmnpqrstuvwxyz", "")
+	geohashes := make([]string, 0, maxHeatmapCells+1)
+	for _, a := range base32 {
+		for _, b := range base32 {
+			for _, c := range base32 {
+				if len(geohashes) <= maxHeatmapCells {
+					geohashes = append(geohashes, "wsq"+a+b+c)
+				}
+			}
+		}
+	}
+	s.insertCells(geohashes...)
+
+	box, _ := utils.GeohashDecode("wsq")
+	cells, truncated, err := store.FindHeatmapCells(box, 3)
+	s.NoError(err)
+	s.True(truncated)
+	s.Len(cells, maxHeatmapCells)
+}
+
+func TestHeatmapTestSuite(t *testing.T) {
+	suite.Run(t, NewHeatmapTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
+}
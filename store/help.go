@@ -5,8 +5,8 @@ import (
 
 	"github.com/lib/pq"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 )
 
 var (
@@ -39,7 +39,7 @@ func (s *AutonomyStore) RequestHelp(accountNumber, subject, needs, meetingPlace,
 func (s *AutonomyStore) ListHelps(accountNumber string, latitude, longitude float64, count int64) ([]schema.HelpRequest, error) {
 	helps := []schema.HelpRequest{}
 
-	accounts, err := s.mongo.NearestDistance(consts.CORHORT_DISTANCE_RANGE, schema.Location{
+	accounts, err := s.mongo.NearestDistance(score.GetNeighborhood().CohortRadius, schema.Location{
 		Latitude:  latitude,
 		Longitude: longitude,
 	})
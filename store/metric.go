@@ -4,12 +4,14 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/bitmark-inc/autonomy-api/consts"
 	"github.com/bitmark-inc/autonomy-api/geo"
 	"github.com/bitmark-inc/autonomy-api/schema"
 	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/utils"
 )
 
 const (
@@ -17,58 +19,158 @@ const (
 )
 
 type Metric interface {
-	CollectRawMetrics(location schema.Location) (*schema.Metric, error)
-	SyncAccountMetrics(accountNumber string, coefficient *schema.ScoreCoefficient, location schema.Location) (*schema.Metric, error)
+	CollectRawMetrics(location schema.Location, window schema.ScoreWindow, tz *time.Location) (*schema.Metric, error)
+	SyncAccountMetrics(accountNumber string, coefficient *schema.ScoreCoefficient, location schema.Location, tz *time.Location) (*schema.Metric, error)
 	SyncAccountPOIMetrics(accountNumber string, coefficient *schema.ScoreCoefficient, poiID primitive.ObjectID) (*schema.Metric, error)
 	SyncPOIMetrics(poiID primitive.ObjectID, location schema.Location) (*schema.Metric, error)
 }
 
 // CollectRawMetrics will gather data from various of sources that is required to
-// calculate an autonomy score
-func (m *mongoDB) CollectRawMetrics(location schema.Location) (*schema.Metric, error) {
-	now := time.Now().UTC()
-	todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// calculate an autonomy score. Symptoms and behaviors are collected by the given window,
+// where days start at the midnight of the given location.
+func (m *mongoDB) CollectRawMetrics(location schema.Location, window schema.ScoreWindow, tz *time.Location) (*schema.Metric, error) {
+	now := time.Now().In(tz)
+	current, _ := score.ReportPeriods(window, now)
 
-	yesterdayStartAtUnix := todayStartAt.AddDate(0, 0, -1).Unix()
-	todayStartAtUnix := todayStartAt.Unix()
-	tomorrowStartAtUnix := todayStartAt.AddDate(0, 0, 1).Unix()
+	radius, reporters, reports, err := m.neighborhoodRadius(location, current.Start.Unix(), current.End.Unix())
+	if err != nil {
+		return nil, err
+	}
 
-	behaviorDistrToday, err := m.FindNearbyBehaviorDistribution(consts.NEARBY_DISTANCE_RANGE, location, todayStartAtUnix, tomorrowStartAtUnix)
+	metric, err := m.collectReportMetrics(aggStageGeoProximity(radius, location), window, now)
 	if err != nil {
 		return nil, err
 	}
-	behaviorDistrYesterday, err := m.FindNearbyBehaviorDistribution(consts.NEARBY_DISTANCE_RANGE, location, yesterdayStartAtUnix, todayStartAtUnix)
+
+	if location.Country == "" {
+		log.Info("fetch poi geo info from external service")
+		var err error
+		location, err = geo.PoliticalGeoInfo(location)
+		if err != nil {
+			log.WithError(err).WithField("location", location).Error("fail to fetch geo info")
+			return nil, err
+		}
+	}
+
+	if err := m.collectConfirmMetrics(location, now, metric); err != nil {
+		return nil, err
+	}
+
+	m.collectAirQualityMetrics(location, metric)
+
+	metric.Confidence.Reporters = reporters
+	metric.Confidence.Reports = reports
+	metric.Radius = radius
+
+	return metric, nil
+}
+
+// collectReportMetrics gathers the symptoms and behaviors reported in the area matched by the given stage.
+// The metric is flagged suppressed if any community aggregate is reported by too few users.
+func (m *mongoDB) collectReportMetrics(area bson.M, window schema.ScoreWindow, now time.Time) (*schema.Metric, error) {
+	todayStartAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	current, previous := score.ReportPeriods(window, now)
+	currentStartAtUnix, currentEndAtUnix := current.Start.Unix(), current.End.Unix()
+	previousStartAtUnix, previousEndAtUnix := previous.Start.Unix(), previous.End.Unix()
+	halfLife := window.HalfLife()
+
+	behaviorDistrToday, err := m.behaviorDistribution(area, currentStartAtUnix, currentEndAtUnix, halfLife)
+	if err != nil {
+		return nil, err
+	}
+	behaviorDistrYesterday, err := m.behaviorDistribution(area, previousStartAtUnix, previousEndAtUnix, halfLife)
 	if err != nil {
 		return nil, err
 	}
-	behaviorReportTimes, err := m.FindNearbyBehaviorReportTimes(consts.NEARBY_DISTANCE_RANGE, location, todayStartAtUnix, tomorrowStartAtUnix)
+	behaviorReportTimes, err := m.behaviorReportTimes(area, currentStartAtUnix, currentEndAtUnix, halfLife)
 	if err != nil {
 		return nil, err
 	}
 
-	symptomDistToday, err := m.FindNearbySymptomDistribution(consts.NEARBY_DISTANCE_RANGE, location, todayStartAtUnix, tomorrowStartAtUnix)
+	symptomDistToday, err := m.symptomDistribution(area, currentStartAtUnix, currentEndAtUnix, halfLife)
 	if err != nil {
 		return nil, err
 	}
-	symptomDistYesterday, err := m.FindNearbySymptomDistribution(consts.NEARBY_DISTANCE_RANGE, location, yesterdayStartAtUnix, todayStartAtUnix)
+	symptomDistYesterday, err := m.symptomDistribution(area, previousStartAtUnix, previousEndAtUnix, halfLife)
 	if err != nil {
 		return nil, err
 	}
-	symptomUserCount, err := m.GetNearbyReportingUserCount(schema.ReportTypeSymptom, consts.NEARBY_DISTANCE_RANGE, location, now)
+	// the daily distributions of the baseline days and today
+	baselineDays := score.GetSpikeDetector().BaselineDays
+	symptomDistDaily, err := m.dailySymptomDistributions(area, todayStartAt.AddDate(0, 0, -baselineDays).Unix(), baselineDays+1)
 	if err != nil {
 		return nil, err
 	}
-
-	if location.Country == "" {
-		log.Info("fetch poi geo info from external service")
-		var err error
-		location, err = geo.PoliticalGeoInfo(location)
+	symptomUserCount, err := m.reportingUserCount(schema.ReportTypeSymptom, area, currentStartAtUnix, currentEndAtUnix)
+	if err != nil {
+		return nil, err
+	}
+	symptomUserCountYesterday, err := m.reportingUserCount(schema.ReportTypeSymptom, area, previousStartAtUnix, previousEndAtUnix)
+	if err != nil {
+		return nil, err
+	}
+	// the symptom counts are decayed in the rolling window, and so are the people they are counted out of
+	symptomTotalPeople := float64(symptomUserCount)
+	if halfLife > 0 {
+		symptomTotalPeople, err = m.weightedReportingUserCount(schema.ReportTypeSymptom, area, currentStartAtUnix, currentEndAtUnix, halfLife)
 		if err != nil {
-			log.WithError(err).WithField("location", location).Error("fail to fetch geo info")
 			return nil, err
 		}
 	}
+	behaviorUserCount, err := m.reportingUserCount(schema.ReportTypeBehavior, area, currentStartAtUnix, currentEndAtUnix)
+	if err != nil {
+		return nil, err
+	}
+	behaviorUserCountYesterday, err := m.reportingUserCount(schema.ReportTypeBehavior, area, previousStartAtUnix, previousEndAtUnix)
+	if err != nil {
+		return nil, err
+	}
+
+	// the daily distributions are released as symptom spikes, so they are guarded by the reporters of each day
+	symptomUserCountDaily, err := m.dailySymptomReporters(area, todayStartAt.AddDate(0, 0, -baselineDays).Unix(), baselineDays+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// the scores are calculated from the raw aggregates, while the community aggregates
+	// reported by too few users are guarded when they are responded
+	guard := GetPrivacyGuard()
+	_, symptomSuppressed := guard.Distribution(symptomDistToday, symptomUserCount)
+	_, symptomSuppressedYesterday := guard.Distribution(symptomDistYesterday, symptomUserCountYesterday)
+	_, behaviorSuppressed := guard.Distribution(behaviorDistrToday, behaviorUserCount)
+	_, behaviorSuppressedYesterday := guard.Distribution(behaviorDistrYesterday, behaviorUserCountYesterday)
+	suppressed := symptomSuppressed || symptomSuppressedYesterday || behaviorSuppressed || behaviorSuppressedYesterday
+	for i := range symptomDistDaily {
+		symptomDistDaily[i], _ = guard.Distribution(symptomDistDaily[i], symptomUserCountDaily[i])
+	}
 
+	return &schema.Metric{
+		Suppressed: suppressed,
+		Details: schema.Details{
+			Symptoms: schema.SymptomDetail{
+				TotalPeople: symptomTotalPeople,
+				TodayData: schema.NearestSymptomData{
+					WeightDistribution: symptomDistToday,
+				},
+				YesterdayData: schema.NearestSymptomData{
+					WeightDistribution: symptomDistYesterday,
+				},
+				BaselineData:  symptomDistDaily[:baselineDays],
+				LatestDayData: symptomDistDaily[baselineDays],
+			},
+			Behaviors: schema.BehaviorDetail{
+				ReportTimes:           behaviorReportTimes,
+				TodayDistribution:     behaviorDistrToday,
+				YesterdayDistribution: behaviorDistrYesterday,
+			},
+		},
+	}, nil
+}
+
+// collectConfirmMetrics gathers the confirmed case data of the political area of a location into a metric.
+// The data is left empty if it is not available for the area.
+func (m *mongoDB) collectConfirmMetrics(location schema.Location, now time.Time, metric *schema.Metric) error {
 	// Processing confirmed case data
 	activeCount, activeDiff, activeDiffPercent, err := m.GetCDSActive(location)
 	if err == ErrNoConfirmDataset || err == ErrInvalidConfirmDataset || err == ErrPoliticalTypeGeoInfo {
@@ -82,7 +184,7 @@ func (m *mongoDB) CollectRawMetrics(location schema.Location) (*schema.Metric, e
 			"prefix": mongoLogPrefix,
 			"error":  err,
 		}).Error("confirm info")
-		return nil, err
+		return err
 	} else {
 		log.WithFields(log.Fields{"prefix": mongoLogPrefix, "activeCount": activeCount, "activeDiff": activeDiff, "activeDiffPercent": activeDiffPercent}).Debug("confirm info")
 	}
@@ -101,38 +203,72 @@ func (m *mongoDB) CollectRawMetrics(location schema.Location) (*schema.Metric, e
 			"prefix": mongoLogPrefix,
 			"error":  err,
 		}).Error("continuous confirm info")
-		return nil, err
+		return err
 	} else {
 		log.WithFields(log.Fields{"prefix": mongoLogPrefix, "activeCount": activeCount, "activeDiff": activeDiff, "activeDiffPercent": activeDiffPercent}).Debug("confirm info")
 	}
 
-	return &schema.Metric{
-		ConfirmedCount: activeCount,
-		ConfirmedDelta: activeDiffPercent,
-		Details: schema.Details{
-			Confirm: schema.ConfirmDetail{
-				ContinuousData: confirmData,
-			},
-			Symptoms: schema.SymptomDetail{
-				TotalPeople: float64(symptomUserCount),
-				TodayData: schema.NearestSymptomData{
-					WeightDistribution: symptomDistToday,
-				},
-				YesterdayData: schema.NearestSymptomData{
-					WeightDistribution: symptomDistYesterday,
-				},
-			},
-			Behaviors: schema.BehaviorDetail{
-				ReportTimes:           behaviorReportTimes,
-				TodayDistribution:     behaviorDistrToday,
-				YesterdayDistribution: behaviorDistrYesterday,
-			},
-		},
-	}, nil
+	var population float64
+	if len(confirmData) > 0 && score.GetConfirmMode() == score.ConfirmModePer100K {
+		population, err = m.ConfirmPopulation(location, confirmData)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"prefix":   mongoLogPrefix,
+				"location": location,
+				"err":      err,
+			}).Warn("collect confirm population")
+		}
+	}
+
+	confirmDataAge := -1
+	if len(confirmData) > 0 {
+		if reportTime := confirmData[len(confirmData)-1].ReportTime; reportTime > 0 {
+			confirmDataAge = int(now.Sub(time.Unix(reportTime, 0)).Hours() / 24)
+		}
+	}
+
+	metric.ConfirmedCount = activeCount
+	metric.ConfirmedDelta = activeDiffPercent
+	metric.Confidence.ConfirmDataAge = confirmDataAge
+	metric.Details.Confirm = schema.ConfirmDetail{
+		ContinuousData: confirmData,
+		Population:     population,
+	}
+
+	return nil
 }
 
-func (m *mongoDB) SyncAccountMetrics(accountNumber string, coefficient *schema.ScoreCoefficient, location schema.Location) (*schema.Metric, error) {
-	rawMetrics, err := m.CollectRawMetrics(location)
+// neighborhoodRadius returns the narrowest radius of the neighborhood where at least the minimum
+// number of users have reported symptoms or behaviors within the specified time range, along with
+// the numbers of reporters and reports. The widest radius is used if none of the radii has enough reporters.
+func (m *mongoDB) neighborhoodRadius(location schema.Location, start, end int64) (int, int, int, error) {
+	n := score.GetNeighborhood()
+	radii := n.Radii()
+
+	for i, radius := range radii {
+		reporters, reports, err := m.nearbyReportActivity(radius, location, start, end)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		if reporters >= n.MinReporters || i == len(radii)-1 {
+			return radius, reporters, reports, nil
+		}
+
+		log.WithFields(log.Fields{
+			"prefix":    mongoLogPrefix,
+			"location":  location,
+			"radius":    radius,
+			"reporters": reporters,
+		}).Debug("widen neighborhood radius")
+	}
+
+	return 0, 0, 0, nil
+}
+
+func (m *mongoDB) SyncAccountMetrics(accountNumber string, coefficient *schema.ScoreCoefficient, location schema.Location, tz *time.Location) (*schema.Metric, error) {
+	formula := score.AccountFormula(accountNumber)
+	rawMetrics, err := m.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"prefix":         mongoLogPrefix,
@@ -148,11 +284,12 @@ func (m *mongoDB) SyncAccountMetrics(accountNumber string, coefficient *schema.S
 		"raw_metrics":    rawMetrics,
 	}).Debug("collect raw metrics")
 
-	metric := score.CalculateMetric(*rawMetrics, coefficient)
+	metric := formula.CalculateMetric(*rawMetrics, coefficient)
 
 	if err := m.UpdateProfileMetric(accountNumber, metric); err != nil {
 		return nil, err
 	}
+	m.addMetricHistory(schema.NewMetricHistory(accountNumber, nil, metric))
 
 	return &metric, nil
 }
@@ -184,7 +321,10 @@ func (m *mongoDB) SyncAccountPOIMetrics(accountNumber string, coefficient *schem
 				},
 			}
 
-			rawMetrics, err := m.CollectRawMetrics(location)
+			formula := score.AccountFormula(accountNumber)
+			// days of a POI start at its local midnight, as the worker refreshes it, whoever follows it
+			tz := utils.GetLocalLocation("", location.Longitude)
+			rawMetrics, err := m.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
 			if err != nil {
 				log.WithFields(log.Fields{
 					"prefix":         mongoLogPrefix,
@@ -200,11 +340,12 @@ func (m *mongoDB) SyncAccountPOIMetrics(accountNumber string, coefficient *schem
 				"raw_metrics":    rawMetrics,
 			}).Debug("collect raw metrics")
 
-			metric := score.CalculateMetric(*rawMetrics, coefficient)
+			metric := formula.CalculateMetric(*rawMetrics, coefficient)
 
 			if err := m.UpdateProfilePOIMetric(accountNumber, poiID, metric); err != nil {
 				return nil, err
 			}
+			m.addMetricHistory(schema.NewMetricHistory(accountNumber, &poiID, metric))
 			return &metric, nil
 		}
 	}
@@ -213,19 +354,19 @@ func (m *mongoDB) SyncAccountPOIMetrics(accountNumber string, coefficient *schem
 }
 
 func (m *mongoDB) SyncPOIMetrics(poiID primitive.ObjectID, location schema.Location) (*schema.Metric, error) {
-	rawMetrics, err := m.CollectRawMetrics(location)
+	formula := score.DefaultFormula()
+	tz := utils.GetLocalLocation("", location.Longitude)
+	rawMetrics, err := m.CollectRawMetrics(location, score.FormulaScoreWindow(formula.Version()), tz)
 	if err != nil {
 		return nil, err
 	}
 
-	metric := score.CalculateMetric(*rawMetrics, nil)
-	if err != nil {
-		return nil, err
-	}
+	metric := formula.CalculateMetric(*rawMetrics, nil)
 
 	if err := m.UpdatePOIMetric(poiID, metric); err != nil {
 		return nil, err
 	}
+	m.addMetricHistory(schema.NewMetricHistory("", &poiID, metric))
 
 	return &metric, nil
 }
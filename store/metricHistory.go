@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+var (
+	ErrUnknownGranularity = fmt.Errorf("unknown granularity")
+)
+
+// metricHistoryDateFormats maps a granularity to the date format which groups snapshots
+var metricHistoryDateFormats = map[schema.MetricHistoryGranularity]struct {
+	aggFormat string
+	layout    string
+}{
+	schema.MetricHistoryGranularityHour: {"%Y-%m-%dT%H", "2006-01-02T15"},
+	schema.MetricHistoryGranularityDay:  {"%Y-%m-%d", "2006-01-02"},
+}
+
+type MetricHistory interface {
+	AddMetricHistory(history schema.MetricHistory) error
+	GetAccountMetricHistory(accountNumber string, from, to time.Time, granularity schema.MetricHistoryGranularity, tz *time.Location) ([]schema.MetricHistoryPoint, error)
+	GetAccountPOIMetricHistory(accountNumber string, poiID primitive.ObjectID, from, to time.Time, granularity schema.MetricHistoryGranularity, tz *time.Location) ([]schema.MetricHistoryPoint, error)
+}
+
+// AddMetricHistory saves a snapshot of a metric. Snapshots are only taken when
+// a metric is refreshed from collected data, not when its score is recalculated.
+func (m *mongoDB) AddMetricHistory(history schema.MetricHistory) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.MetricHistoryCollection)
+	_, err := c.InsertOne(ctx, history)
+	return err
+}
+
+// addMetricHistory saves a snapshot of a refreshed metric. Failing to save a snapshot
+// is only logged since it should not fail the refresh of a metric.
+func (m *mongoDB) addMetricHistory(history schema.MetricHistory) {
+	if err := m.AddMetricHistory(history); err != nil {
+		log.WithFields(log.Fields{
+			"prefix":         mongoLogPrefix,
+			"account_number": history.AccountNumber,
+			"poi_id":         history.POIID,
+			"error":          err,
+		}).Error("add metric history")
+	}
+}
+
+// GetAccountMetricHistory returns the history of the metric of an account's current location
+func (m *mongoDB) GetAccountMetricHistory(accountNumber string, from, to time.Time, granularity schema.MetricHistoryGranularity, tz *time.Location) ([]schema.MetricHistoryPoint, error) {
+	return m.getMetricHistory(bson.M{
+		"account_number": accountNumber,
+		"poi_id":         bson.M{"$exists": false},
+	}, from, to, granularity, tz)
+}
+
+// GetAccountPOIMetricHistory returns the history of the metric of a POI followed by an account
+func (m *mongoDB) GetAccountPOIMetricHistory(accountNumber string, poiID primitive.ObjectID, from, to time.Time, granularity schema.MetricHistoryGranularity, tz *time.Location) ([]schema.MetricHistoryPoint, error) {
+	return m.getMetricHistory(bson.M{
+		"account_number": accountNumber,
+		"poi_id":         poiID,
+	}, from, to, granularity, tz)
+}
+
+// getMetricHistory averages the snapshots matching the query in [from, to) by the granularity,
+// where periods are cut in the time zone with its offset taken at from.
+func (m *mongoDB) getMetricHistory(query bson.M, from, to time.Time, granularity schema.MetricHistoryGranularity, tz *time.Location) ([]schema.MetricHistoryPoint, error) {
+	format, ok := metricHistoryDateFormats[granularity]
+	if !ok {
+		return nil, ErrUnknownGranularity
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	query["created_at"] = bson.M{
+		"$gte": from.UTC(),
+		"$lt":  to.UTC(),
+	}
+
+	pipeline := []bson.M{
+		{"$match": query},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"$dateToString": bson.M{
+						"format":   format.aggFormat,
+						"date":     "$created_at",
+						"timezone": from.In(tz).Format("-07:00"),
+					},
+				},
+				"score":          bson.M{"$avg": "$score"},
+				"symptom_score":  bson.M{"$avg": "$symptom_score"},
+				"behavior_score": bson.M{"$avg": "$behavior_score"},
+				"confirm_score":  bson.M{"$avg": "$confirm_score"},
+				"count":          bson.M{"$sum": 1},
+			},
+		},
+		{"$sort": bson.M{"_id": 1}},
+	}
+
+	c := m.client.Database(m.database).Collection(schema.MetricHistoryCollection)
+	cursor, err := c.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	points := make([]schema.MetricHistoryPoint, 0)
+	for cursor.Next(ctx) {
+		var result struct {
+			Period        string  `bson:"_id"`
+			Score         float64 `bson:"score"`
+			SymptomScore  float64 `bson:"symptom_score"`
+			BehaviorScore float64 `bson:"behavior_score"`
+			ConfirmScore  float64 `bson:"confirm_score"`
+			Count         int     `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+
+		periodStartAt, err := time.ParseInLocation(format.layout, result.Period, tz)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, schema.MetricHistoryPoint{
+			Timestamp:     periodStartAt.Unix(),
+			Score:         result.Score,
+			SymptomScore:  result.SymptomScore,
+			BehaviorScore: result.BehaviorScore,
+			ConfirmScore:  result.ConfirmScore,
+			Count:         result.Count,
+		})
+	}
+
+	return points, cursor.Err()
+}
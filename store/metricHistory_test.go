@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type MetricHistoryTestSuite struct {
+	suite.Suite
+	connURI      string
+	testDBName   string
+	mongoClient  *mongo.Client
+	testDatabase *mongo.Database
+}
+
+func NewMetricHistoryTestSuite(connURI, dbName string) *MetricHistoryTestSuite {
+	return &MetricHistoryTestSuite{
+		connURI:    connURI,
+		testDBName: dbName,
+	}
+}
+
+func (s *MetricHistoryTestSuite) SetupSuite() {
+	if s.connURI == "" || s.testDBName == "" {
+		s.T().Fatal("invalid test suite configuration")
+	}
+
+	opts := options.Client().ApplyURI(s.connURI)
+	mongoClient, err := mongo.NewClient(opts)
+	if nil != err {
+		s.T().Fatalf("create mongo client with error: %s", err)
+	}
+
+	if err = mongoClient.Connect(context.Background()); nil != err {
+		s.T().Fatalf("connect mongo database with error: %s", err.Error())
+	}
+
+	s.mongoClient = mongoClient
+	s.testDatabase = mongoClient.Database(s.testDBName)
+}
+
+func (s *MetricHistoryTestSuite) SetupTest() {
+	if _, err := s.testDatabase.Collection(schema.MetricHistoryCollection).DeleteMany(context.Background(), bson.M{}); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *MetricHistoryTestSuite) TearDownSuite() {
+	if err := s.testDatabase.Collection(schema.MetricHistoryCollection).Drop(context.Background()); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *MetricHistoryTestSuite) TestAddAndGetMetricHistory() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	poiID := primitive.NewObjectID()
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	histories := []schema.MetricHistory{
+		{AccountNumber: "account-a", Score: 60, SymptomScore: 50, CreatedAt: day.Add(1 * time.Hour)},
+		{AccountNumber: "account-a", Score: 80, SymptomScore: 70, CreatedAt: day.Add(1*time.Hour + 30*time.Minute)},
+		{AccountNumber: "account-a", Score: 90, CreatedAt: day.Add(26 * time.Hour)},
+		{AccountNumber: "account-a", POIID: &poiID, Score: 40, CreatedAt: day.Add(2 * time.Hour)},
+		{AccountNumber: "account-b", Score: 10, CreatedAt: day.Add(2 * time.Hour)},
+	}
+	for _, h := range histories {
+		s.NoError(store.AddMetricHistory(h))
+	}
+
+	points, err := store.GetAccountMetricHistory("account-a", day, day.Add(48*time.Hour), schema.MetricHistoryGranularityDay, time.UTC)
+	s.NoError(err)
+	s.Len(points, 2)
+	s.Equal(day.Unix(), points[0].Timestamp)
+	s.Equal(float64(70), points[0].Score)
+	s.Equal(float64(60), points[0].SymptomScore)
+	s.Equal(2, points[0].Count)
+	s.Equal(float64(90), points[1].Score)
+
+	points, err = store.GetAccountMetricHistory("account-a", day, day.Add(24*time.Hour), schema.MetricHistoryGranularityHour, time.UTC)
+	s.NoError(err)
+	s.Len(points, 1)
+	s.Equal(day.Add(time.Hour).Unix(), points[0].Timestamp)
+
+	points, err = store.GetAccountPOIMetricHistory("account-a", poiID, day, day.Add(48*time.Hour), schema.MetricHistoryGranularityDay, time.UTC)
+	s.NoError(err)
+	s.Len(points, 1)
+	s.Equal(float64(40), points[0].Score)
+
+	_, err = store.GetAccountMetricHistory("account-a", day, day.Add(48*time.Hour), "week", time.UTC)
+	s.Equal(ErrUnknownGranularity, err)
+}
+
+// TestGetMetricHistoryLocalDays tests snapshots are grouped by the days of a time zone
+func (s *MetricHistoryTestSuite) TestGetMetricHistoryLocalDays() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tz := time.FixedZone("GMT-3", -3*60*60)
+
+	histories := []schema.MetricHistory{
+		{AccountNumber: "account-a", Score: 60, CreatedAt: day.Add(1 * time.Hour)},
+		{AccountNumber: "account-a", Score: 80, CreatedAt: day.Add(4 * time.Hour)},
+		{AccountNumber: "account-a", Score: 90, CreatedAt: day.Add(30 * time.Hour)},
+	}
+	for _, h := range histories {
+		s.NoError(store.AddMetricHistory(h))
+	}
+
+	localDay := time.Date(2020, 5, 31, 0, 0, 0, 0, tz)
+	points, err := store.GetAccountMetricHistory("account-a", localDay, localDay.AddDate(0, 0, 3), schema.MetricHistoryGranularityDay, tz)
+	s.NoError(err)
+	s.Len(points, 3)
+	s.Equal(localDay.Unix(), points[0].Timestamp)
+	s.Equal(float64(60), points[0].Score)
+	s.Equal(localDay.AddDate(0, 0, 1).Unix(), points[1].Timestamp)
+	s.Equal(float64(80), points[1].Score)
+	s.Equal(localDay.AddDate(0, 0, 2).Unix(), points[2].Timestamp)
+	s.Equal(float64(90), points[2].Score)
+}
+
+func TestMetricHistoryTestSuite(t *testing.T) {
+	suite.Run(t, NewMetricHistoryTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
+}
@@ -23,11 +23,16 @@ type MongoStore interface {
 	GoodBehaviorReport
 	Closer
 	Pinger
-	ConfirmOperator
 	History
 	Metric
+	MetricHistory
 	ConfirmCDS
 	Report
+	PrivacyBudget
+	Heatmap
+	Region
+	CrawlRun
+	ConfirmQuarantine
 }
 
 // Closer - close db connection
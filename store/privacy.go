@@ -0,0 +1,318 @@
+package store
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type PrivacyMode string
+
+const (
+	// PrivacyModeSuppress hides community aggregates reported by fewer than k users
+	PrivacyModeSuppress PrivacyMode = "suppress"
+	// PrivacyModeCoarsen rounds community aggregates reported by fewer than k users
+	// to the nearest multiple of k
+	PrivacyModeCoarsen PrivacyMode = "coarsen"
+)
+
+var (
+	ErrInvalidPrivacyGuard = fmt.Errorf("invalid privacy guard")
+)
+
+// PrivacyGuard protects reporters from being identified by community aggregates.
+// Symptom and behavior aggregates of an area are guarded if fewer than K users have reported
+// in the area, so that what a specific neighbor reported can not be told.
+type PrivacyGuard struct {
+	K     int          `mapstructure:"k"`
+	Mode  PrivacyMode  `mapstructure:"mode"`
+	Noise PrivacyNoise `mapstructure:"noise"`
+}
+
+// PrivacyNoise is the Laplace mechanism which makes the aggregates served to clients other than
+// the reporters differentially private. Each query of an area spends Epsilon of the privacy budget
+// of the area, and the aggregates of an area are suppressed once DailyBudget is spent in a day.
+// Sensitivity is the most a single user changes an aggregate released by a query.
+type PrivacyNoise struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Epsilon     float64 `mapstructure:"epsilon"`
+	DailyBudget float64 `mapstructure:"daily_budget"`
+	Sensitivity float64 `mapstructure:"sensitivity"`
+}
+
+var DefaultPrivacyGuard = PrivacyGuard{
+	K:    3,
+	Mode: PrivacyModeSuppress,
+	Noise: PrivacyNoise{
+		Epsilon:     0.1,
+		DailyBudget: 1,
+		Sensitivity: 1,
+	},
+}
+
+var (
+	privacyGuardLock sync.RWMutex
+	privacyGuard     = DefaultPrivacyGuard
+)
+
+// SetupPrivacyGuard configures the privacy guard. Fields not given use the default values.
+// Setting k to 1 disables the guard.
+func SetupPrivacyGuard(g PrivacyGuard) error {
+	if g.K == 0 {
+		g.K = DefaultPrivacyGuard.K
+	}
+	if g.Mode == "" {
+		g.Mode = DefaultPrivacyGuard.Mode
+	}
+	if g.Noise.Epsilon == 0 {
+		g.Noise.Epsilon = DefaultPrivacyGuard.Noise.Epsilon
+	}
+	if g.Noise.DailyBudget == 0 {
+		g.Noise.DailyBudget = DefaultPrivacyGuard.Noise.DailyBudget
+	}
+	if g.Noise.Sensitivity == 0 {
+		g.Noise.Sensitivity = DefaultPrivacyGuard.Noise.Sensitivity
+	}
+
+	if g.K < 0 || (g.Mode != PrivacyModeSuppress && g.Mode != PrivacyModeCoarsen) {
+		return fmt.Errorf("%w: %+v", ErrInvalidPrivacyGuard, g)
+	}
+	if g.Noise.Epsilon < 0 || g.Noise.Sensitivity < 0 || g.Noise.DailyBudget < g.Noise.Epsilon {
+		return fmt.Errorf("%w: %+v", ErrInvalidPrivacyGuard, g.Noise)
+	}
+
+	privacyGuardLock.Lock()
+	defer privacyGuardLock.Unlock()
+
+	privacyGuard = g
+	return nil
+}
+
+// GetPrivacyGuard returns the configured privacy guard
+func GetPrivacyGuard() PrivacyGuard {
+	privacyGuardLock.RLock()
+	defer privacyGuardLock.RUnlock()
+
+	return privacyGuard
+}
+
+// Distribution guards a distribution reported by the given number of users.
+// It returns the guarded distribution and whether the distribution is suppressed or coarsened.
+func (g PrivacyGuard) Distribution(distribution map[string]float64, reporters int) (map[string]float64, bool) {
+	if reporters >= g.K || len(distribution) == 0 {
+		return distribution, false
+	}
+
+	guarded := make(map[string]float64)
+	if g.Mode == PrivacyModeCoarsen {
+		for k, v := range distribution {
+			if v = g.coarsen(v); v > 0 {
+				guarded[k] = v
+			}
+		}
+	}
+
+	return guarded, true
+}
+
+// Count guards a count reported by the given number of users.
+// It returns the guarded count and whether the count is suppressed or coarsened.
+func (g PrivacyGuard) Count(count float64, reporters int) (float64, bool) {
+	if reporters >= g.K || count == 0 {
+		return count, false
+	}
+
+	if g.Mode == PrivacyModeCoarsen {
+		return g.coarsen(count), true
+	}
+
+	return 0, true
+}
+
+// Metric guards the community counts of a metric responded to clients if the metric is
+// flagged suppressed. The scores are kept since they are calculated from the raw aggregates.
+func (g PrivacyGuard) Metric(metric schema.Metric) schema.Metric {
+	if !metric.Suppressed {
+		return metric
+	}
+
+	if g.Mode == PrivacyModeCoarsen {
+		metric.SymptomCount = g.coarsen(metric.SymptomCount)
+		metric.BehaviorCount = g.coarsen(metric.BehaviorCount)
+	} else {
+		metric.SymptomCount = 0
+		metric.BehaviorCount = 0
+	}
+	// the changes tell the counts of yesterday, which are guarded as well
+	metric.SymptomDelta = 0
+	metric.BehaviorDelta = 0
+
+	return metric
+}
+
+// HeatmapCell guards a heatmap cell responded to clients if its metric is flagged suppressed
+// or fewer than K users have reported in the cell. It returns false if the cell is suppressed,
+// and the reporter and report counts of a coarsened cell are rounded to the multiples of k.
+func (g PrivacyGuard) HeatmapCell(cell schema.HeatmapCell) (schema.HeatmapCell, bool) {
+	if !cell.Suppressed && cell.Confidence.Reporters >= g.K {
+		return cell, true
+	}
+
+	if g.Mode == PrivacyModeCoarsen {
+		cell.Confidence.Reporters = int(g.coarsen(float64(cell.Confidence.Reporters)))
+		cell.Confidence.Reports = int(g.coarsen(float64(cell.Confidence.Reports)))
+		return cell, true
+	}
+
+	return cell, false
+}
+
+func (g PrivacyGuard) coarsen(v float64) float64 {
+	k := float64(g.K)
+	return math.Round(v/k) * k
+}
+
+// Distribution adds Laplace noise to each value of a distribution released by a query.
+// A single user may count in every key of the distribution, so the sensitivity is scaled
+// by the number of keys. Noisy values are clamped to be non-negative and values which
+// become zero are removed.
+func (n PrivacyNoise) Distribution(distribution map[string]float64) map[string]float64 {
+	scale := n.Sensitivity * float64(len(distribution)) / n.Epsilon
+
+	noisy := make(map[string]float64)
+	for k, v := range distribution {
+		if v = addLaplaceNoise(v, scale); v > 0 {
+			noisy[k] = v
+		}
+	}
+	return noisy
+}
+
+// Counts adds Laplace noise to the counts released by a query, where the epsilon
+// is split among the counts. Noisy counts are clamped to be non-negative.
+func (n PrivacyNoise) Counts(counts ...float64) []float64 {
+	scale := n.Sensitivity * float64(len(counts)) / n.Epsilon
+
+	noisy := make([]float64, len(counts))
+	for i, c := range counts {
+		noisy[i] = addLaplaceNoise(c, scale)
+	}
+	return noisy
+}
+
+func addLaplaceNoise(v, scale float64) float64 {
+	return math.Max(0, v+laplace(scale, noiseUniform()))
+}
+
+var (
+	noiseRandLock sync.Mutex
+	// noiseRand is seeded once at startup so that the noise differs among runs
+	noiseRand = rand.New(rand.NewSource(noiseSeed()))
+)
+
+// noiseSeed returns a seed from the cryptographically secure random source. The current
+// time is used if the source is not available.
+func noiseSeed() int64 {
+	var b [8]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// noiseUniform returns a uniform random number in [0, 1) for the noise
+func noiseUniform() float64 {
+	noiseRandLock.Lock()
+	defer noiseRandLock.Unlock()
+
+	return noiseRand.Float64()
+}
+
+// laplace transforms a uniform random number in [0, 1) to a sample of
+// the Laplace distribution centered at zero with the given scale
+func laplace(scale, uniform float64) float64 {
+	u := uniform - 0.5
+	if u == -0.5 {
+		u = 0
+	}
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
+
+// privacyArea returns the area of a location which the privacy budget is tracked by.
+// An area is a cell of 0.01 degree latitude by 0.01 degree longitude, about one kilometer square.
+func privacyArea(loc schema.Location) string {
+	return fmt.Sprintf("%.2f,%.2f", loc.Latitude, loc.Longitude)
+}
+
+type PrivacyBudget interface {
+	SpendPrivacyBudget(loc schema.Location, epsilon float64, now time.Time) (bool, error)
+}
+
+// SpendPrivacyBudget spends epsilon of the daily privacy budget of the area of a location.
+// It returns false without spending any budget if the remaining budget is not enough.
+func (m *mongoDB) SpendPrivacyBudget(loc schema.Location, epsilon float64, now time.Time) (bool, error) {
+	dailyBudget := GetPrivacyGuard().Noise.DailyBudget
+	if epsilon > dailyBudget {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"area":  privacyArea(loc),
+		"day":   now.UTC().Format("2006-01-02"),
+		"spent": bson.M{"$lte": dailyBudget - epsilon},
+	}
+	update := bson.M{
+		"$inc":         bson.M{"spent": epsilon},
+		"$setOnInsert": bson.M{"created_at": now.UTC()},
+	}
+
+	c := m.client.Database(m.database).Collection(schema.PrivacyBudgetCollection)
+	if _, err := c.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		// the budget of the day exists but is not enough, so a new one is attempted to be inserted
+		if we, hasErr := err.(mongo.WriteException); hasErr {
+			if 1 == len(we.WriteErrors) && DuplicateKeyCode == we.WriteErrors[0].Code {
+				return false, nil
+			}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// guardDistribution guards a distribution of an area served to clients other than the reporters.
+// Noise is added if it is enabled, and the distribution is suppressed if the privacy budget is used up.
+func (m *mongoDB) guardDistribution(distribution map[string]float64, reporters int, loc schema.Location) (map[string]float64, error) {
+	guard := GetPrivacyGuard()
+	distribution, _ = guard.Distribution(distribution, reporters)
+	if !guard.Noise.Enabled || len(distribution) == 0 {
+		return distribution, nil
+	}
+
+	ok, err := m.SpendPrivacyBudget(loc, guard.Noise.Epsilon, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string]float64{}, nil
+	}
+
+	return guard.Noise.Distribution(distribution), nil
+}
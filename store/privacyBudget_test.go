@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+type PrivacyBudgetTestSuite struct {
+	suite.Suite
+	connURI      string
+	testDBName   string
+	mongoClient  *mongo.Client
+	testDatabase *mongo.Database
+}
+
+func NewPrivacyBudgetTestSuite(connURI, dbName string) *PrivacyBudgetTestSuite {
+	return &PrivacyBudgetTestSuite{
+		connURI:    connURI,
+		testDBName: dbName,
+	}
+}
+
+func (s *PrivacyBudgetTestSuite) SetupSuite() {
+	if s.connURI == "" || s.testDBName == "" {
+		s.T().Fatal("invalid test suite configuration")
+	}
+
+	opts := options.Client().ApplyURI(s.connURI)
+	mongoClient, err := mongo.NewClient(opts)
+	if nil != err {
+		s.T().Fatalf("create mongo client with error: %s", err)
+	}
+
+	if err = mongoClient.Connect(context.Background()); nil != err {
+		s.T().Fatalf("connect mongo database with error: %s", err.Error())
+	}
+
+	s.mongoClient = mongoClient
+	s.testDatabase = mongoClient.Database(s.testDBName)
+
+	// the budget relies on the unique index of {area, day} to tell it is used up
+	if err := schema.NewMongoDBIndexer(s.connURI, s.testDBName).IndexPrivacyBudgetCollection(); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *PrivacyBudgetTestSuite) SetupTest() {
+	if _, err := s.testDatabase.Collection(schema.PrivacyBudgetCollection).DeleteMany(context.Background(), bson.M{}); err != nil {
+		s.T().Fatal(err)
+	}
+
+	if err := SetupPrivacyGuard(PrivacyGuard{
+		K: 1,
+		Noise: PrivacyNoise{
+			Enabled:     true,
+			Epsilon:     0.5,
+			DailyBudget: 1,
+		},
+	}); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *PrivacyBudgetTestSuite) TearDownSuite() {
+	if err := SetupPrivacyGuard(DefaultPrivacyGuard); err != nil {
+		s.T().Fatal(err)
+	}
+
+	if err := s.testDatabase.Collection(schema.PrivacyBudgetCollection).Drop(context.Background()); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *PrivacyBudgetTestSuite) TestSpendPrivacyBudget() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	now := time.Now()
+	loc := schema.Location{Latitude: 25.0412, Longitude: 121.5655}
+
+	for i := 0; i < 2; i++ {
+		ok, err := store.SpendPrivacyBudget(loc, 0.5, now)
+		s.NoError(err)
+		s.True(ok)
+	}
+
+	ok, err := store.SpendPrivacyBudget(loc, 0.5, now)
+	s.NoError(err)
+	s.False(ok)
+
+	// the budget of another day is not spent yet
+	ok, err = store.SpendPrivacyBudget(loc, 0.5, now.Add(24*time.Hour))
+	s.NoError(err)
+	s.True(ok)
+}
+
+// TestGuardDistributionOfAnotherAccountInSameArea validates that the budget is tracked by
+// the queried area instead of the requester, so that an account querying an area whose
+// budget is spent by another account gets the distribution suppressed.
+func (s *PrivacyBudgetTestSuite) TestGuardDistributionOfAnotherAccountInSameArea() {
+	store := NewMongoStore(s.mongoClient, s.testDBName).(*mongoDB)
+	distribution := map[string]float64{"fever": 1000, "cough": 1000}
+
+	// the first account spends the whole budget of the area
+	firstAccountLoc := schema.Location{Latitude: 25.0412, Longitude: 121.5655}
+	for i := 0; i < 2; i++ {
+		guarded, err := store.guardDistribution(distribution, 10, firstAccountLoc)
+		s.NoError(err)
+		s.Len(guarded, 2)
+	}
+
+	// the second account stands at a different location in the same area
+	secondAccountLoc := schema.Location{Latitude: 25.0448, Longitude: 121.5690}
+	s.Equal(privacyArea(firstAccountLoc), privacyArea(secondAccountLoc))
+	guarded, err := store.guardDistribution(distribution, 10, secondAccountLoc)
+	s.NoError(err)
+	s.Empty(guarded)
+
+	// the areas nearby keep their own budget
+	guarded, err = store.guardDistribution(distribution, 10, schema.Location{Latitude: 25.0512, Longitude: 121.5655})
+	s.NoError(err)
+	s.Len(guarded, 2)
+}
+
+func TestPrivacyBudgetTestSuite(t *testing.T) {
+	suite.Run(t, NewPrivacyBudgetTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
+}
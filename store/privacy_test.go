@@ -0,0 +1,175 @@
+package store
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+)
+
+func TestSetupPrivacyGuard(t *testing.T) {
+	defer SetupPrivacyGuard(DefaultPrivacyGuard)
+
+	assert.NoError(t, SetupPrivacyGuard(PrivacyGuard{}))
+	assert.Equal(t, DefaultPrivacyGuard, GetPrivacyGuard())
+
+	assert.NoError(t, SetupPrivacyGuard(PrivacyGuard{K: 5, Mode: PrivacyModeCoarsen}))
+	assert.Equal(t, PrivacyGuard{K: 5, Mode: PrivacyModeCoarsen, Noise: DefaultPrivacyGuard.Noise}, GetPrivacyGuard())
+
+	assert.Error(t, SetupPrivacyGuard(PrivacyGuard{K: -1}))
+	assert.Error(t, SetupPrivacyGuard(PrivacyGuard{Mode: "unknown"}))
+	assert.Error(t, SetupPrivacyGuard(PrivacyGuard{Noise: PrivacyNoise{Epsilon: 2}}))
+	assert.Error(t, SetupPrivacyGuard(PrivacyGuard{Noise: PrivacyNoise{Sensitivity: -1}}))
+	assert.Equal(t, 5, GetPrivacyGuard().K)
+
+	assert.NoError(t, SetupPrivacyGuard(PrivacyGuard{Noise: PrivacyNoise{Enabled: true, Epsilon: 0.5}}))
+	assert.Equal(t, PrivacyNoise{Enabled: true, Epsilon: 0.5, DailyBudget: 1, Sensitivity: 1}, GetPrivacyGuard().Noise)
+}
+
+func TestPrivacyGuardSuppress(t *testing.T) {
+	g := PrivacyGuard{K: 3, Mode: PrivacyModeSuppress}
+
+	distribution := map[string]float64{"fever": 2, "cough": 1}
+	guarded, suppressed := g.Distribution(distribution, 3)
+	assert.False(t, suppressed)
+	assert.Equal(t, distribution, guarded)
+
+	guarded, suppressed = g.Distribution(distribution, 2)
+	assert.True(t, suppressed)
+	assert.Empty(t, guarded)
+
+	guarded, suppressed = g.Distribution(map[string]float64{}, 0)
+	assert.False(t, suppressed)
+	assert.Empty(t, guarded)
+
+	count, suppressed := g.Count(7, 3)
+	assert.False(t, suppressed)
+	assert.Equal(t, float64(7), count)
+
+	count, suppressed = g.Count(7, 2)
+	assert.True(t, suppressed)
+	assert.Equal(t, float64(0), count)
+
+	count, suppressed = g.Count(0, 0)
+	assert.False(t, suppressed)
+	assert.Equal(t, float64(0), count)
+}
+
+func TestPrivacyGuardCoarsen(t *testing.T) {
+	g := PrivacyGuard{K: 3, Mode: PrivacyModeCoarsen}
+
+	guarded, suppressed := g.Distribution(map[string]float64{"fever": 2, "cough": 1, "nasal": 0.5}, 2)
+	assert.True(t, suppressed)
+	assert.Equal(t, map[string]float64{"fever": 3}, guarded)
+
+	count, suppressed := g.Count(8, 2)
+	assert.True(t, suppressed)
+	assert.Equal(t, float64(9), count)
+
+	count, suppressed = g.Count(1, 1)
+	assert.True(t, suppressed)
+	assert.Equal(t, float64(0), count)
+}
+
+func TestPrivacyGuardMetric(t *testing.T) {
+	metric := schema.Metric{
+		Score:         60,
+		SymptomCount:  4,
+		SymptomDelta:  100,
+		BehaviorCount: 5,
+		BehaviorDelta: 25,
+	}
+
+	// a metric not flagged suppressed is responded as it is
+	assert.Equal(t, metric, PrivacyGuard{K: 3, Mode: PrivacyModeSuppress}.Metric(metric))
+
+	metric.Suppressed = true
+	guarded := PrivacyGuard{K: 3, Mode: PrivacyModeSuppress}.Metric(metric)
+	assert.Equal(t, 60.0, guarded.Score)
+	assert.Equal(t, 0.0, guarded.SymptomCount)
+	assert.Equal(t, 0.0, guarded.SymptomDelta)
+	assert.Equal(t, 0.0, guarded.BehaviorCount)
+	assert.Equal(t, 0.0, guarded.BehaviorDelta)
+
+	guarded = PrivacyGuard{K: 3, Mode: PrivacyModeCoarsen}.Metric(metric)
+	assert.Equal(t, 60.0, guarded.Score)
+	assert.Equal(t, 3.0, guarded.SymptomCount)
+	assert.Equal(t, 6.0, guarded.BehaviorCount)
+	assert.Equal(t, 0.0, guarded.SymptomDelta)
+	assert.True(t, guarded.Suppressed)
+}
+
+func TestPrivacyGuardHeatmapCell(t *testing.T) {
+	cell := schema.HeatmapCell{
+		Geohash:    "wsqqqm",
+		Score:      60,
+		Confidence: schema.Confidence{Reporters: 4, Reports: 7},
+	}
+
+	suppress := PrivacyGuard{K: 3, Mode: PrivacyModeSuppress}
+	guarded, ok := suppress.HeatmapCell(cell)
+	assert.True(t, ok)
+	assert.Equal(t, cell, guarded)
+
+	// a cell whose symptoms or behaviors are suppressed
+	suppressedCell := cell
+	suppressedCell.Suppressed = true
+	_, ok = suppress.HeatmapCell(suppressedCell)
+	assert.False(t, ok)
+
+	// a cell reported by fewer than k users
+	fewReporters := cell
+	fewReporters.Confidence = schema.Confidence{Reporters: 2, Reports: 5}
+	_, ok = suppress.HeatmapCell(fewReporters)
+	assert.False(t, ok)
+
+	coarsen := PrivacyGuard{K: 3, Mode: PrivacyModeCoarsen}
+	guarded, ok = coarsen.HeatmapCell(fewReporters)
+	assert.True(t, ok)
+	assert.Equal(t, 3, guarded.Confidence.Reporters)
+	assert.Equal(t, 6, guarded.Confidence.Reports)
+	assert.Equal(t, 60.0, guarded.Score)
+}
+
+func TestLaplace(t *testing.T) {
+	assert.Equal(t, float64(0), laplace(2, 0.5))
+	assert.Equal(t, float64(0), laplace(2, 0))
+	assert.InDelta(t, 2*math.Log(2), laplace(2, 0.75), 1e-9)
+	assert.InDelta(t, -2*math.Log(2), laplace(2, 0.25), 1e-9)
+}
+
+func TestPrivacyNoise(t *testing.T) {
+	n := PrivacyNoise{Enabled: true, Epsilon: 1, Sensitivity: 1}
+
+	samples := 10000
+	var sum, absSum float64
+	for i := 0; i < samples; i++ {
+		noisy := n.Counts(1000, 1000)
+		assert.Len(t, noisy, 2)
+		sum += noisy[0] - 1000
+		absSum += math.Abs(noisy[0] - 1000)
+	}
+	// the noise of each count is of scale 2 since the epsilon is split between two counts
+	assert.InDelta(t, 0, sum/float64(samples), 0.2)
+	assert.InDelta(t, 2, absSum/float64(samples), 0.2)
+
+	// the noise of each value is of scale 4 since a user may count in all the four keys
+	absSum = 0
+	distribution := map[string]float64{"fever": 1000, "cough": 1000, "nasal": 1000, "fatigue": 1000}
+	for i := 0; i < samples; i++ {
+		absSum += math.Abs(n.Distribution(distribution)["fever"] - 1000)
+	}
+	assert.InDelta(t, 4, absSum/float64(samples), 0.4)
+
+	// the noise source is seeded differently on every start
+	assert.NotEqual(t, noiseSeed(), noiseSeed())
+
+	for i := 0; i < 100; i++ {
+		for _, v := range n.Distribution(map[string]float64{"fever": 1, "cough": 0.1}) {
+			assert.True(t, v > 0)
+		}
+		assert.True(t, n.Counts(0)[0] >= 0)
+	}
+}
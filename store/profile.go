@@ -4,14 +4,13 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/bitmark-inc/autonomy-api/consts"
-
 	log "github.com/sirupsen/logrus"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
 )
 
 func (m *mongoDB) ProfileMetric(accountNumber string) (*schema.Metric, error) {
@@ -58,7 +57,7 @@ func (m *mongoDB) UpdateAreaProfileBehavior(behaviors []schema.Behavior, locatio
 	if 0 == len(behaviors) {
 		return fmt.Errorf("no behavior")
 	}
-	query := distanceQuery(consts.NEARBY_DISTANCE_RANGE, location)
+	query := distanceQuery(score.GetNeighborhood().Radius, location)
 	c := m.client.Database(m.database).Collection(schema.ProfileCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -105,7 +104,7 @@ func (m *mongoDB) UpdateAreaProfileSymptom(symptoms []schema.Symptom, location s
 	if 0 == len(symptoms) {
 		return fmt.Errorf("no symptom")
 	}
-	query := distanceQuery(consts.NEARBY_DISTANCE_RANGE, location)
+	query := distanceQuery(score.GetNeighborhood().Radius, location)
 	c := m.client.Database(m.database).Collection(schema.ProfileCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -0,0 +1,340 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+	"github.com/bitmark-inc/autonomy-api/utils"
+)
+
+var (
+	ErrRegionNotFound       = fmt.Errorf("region not found")
+	ErrUnknownRegionRanking = fmt.Errorf("unknown region ranking")
+)
+
+type RegionRanking string
+
+const (
+	RegionRankingByScore      RegionRanking = "score"
+	RegionRankingByDelta      RegionRanking = "delta"
+	RegionRankingByConfidence RegionRanking = "confidence"
+)
+
+// regionRankingFields maps a ranking to the field which region metrics are sorted by
+var regionRankingFields = map[RegionRanking]string{
+	RegionRankingByScore:      "score",
+	RegionRankingByDelta:      "score_delta",
+	RegionRankingByConfidence: "confidence.reporters",
+}
+
+type Region interface {
+	CollectRegionRawMetrics(region schema.AddressComponent, window schema.ScoreWindow) (*schema.Metric, *time.Location, error)
+	GetRegionRawMetrics(region schema.Region, window schema.ScoreWindow, now time.Time) (*schema.Metric, *time.Location, error)
+	FindRegions() ([]schema.Region, error)
+	GetRegionMetric(id string) (*schema.RegionMetric, error)
+	UpdateRegionMetric(metric schema.RegionMetric) error
+	RankRegionMetrics(level schema.RegionLevel, country string, ranking RegionRanking, limit int64, excludeLowConfidence bool) ([]schema.RegionMetric, error)
+}
+
+// CollectRegionRawMetrics gathers the data required to calculate an autonomy score of an administrative
+// region, which is a country, a state or a county. Symptoms and behaviors reported within the boundaries
+// of the region are aggregated, and they are combined with the confirmed case data of the region.
+// A region without state or county covers all the boundaries in its country or state.
+// Days start at the midnight of the time zone of the region, which is returned along with the metrics.
+func (m *mongoDB) CollectRegionRawMetrics(region schema.AddressComponent, window schema.ScoreWindow) (*schema.Metric, *time.Location, error) {
+	geometries, err := m.regionGeometries(region)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(geometries) == 0 {
+		return nil, nil, ErrRegionNotFound
+	}
+
+	tz := regionTimezone(geometries)
+	now := time.Now().In(tz)
+	current, _ := score.ReportPeriods(window, now)
+	area := aggStageGeoWithin(geometries)
+
+	reporters, reports, err := m.reportActivity(area, current.Start.Unix(), current.End.Unix())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metric, err := m.collectReportMetrics(area, window, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.collectConfirmMetrics(schema.Location{AddressComponent: region}, now, metric); err != nil {
+		return nil, nil, err
+	}
+
+	metric.Confidence.Reporters = reporters
+	metric.Confidence.Reports = reports
+
+	return metric, tz, nil
+}
+
+// GetRegionRawMetrics returns the raw metrics of a region collected by a score window, along with
+// the time zone of the region. The raw metrics are cached for RegionRawMetricTTL, so the boundaries
+// of the region are not aggregated on every request.
+func (m *mongoDB) GetRegionRawMetrics(region schema.Region, window schema.ScoreWindow, now time.Time) (*schema.Metric, *time.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	id := regionRawMetricID(region, window)
+	c := m.client.Database(m.database).Collection(schema.RegionRawMetricCollection)
+
+	var cached schema.RegionRawMetric
+	err := c.FindOne(ctx, bson.M{
+		"_id":        id,
+		"updated_at": bson.M{"$gt": now.Add(-schema.RegionRawMetricTTL)},
+	}).Decode(&cached)
+	if err == nil {
+		// the cache without a valid time zone is collected again
+		if tz := utils.GetLocation(cached.Timezone); tz != nil {
+			return &cached.Metric, tz, nil
+		}
+	} else if err != mongo.ErrNoDocuments {
+		return nil, nil, err
+	}
+
+	metric, tz, err := m.CollectRegionRawMetrics(region.AddressComponent(), window)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := c.ReplaceOne(ctx, bson.M{"_id": id}, schema.RegionRawMetric{
+		ID:        id,
+		Metric:    *metric,
+		Timezone:  tz.String(),
+		UpdatedAt: now.UTC(),
+	}, options.Replace().SetUpsert(true)); err != nil {
+		// the raw metrics are still usable even if they are not cached
+		log.WithFields(log.Fields{
+			"prefix": mongoLogPrefix,
+			"region": region.ID(),
+			"err":    err,
+		}).Warn("cache region raw metrics")
+	}
+
+	return metric, tz, nil
+}
+
+// regionRawMetricID identifies the raw metrics of a region by the score window they are collected by
+func regionRawMetricID(region schema.Region, window schema.ScoreWindow) string {
+	return fmt.Sprintf("%s|%s|%d|%g", region.ID(), window.Mode, window.Hours, window.HalfLifeHours)
+}
+
+// regionTimezone returns the time zone of a region, which is approximated by the nautical time zone
+// of the longitude at the center of the bounds of its boundaries. It is UTC if there is no position.
+func regionTimezone(geometries []schema.Geometry) *time.Location {
+	minLongitude, maxLongitude := math.Inf(1), math.Inf(-1)
+	for _, g := range geometries {
+		walkLongitudes(g.Coordinates, func(longitude float64) {
+			minLongitude = math.Min(minLongitude, longitude)
+			maxLongitude = math.Max(maxLongitude, longitude)
+		})
+	}
+
+	if minLongitude > maxLongitude {
+		return time.UTC
+	}
+
+	return utils.GetLocalLocation("", (minLongitude+maxLongitude)/2)
+}
+
+// walkLongitudes calls f with the longitude of each position in the coordinates of a geometry,
+// which are arrays of positions in the form of [longitude, latitude] nested by the geometry type
+func walkLongitudes(coordinates interface{}, f func(float64)) {
+	var values []interface{}
+	switch c := coordinates.(type) {
+	case primitive.A:
+		values = c
+	case []interface{}:
+		values = c
+	default:
+		return
+	}
+
+	if len(values) >= 2 {
+		longitude, isPosition := values[0].(float64)
+		if _, ok := values[1].(float64); isPosition && ok {
+			f(longitude)
+			return
+		}
+	}
+
+	for _, v := range values {
+		walkLongitudes(v, f)
+	}
+}
+
+// regionGeometries returns the geometries of the boundaries in a region
+func (m *mongoDB) regionGeometries(region schema.AddressComponent) ([]schema.Geometry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	filter := bson.M{"country": region.Country}
+	if region.State != "" {
+		filter["state"] = region.State
+	}
+	if region.County != "" {
+		filter["county"] = region.County
+	}
+
+	c := m.client.Database(m.database).Collection(schema.BoundaryCollection)
+	cursor, err := c.Find(ctx, filter, options.Find().SetProjection(bson.M{"geometry": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	geometries := make([]schema.Geometry, 0)
+	for cursor.Next(ctx) {
+		var boundary schema.Boundary
+		if err := cursor.Decode(&boundary); err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, boundary.Geometry)
+	}
+
+	return geometries, cursor.Err()
+}
+
+// FindRegions returns the regions of all levels which have boundaries
+func (m *mongoDB) FindRegions() ([]schema.Region, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.BoundaryCollection)
+	cursor, err := c.Aggregate(ctx, []bson.M{
+		{
+			"$group": bson.M{
+				"_id": bson.M{"country": "$country", "state": "$state", "county": "$county"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	regions := make([]schema.Region, 0)
+	found := make(map[string]struct{})
+	add := func(r schema.Region) {
+		if _, ok := found[r.ID()]; !ok {
+			found[r.ID()] = struct{}{}
+			regions = append(regions, r)
+		}
+	}
+
+	for cursor.Next(ctx) {
+		var aggItem struct {
+			Region schema.Region `bson:"_id"`
+		}
+		if err := cursor.Decode(&aggItem); err != nil {
+			return nil, err
+		}
+
+		r := aggItem.Region
+		if r.Country == "" {
+			continue
+		}
+
+		// a boundary of a county is also a part of its state and its country
+		add(schema.Region{Country: r.Country})
+		if r.State != "" {
+			add(schema.Region{Country: r.Country, State: r.State})
+		}
+		if r.County != "" {
+			add(r)
+		}
+	}
+
+	return regions, cursor.Err()
+}
+
+// GetRegionMetric returns the metric of a region. It returns nil if the metric is not calculated.
+func (m *mongoDB) GetRegionMetric(id string) (*schema.RegionMetric, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.RegionMetricCollection)
+
+	var metric schema.RegionMetric
+	if err := c.FindOne(ctx, bson.M{"_id": id}).Decode(&metric); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &metric, nil
+}
+
+// UpdateRegionMetric saves the metric of a region
+func (m *mongoDB) UpdateRegionMetric(metric schema.RegionMetric) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	c := m.client.Database(m.database).Collection(schema.RegionMetricCollection)
+	_, err := c.ReplaceOne(ctx, bson.M{"_id": metric.ID}, metric, options.Replace().SetUpsert(true))
+	return err
+}
+
+// RankRegionMetrics returns the metrics of the regions of a level in descending order of the ranking.
+// Regions of all countries are ranked if the country is not given. Regions without any report or
+// confirmed case data are left out, since they score high from no data. Regions with low confidence
+// are left out as well if excludeLowConfidence is set.
+func (m *mongoDB) RankRegionMetrics(level schema.RegionLevel, country string, ranking RegionRanking, limit int64, excludeLowConfidence bool) ([]schema.RegionMetric, error) {
+	field, ok := regionRankingFields[ranking]
+	if !ok {
+		return nil, ErrUnknownRegionRanking
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	query := bson.M{
+		"level": level,
+		"$or": bson.A{
+			bson.M{"confidence.reporters": bson.M{"$gt": 0}},
+			bson.M{"confidence.confirm_data_age": bson.M{"$gte": 0}},
+		},
+	}
+	if country != "" {
+		query["country"] = country
+	}
+	if excludeLowConfidence {
+		query["confidence.level"] = bson.M{"$ne": schema.ConfidenceLow}
+	}
+
+	c := m.client.Database(m.database).Collection(schema.RegionMetricCollection)
+	cursor, err := c.Find(ctx, query, options.Find().SetSort(bson.D{{field, -1}, {"_id", 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	metrics := make([]schema.RegionMetric, 0)
+	for cursor.Next(ctx) {
+		var metric schema.RegionMetric
+		if err := cursor.Decode(&metric); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, cursor.Err()
+}
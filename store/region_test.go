@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bitmark-inc/autonomy-api/schema"
+	"github.com/bitmark-inc/autonomy-api/score"
+)
+
+type RegionTestSuite struct {
+	suite.Suite
+	connURI      string
+	testDBName   string
+	mongoClient  *mongo.Client
+	testDatabase *mongo.Database
+}
+
+func NewRegionTestSuite(connURI, dbName string) *RegionTestSuite {
+	return &RegionTestSuite{
+		connURI:    connURI,
+		testDBName: dbName,
+	}
+}
+
+var regionTestCollections = []string{
+	schema.BoundaryCollection,
+	schema.RegionMetricCollection,
+	schema.RegionRawMetricCollection,
+}
+
+func (s *RegionTestSuite) SetupSuite() {
+	if s.connURI == "" || s.testDBName == "" {
+		s.T().Fatal("invalid test suite configuration")
+	}
+
+	opts := options.Client().ApplyURI(s.connURI)
+	mongoClient, err := mongo.NewClient(opts)
+	if nil != err {
+		s.T().Fatalf("create mongo client with error: %s", err)
+	}
+
+	if err = mongoClient.Connect(context.Background()); nil != err {
+		s.T().Fatalf("connect mongo database with error: %s", err.Error())
+	}
+
+	s.mongoClient = mongoClient
+	s.testDatabase = mongoClient.Database(s.testDBName)
+}
+
+func (s *RegionTestSuite) SetupTest() {
+	for _, c := range regionTestCollections {
+		if _, err := s.testDatabase.Collection(c).DeleteMany(context.Background(), bson.M{}); err != nil {
+			s.T().Fatal(err)
+		}
+	}
+
+	if _, err := s.testDatabase.Collection(schema.BoundaryCollection).InsertMany(context.Background(), []interface{}{
+		schema.Boundary{
+			Country: "Taiwan",
+			State:   "Taipei City",
+			County:  "Da'an District",
+			Geometry: schema.Geometry{
+				Type: "Polygon",
+				Coordinates: [][][]float64{
+					{{121.52, 25.01}, {121.56, 25.01}, {121.56, 25.04}, {121.52, 25.04}, {121.52, 25.01}},
+				},
+			},
+		},
+		schema.Boundary{
+			Country: "Taiwan",
+			State:   "Taipei City",
+			County:  "Xinyi District",
+			Geometry: schema.Geometry{
+				Type: "Polygon",
+				Coordinates: [][][]float64{
+					{{121.56, 25.01}, {121.59, 25.01}, {121.59, 25.05}, {121.56, 25.05}, {121.56, 25.01}},
+				},
+			},
+		},
+	}); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *RegionTestSuite) TearDownSuite() {
+	for _, c := range regionTestCollections {
+		if err := s.testDatabase.Collection(c).Drop(context.Background()); err != nil {
+			s.T().Fatal(err)
+		}
+	}
+}
+
+func (s *RegionTestSuite) TestFindRegions() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	regions, err := store.FindRegions()
+	s.NoError(err)
+	s.ElementsMatch([]schema.Region{
+		{Country: "Taiwan"},
+		{Country: "Taiwan", State: "Taipei City"},
+		{Country: "Taiwan", State: "Taipei City", County: "Da'an District"},
+		{Country: "Taiwan", State: "Taipei City", County: "Xinyi District"},
+	}, regions)
+}
+
+func (s *RegionTestSuite) TestCollectRegionRawMetricsUnknownRegion() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	_, _, err := store.CollectRegionRawMetrics(schema.AddressComponent{Country: "Japan"}, score.DefaultScoreWindow)
+	s.Equal(ErrRegionNotFound, err)
+
+	_, _, err = store.GetRegionRawMetrics(schema.Region{Country: "Japan"}, score.DefaultScoreWindow, time.Now())
+	s.Equal(ErrRegionNotFound, err)
+}
+
+// TestGetRegionRawMetricsCached tests the raw metrics of a region are served from the cache until it is expired
+func (s *RegionTestSuite) TestGetRegionRawMetricsCached() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	region := schema.Region{Country: "Taiwan", State: "Taipei City"}
+	now := time.Now()
+
+	metric, tz, err := store.GetRegionRawMetrics(region, score.DefaultScoreWindow, now)
+	s.NoError(err)
+	s.Equal(float64(0), metric.Score)
+	s.Equal("GMT+8", tz.String())
+
+	id := regionRawMetricID(region, score.DefaultScoreWindow)
+	var cached schema.RegionRawMetric
+	s.NoError(s.testDatabase.Collection(schema.RegionRawMetricCollection).FindOne(context.Background(), bson.M{"_id": id}).Decode(&cached))
+	s.Equal(now.UTC().Unix(), cached.UpdatedAt.Unix())
+
+	// mark the cached metrics to tell them from the collected ones
+	_, err = s.testDatabase.Collection(schema.RegionRawMetricCollection).UpdateOne(context.Background(),
+		bson.M{"_id": id}, bson.M{"$set": bson.M{"metric.score": 42}})
+	s.NoError(err)
+
+	metric, tz, err = store.GetRegionRawMetrics(region, score.DefaultScoreWindow, now.Add(time.Minute))
+	s.NoError(err)
+	s.Equal(float64(42), metric.Score)
+	s.Equal("GMT+8", tz.String())
+
+	// an expired cache is collected again
+	metric, _, err = store.GetRegionRawMetrics(region, score.DefaultScoreWindow, now.Add(schema.RegionRawMetricTTL+time.Minute))
+	s.NoError(err)
+	s.Equal(float64(0), metric.Score)
+}
+
+func (s *RegionTestSuite) TestUpdateAndRankRegionMetrics() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	metric, err := store.GetRegionMetric("Taiwan||")
+	s.NoError(err)
+	s.Nil(metric)
+
+	regions := []schema.Region{
+		{Country: "Taiwan", State: "Taipei City", County: "Da'an District"},
+		{Country: "Taiwan", State: "Taipei City", County: "Xinyi District"},
+		{Country: "Japan", State: "Tokyo", County: "Chiyoda"},
+	}
+	for i, r := range regions {
+		s.NoError(store.UpdateRegionMetric(schema.RegionMetric{
+			ID:         r.ID(),
+			Region:     r,
+			Level:      r.Level(),
+			Score:      float64(60 + 10*i),
+			ScoreDelta: float64(10 - 10*i),
+			Confidence: schema.Confidence{Reporters: 10 * (i + 1)},
+			UpdatedAt:  now,
+		}))
+	}
+
+	// regions without data score high but are not ranked, and regions with low confidence are
+	// ranked along with their confidence unless they are excluded
+	noData := schema.Region{Country: "Japan", State: "Tokyo", County: "Minato"}
+	lowConfidence := schema.Region{Country: "Japan", State: "Tokyo", County: "Shibuya"}
+	s.NoError(store.UpdateRegionMetric(schema.RegionMetric{
+		ID:         noData.ID(),
+		Region:     noData,
+		Level:      noData.Level(),
+		Score:      100,
+		Confidence: schema.Confidence{ConfirmDataAge: -1},
+		UpdatedAt:  now,
+	}))
+	s.NoError(store.UpdateRegionMetric(schema.RegionMetric{
+		ID:         lowConfidence.ID(),
+		Region:     lowConfidence,
+		Level:      lowConfidence.Level(),
+		Score:      100,
+		Confidence: schema.Confidence{Reporters: 1, Level: schema.ConfidenceLow},
+		UpdatedAt:  now,
+	}))
+
+	metric, err = store.GetRegionMetric(regions[0].ID())
+	s.NoError(err)
+	s.Equal(float64(60), metric.Score)
+	s.Equal(regions[0], metric.Region)
+	s.Equal(now, metric.UpdatedAt.UTC())
+
+	metrics, err := store.RankRegionMetrics(schema.RegionLevelCounty, "", RegionRankingByScore, 10, false)
+	s.NoError(err)
+	s.Len(metrics, 4)
+	s.Equal(lowConfidence.ID(), metrics[0].ID)
+	s.Equal(schema.ConfidenceLow, metrics[0].Confidence.Level)
+	s.Equal(regions[2].ID(), metrics[1].ID)
+
+	metrics, err = store.RankRegionMetrics(schema.RegionLevelCounty, "", RegionRankingByScore, 10, true)
+	s.NoError(err)
+	s.Len(metrics, 3)
+	s.Equal(regions[2].ID(), metrics[0].ID)
+	s.Equal(regions[1].ID(), metrics[1].ID)
+	s.Equal(regions[0].ID(), metrics[2].ID)
+
+	metrics, err = store.RankRegionMetrics(schema.RegionLevelCounty, "Taiwan", RegionRankingByDelta, 1, false)
+	s.NoError(err)
+	s.Len(metrics, 1)
+	s.Equal(regions[0].ID(), metrics[0].ID)
+
+	metrics, err = store.RankRegionMetrics(schema.RegionLevelState, "", RegionRankingByScore, 10, false)
+	s.NoError(err)
+	s.Len(metrics, 0)
+
+	_, err = store.RankRegionMetrics(schema.RegionLevelCounty, "", RegionRanking("unknown"), 10, false)
+	s.Equal(ErrUnknownRegionRanking, err)
+}
+
+func TestRegionTimezone(t *testing.T) {
+	// coordinates decoded from the database are nested arrays
+	taipei := schema.Geometry{
+		Type: "Polygon",
+		Coordinates: primitive.A{
+			primitive.A{primitive.A{121.52, 25.01}, primitive.A{121.59, 25.01}, primitive.A{121.59, 25.05}, primitive.A{121.52, 25.01}},
+		},
+	}
+	assert.Equal(t, "GMT+8", regionTimezone([]schema.Geometry{taipei}).String())
+
+	// the center of the bounds of all the boundaries of a region
+	newYork := schema.Geometry{
+		Type: "MultiPolygon",
+		Coordinates: []interface{}{
+			[]interface{}{[]interface{}{[]interface{}{-79.7, 42.0}, []interface{}{-71.9, 41.0}, []interface{}{-73.3, 45.0}, []interface{}{-79.7, 42.0}}},
+		},
+	}
+	assert.Equal(t, "GMT-5", regionTimezone([]schema.Geometry{newYork}).String())
+
+	assert.Equal(t, time.UTC, regionTimezone([]schema.Geometry{}))
+}
+
+func TestRegionTestSuite(t *testing.T) {
+	suite.Run(t, NewRegionTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
+}
@@ -12,12 +12,26 @@ import (
 )
 
 type Report interface {
-	GetNearbyReportingUserCount(reportType schema.ReportType, dist int, loc schema.Location, now time.Time) (int, error)
+	GetNearbyReportingUserCount(reportType schema.ReportType, dist int, loc schema.Location, now time.Time, tz *time.Location) (int, error)
 }
 
 // GetNearbyReportingUserCount returns the number of users who have reported symptoms/behaviors
-// in the specified area and within the specified day.
-func (m *mongoDB) GetNearbyReportingUserCount(reportType schema.ReportType, dist int, loc schema.Location, now time.Time) (int, error) {
+// in the specified area and within the specified day in the given location.
+func (m *mongoDB) GetNearbyReportingUserCount(reportType schema.ReportType, dist int, loc schema.Location, now time.Time, tz *time.Location) (int, error) {
+	_, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now, tz)
+
+	return m.nearbyReportingUserCount(reportType, dist, loc, todayStartAt.Unix(), tomorrowStartAt.Unix())
+}
+
+// nearbyReportingUserCount returns the number of users who have reported symptoms/behaviors
+// in the specified area and within the specified time range.
+func (m *mongoDB) nearbyReportingUserCount(reportType schema.ReportType, dist int, loc schema.Location, start, end int64) (int, error) {
+	return m.reportingUserCount(reportType, aggStageGeoProximity(dist, loc), start, end)
+}
+
+// reportingUserCount returns the number of users who have reported symptoms/behaviors
+// in the area matched by the given stage and within the specified time range.
+func (m *mongoDB) reportingUserCount(reportType schema.ReportType, area bson.M, start, end int64) (int, error) {
 	var c *mongo.Collection
 	switch reportType {
 	case schema.ReportTypeSymptom:
@@ -30,11 +44,9 @@ func (m *mongoDB) GetNearbyReportingUserCount(reportType schema.ReportType, dist
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
-	_, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now)
-
 	pipeline := []bson.M{
-		aggStageGeoProximity(dist, loc),
-		aggStageReportedBetween(todayStartAt.Unix(), tomorrowStartAt.Unix()),
+		area,
+		aggStageReportedBetween(start, end),
 		{
 			"$group": bson.M{
 				"_id": "$profile_id",
@@ -70,3 +82,116 @@ func (m *mongoDB) GetNearbyReportingUserCount(reportType schema.ReportType, dist
 
 	return result.Count, nil
 }
+
+// nearbyReportActivity returns the number of users who have reported symptoms or behaviors and
+// the number of their reports in the specified area and within the specified time range.
+func (m *mongoDB) nearbyReportActivity(dist int, loc schema.Location, start, end int64) (int, int, error) {
+	return m.reportActivity(aggStageGeoProximity(dist, loc), start, end)
+}
+
+// reportActivity returns the number of users who have reported symptoms or behaviors and the number
+// of their reports in the area matched by the given stage and within the specified time range.
+func (m *mongoDB) reportActivity(area bson.M, start, end int64) (int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	reporters := make(map[string]struct{})
+	reports := 0
+	for _, collection := range []string{schema.SymptomReportCollection, schema.BehaviorReportCollection} {
+		pipeline := []bson.M{
+			area,
+			aggStageReportedBetween(start, end),
+			{
+				"$group": bson.M{
+					"_id": "$profile_id",
+					"count": bson.M{
+						"$sum": 1,
+					},
+				},
+			},
+		}
+		cursor, err := m.client.Database(m.database).Collection(collection).Aggregate(ctx, pipeline)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for cursor.Next(ctx) {
+			var aggItem struct {
+				ProfileID string `bson:"_id"`
+				Count     int    `bson:"count"`
+			}
+			if err := cursor.Decode(&aggItem); err != nil {
+				cursor.Close(ctx)
+				return 0, 0, err
+			}
+			reporters[aggItem.ProfileID] = struct{}{}
+			reports += aggItem.Count
+		}
+		cursor.Close(ctx)
+	}
+
+	return len(reporters), reports, nil
+}
+
+// weightedReportingUserCount returns the number of users who have reported symptoms/behaviors
+// in the area matched by the given stage and within the specified time range. If halfLife is
+// positive, a user is counted by the weight of the latest report, which halves every halfLife
+// before the end of the time range.
+func (m *mongoDB) weightedReportingUserCount(reportType schema.ReportType, area bson.M, start, end int64, halfLife time.Duration) (float64, error) {
+	var c *mongo.Collection
+	switch reportType {
+	case schema.ReportTypeSymptom:
+		c = m.client.Database(m.database).Collection(schema.SymptomReportCollection)
+	case schema.ReportTypeBehavior:
+		c = m.client.Database(m.database).Collection(schema.BehaviorReportCollection)
+	default:
+		return 0, errors.New("invalid report type")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	pipeline := []bson.M{
+		area,
+		aggStageReportedBetween(start, end),
+		{
+			"$project": bson.M{
+				"profile_id": 1,
+				"weight":     aggExprTimeDecayWeight(end, halfLife),
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": "$profile_id",
+				"weight": bson.M{
+					"$max": "$weight",
+				},
+			},
+		}, // for each user, the weight of the latest report
+		{
+			"$group": bson.M{
+				"_id": nil,
+				"count": bson.M{
+					"$sum": "$weight",
+				},
+			},
+		},
+	}
+	cursor, err := c.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		return 0, nil
+	}
+
+	var result struct {
+		Count float64 `bson:"count"`
+	}
+	if err := cursor.Decode(&result); err != nil {
+		return 0, err
+	}
+
+	return result.Count, nil
+}
@@ -29,9 +29,10 @@ type Symptom interface {
 	ListCustomizedSymptoms() ([]schema.Symptom, error)
 	SymptomReportSave(data *schema.SymptomReportData) error
 	FindSymptomsByIDs(ids []string) ([]schema.Symptom, error)
-	FindNearbySymptomDistribution(dist int, loc schema.Location, start, end int64) (schema.SymptomDistribution, error)
+	FindNearbySymptomDistribution(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (schema.SymptomDistribution, error)
+	FindNearbyDailySymptomDistributions(dist int, loc schema.Location, start int64, days int) ([]schema.SymptomDistribution, error)
 	FindNearbyNonOfficialSymptoms(dist int, loc schema.Location) ([]schema.Symptom, error)
-	GetSymptomCount(profileID string, loc *schema.Location, dist int, now time.Time) (int, int, error)
+	GetSymptomCount(profileID string, loc *schema.Location, dist int, now time.Time, tz *time.Location) (int, int, error)
 }
 
 func (m *mongoDB) CreateSymptom(symptom schema.Symptom) (string, error) {
@@ -230,18 +231,105 @@ func (m *mongoDB) FindSymptomsByIDs(ids []string) ([]schema.Symptom, error) {
 // | userB | [fever] 			    |
 //
 // symptom_distribution = {fever: 2, cough: 1, nasal: 1}
-func (m *mongoDB) FindNearbySymptomDistribution(dist int, loc schema.Location, start, end int64) (schema.SymptomDistribution, error) {
+//
+// If halfLife is positive, a user is counted by the weight of the latest report of the symptom,
+// which halves every halfLife before the end of the time range.
+func (m *mongoDB) FindNearbySymptomDistribution(dist int, loc schema.Location, start, end int64, halfLife time.Duration) (schema.SymptomDistribution, error) {
+	return m.symptomDistribution(aggStageGeoProximity(dist, loc), start, end, halfLife)
+}
+
+// symptomDistribution returns the symptom distribution of the reports in the area matched by the given stage
+func (m *mongoDB) symptomDistribution(area bson.M, start, end int64, halfLife time.Duration) (schema.SymptomDistribution, error) {
 	c := m.client.Database(m.database).Collection(schema.SymptomReportCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
 	pipeline := []bson.M{
-		aggStageGeoProximity(dist, loc),
+		area,
 		aggStageReportedBetween(start, end),
 		{
 			"$project": bson.M{
 				"profile_id":     1,
 				"account_number": 1,
+				"weight":         aggExprTimeDecayWeight(end, halfLife),
+				"symptoms": bson.M{
+					"$concatArrays": bson.A{
+						bson.M{"$ifNull": bson.A{"$official_symptoms", bson.A{}}},
+						bson.M{"$ifNull": bson.A{"$customized_symptoms", bson.A{}}},
+						bson.M{"$ifNull": bson.A{"$symptoms", bson.A{}}},
+					},
+				},
+			},
+		},
+		{
+			"$unwind": bson.M{
+				"path":                       "$symptoms",
+				"preserveNullAndEmptyArrays": false,
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{"profile_id": "$profile_id", "symptom": "$symptoms._id"},
+				"weight": bson.M{
+					"$max": "$weight",
+				},
+			},
+		}, // for each user, the types of symptoms reported and the weight of the latest report
+		{
+			"$group": bson.M{
+				"_id": "$_id.symptom",
+				"count": bson.M{
+					"$sum": "$weight",
+				},
+			},
+		}, // for each symptom, the weighted number of users who have reported it
+	}
+
+	cursor, err := c.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var aggItem struct {
+		SymptomID string  `bson:"_id"`
+		Count     float64 `bson:"count"`
+	}
+	result := make(schema.SymptomDistribution)
+	for cursor.Next(ctx) {
+		if err := cursor.Decode(&aggItem); err != nil {
+			return nil, err
+		}
+		result[aggItem.SymptomID] = aggItem.Count
+	}
+
+	return result, nil
+}
+
+// FindNearbyDailySymptomDistributions returns the symptom distributions of consecutive days
+// starting at the given time. The distributions are ordered from the earliest day.
+func (m *mongoDB) FindNearbyDailySymptomDistributions(dist int, loc schema.Location, start int64, days int) ([]schema.SymptomDistribution, error) {
+	return m.dailySymptomDistributions(aggStageGeoProximity(dist, loc), start, days)
+}
+
+// dailySymptomDistributions returns the daily symptom distributions of the reports in the area matched by the given stage
+func (m *mongoDB) dailySymptomDistributions(area bson.M, start int64, days int) ([]schema.SymptomDistribution, error) {
+	c := m.client.Database(m.database).Collection(schema.SymptomReportCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	secondsOfDay := int64(24 * time.Hour / time.Second)
+	end := start + int64(days)*secondsOfDay
+
+	pipeline := []bson.M{
+		area,
+		aggStageReportedBetween(start, end),
+		{
+			"$project": bson.M{
+				"profile_id": 1,
+				"day": bson.M{
+					"$floor": bson.M{
+						"$divide": bson.A{bson.M{"$subtract": bson.A{"$ts", start}}, secondsOfDay},
+					},
+				},
 				"symptoms": bson.M{
 					"$concatArrays": bson.A{
 						bson.M{"$ifNull": bson.A{"$official_symptoms", bson.A{}}},
@@ -259,12 +347,12 @@ func (m *mongoDB) FindNearbySymptomDistribution(dist int, loc schema.Location, s
 		},
 		{
 			"$group": bson.M{
-				"_id": "$profile_id",
+				"_id": bson.M{"profile_id": "$profile_id", "day": "$day"},
 				"symptoms": bson.M{
 					"$addToSet": "$symptoms",
 				},
 			},
-		}, // for each user, the number of types of symptoms reported
+		}, // for each user and each day, the types of symptoms reported
 		{
 			"$unwind": bson.M{
 				"path":                       "$symptoms",
@@ -273,36 +361,116 @@ func (m *mongoDB) FindNearbySymptomDistribution(dist int, loc schema.Location, s
 		},
 		{
 			"$group": bson.M{
-				"_id": "$symptoms._id",
+				"_id": bson.M{"symptom": "$symptoms._id", "day": "$_id.day"},
 				"count": bson.M{
 					"$sum": 1,
 				},
 			},
-		}, // for each symptom, the number of users who have reported it
+		}, // for each symptom and each day, the number of users who have reported it
 	}
 
 	cursor, err := c.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
-	var aggItem struct {
-		SymptomID string `bson:"_id"`
-		Count     int    `bson:"count"`
+	defer cursor.Close(ctx)
+
+	result := make([]schema.SymptomDistribution, days)
+	for i := range result {
+		result[i] = make(schema.SymptomDistribution)
 	}
-	result := make(map[string]int)
+
 	for cursor.Next(ctx) {
+		var aggItem struct {
+			ID struct {
+				SymptomID string  `bson:"symptom"`
+				Day       float64 `bson:"day"`
+			} `bson:"_id"`
+			Count float64 `bson:"count"`
+		}
 		if err := cursor.Decode(&aggItem); err != nil {
 			return nil, err
 		}
-		result[aggItem.SymptomID] = aggItem.Count
+
+		if day := int(aggItem.ID.Day); day >= 0 && day < days {
+			result[day][aggItem.ID.SymptomID] = aggItem.Count
+		}
+	}
+
+	return result, nil
+}
+
+// dailySymptomReporters returns the daily numbers of users who have reported symptoms
+// in the area matched by the given stage
+func (m *mongoDB) dailySymptomReporters(area bson.M, start int64, days int) ([]int, error) {
+	c := m.client.Database(m.database).Collection(schema.SymptomReportCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	secondsOfDay := int64(24 * time.Hour / time.Second)
+	end := start + int64(days)*secondsOfDay
+
+	pipeline := []bson.M{
+		area,
+		aggStageReportedBetween(start, end),
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"profile_id": "$profile_id",
+					"day": bson.M{
+						"$floor": bson.M{
+							"$divide": bson.A{bson.M{"$subtract": bson.A{"$ts", start}}, secondsOfDay},
+						},
+					},
+				},
+			},
+		}, // for each day, the users who have reported
+		{
+			"$group": bson.M{
+				"_id": "$_id.day",
+				"count": bson.M{
+					"$sum": 1,
+				},
+			},
+		},
+	}
+
+	cursor, err := c.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]int, days)
+	for cursor.Next(ctx) {
+		var aggItem struct {
+			Day   float64 `bson:"_id"`
+			Count int     `bson:"count"`
+		}
+		if err := cursor.Decode(&aggItem); err != nil {
+			return nil, err
+		}
+
+		if day := int(aggItem.Day); day >= 0 && day < days {
+			result[day] = aggItem.Count
+		}
 	}
 
 	return result, nil
 }
 
 // FindNearbyNonOfficialSymptoms returns non-official symptoms reported today in the specified area.
+// Symptoms reported by too few users are guarded by the privacy guard, and noise is added if it is enabled.
 func (m *mongoDB) FindNearbyNonOfficialSymptoms(dist int, loc schema.Location) ([]schema.Symptom, error) {
-	distribution, err := m.FindNearbySymptomDistribution(dist, loc, 0, 9223372036854775807)
+	distribution, err := m.FindNearbySymptomDistribution(dist, loc, 0, 9223372036854775807, 0)
+	if err != nil {
+		return nil, err
+	}
+	reporters, err := m.nearbyReportingUserCount(schema.ReportTypeSymptom, dist, loc, 0, 9223372036854775807)
+	if err != nil {
+		return nil, err
+	}
+	distribution, err = m.guardDistribution(distribution, reporters, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -335,14 +503,14 @@ func (m *mongoDB) FindNearbyNonOfficialSymptoms(dist int, loc schema.Location) (
 	return symptoms, nil
 }
 
-// GetSymptomCount returns the number of reported symptoms for today and yesterday.
+// GetSymptomCount returns the number of reported symptoms for today and yesterday in the given location.
 //
 // Either profileID of loc is required.
 // If profileID is provided, returned values are personal metrics.
 // Otherwise, if location is provided, returned values are community metrics.
 //
 // Duplicated reported symptoms of a user are seen as one symptom.
-func (m *mongoDB) GetSymptomCount(profileID string, loc *schema.Location, dist int, now time.Time) (int, int, error) {
+func (m *mongoDB) GetSymptomCount(profileID string, loc *schema.Location, dist int, now time.Time, tz *time.Location) (int, int, error) {
 	c := m.client.Database(m.database).Collection(schema.SymptomReportCollection)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -361,7 +529,7 @@ func (m *mongoDB) GetSymptomCount(profileID string, loc *schema.Location, dist i
 		return 0, 0, errors.New("either profile ID or location not provided")
 	}
 
-	yesterdayStartAt, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now)
+	yesterdayStartAt, todayStartAt, tomorrowStartAt := getStartTimeOfConsecutiveDays(now, tz)
 
 	pipeline := []bson.M{
 		filter,
@@ -369,16 +537,7 @@ func (m *mongoDB) GetSymptomCount(profileID string, loc *schema.Location, dist i
 		{
 			"$project": bson.M{
 				"profile_id": 1,
-				"day": bson.M{
-					"$dateToString": bson.M{
-						"format": "%Y-%m-%d",
-						"date": bson.M{
-							"$toDate": bson.M{
-								"$multiply": bson.A{"$ts", 1000},
-							},
-						},
-					},
-				},
+				"day":        aggExprLocalDate(todayStartAt),
 				"symptoms": bson.M{
 					"$concatArrays": bson.A{
 						bson.M{"$ifNull": bson.A{"$official_symptoms", bson.A{}}},
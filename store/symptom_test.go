@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -121,19 +122,19 @@ func (s *SymptomTestSuite) TestGetSymptomCountForIndividual() {
 	store := NewMongoStore(s.mongoClient, s.testDBName)
 
 	now := time.Date(2020, 5, 25, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err := store.GetSymptomCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err := store.GetSymptomCount("userA", nil, 0, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 2, todayCount)
 	assert.Equal(s.T(), 0, yesterdayCount)
 
 	now = time.Date(2020, 5, 26, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetSymptomCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err = store.GetSymptomCount("userA", nil, 0, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 2, todayCount)
 	assert.Equal(s.T(), 2, yesterdayCount)
 
 	now = time.Date(2020, 5, 27, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetSymptomCount("userA", nil, 0, now)
+	todayCount, yesterdayCount, err = store.GetSymptomCount("userA", nil, 0, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 0, todayCount)
 	assert.Equal(s.T(), 2, yesterdayCount)
@@ -149,19 +150,19 @@ func (s *SymptomTestSuite) TestGetSymptomCountForCommunity() {
 	dist := consts.CORHORT_DISTANCE_RANGE
 
 	now := time.Date(2020, 5, 25, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err := store.GetSymptomCount("", loc, dist, now)
+	todayCount, yesterdayCount, err := store.GetSymptomCount("", loc, dist, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 2, todayCount)
 	assert.Equal(s.T(), 0, yesterdayCount)
 
 	now = time.Date(2020, 5, 26, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetSymptomCount("", loc, dist, now)
+	todayCount, yesterdayCount, err = store.GetSymptomCount("", loc, dist, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 4, todayCount)
 	assert.Equal(s.T(), 2, yesterdayCount)
 
 	now = time.Date(2020, 5, 27, 12, 0, 0, 0, time.UTC)
-	todayCount, yesterdayCount, err = store.GetSymptomCount("", loc, dist, now)
+	todayCount, yesterdayCount, err = store.GetSymptomCount("", loc, dist, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 0, todayCount)
 	assert.Equal(s.T(), 4, yesterdayCount)
@@ -180,7 +181,7 @@ func (s *SymptomTestSuite) TestGetNearbyReportingSymptomsUserCount() {
 			Longitude: locationBitmark.Coordinates[0],
 			Latitude:  locationBitmark.Coordinates[1],
 		},
-		now)
+		now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 2, count)
 
@@ -191,11 +192,123 @@ func (s *SymptomTestSuite) TestGetNearbyReportingSymptomsUserCount() {
 		schema.Location{
 			Longitude: locationTaipeiTrainStation.Coordinates[0],
 			Latitude:  locationTaipeiTrainStation.Coordinates[1],
-		}, now)
+		}, now, time.UTC)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), 1, count)
 }
 
+func (s *SymptomTestSuite) TestSymptomDistributionWithinRegion() {
+	store := NewMongoStore(s.mongoClient, s.testDBName).(*mongoDB)
+
+	// a boundary around Nangang which excludes Taipei train station
+	if _, err := s.testDatabase.Collection(schema.BoundaryCollection).InsertOne(context.Background(), schema.Boundary{
+		Country: "Taiwan",
+		County:  "Nangang",
+		Geometry: schema.Geometry{
+			Type: "Polygon",
+			Coordinates: [][][]float64{{
+				{121.59, 25.03},
+				{121.63, 25.03},
+				{121.63, 25.07},
+				{121.59, 25.07},
+				{121.59, 25.03},
+			}},
+		},
+	}); err != nil {
+		s.T().Fatal(err)
+	}
+
+	geometries, err := store.regionGeometries(schema.AddressComponent{Country: "Taiwan", County: "Nangang"})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), geometries, 1)
+
+	geometries, err = store.regionGeometries(schema.AddressComponent{Country: "Taiwan", County: "Wanhua"})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), geometries)
+
+	geometries, err = store.regionGeometries(schema.AddressComponent{Country: "Taiwan"})
+	assert.NoError(s.T(), err)
+	area := aggStageGeoWithin(geometries)
+
+	start := time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC).Unix()
+	end := time.Date(2020, 5, 27, 0, 0, 0, 0, time.UTC).Unix()
+	distribution, err := store.symptomDistribution(area, start, end, 0)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), schema.SymptomDistribution{
+		" cough":           1,
+		" fever":           1,
+		"loss_taste_smell": 1,
+		"new_symptom_1":    1,
+	}, distribution)
+
+	count, err := store.reportingUserCount(schema.ReportTypeSymptom, area, start, end)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+}
+
+func (s *SymptomTestSuite) TestFindNearbyDailySymptomDistributions() {
+	store := NewMongoStore(s.mongoClient, s.testDBName)
+
+	loc := schema.Location{
+		Longitude: locationBitmark.Coordinates[0],
+		Latitude:  locationBitmark.Coordinates[1],
+	}
+	start := time.Date(2020, 5, 25, 0, 0, 0, 0, time.UTC).Unix()
+
+	// a user is counted once a day even if the user reports a symptom twice,
+	// and the report at Taipei train station is out of the area
+	distributions, err := store.FindNearbyDailySymptomDistributions(consts.CORHORT_DISTANCE_RANGE, loc, start, 3)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []schema.SymptomDistribution{
+		{
+			" cough": 1,
+			" fever": 1,
+		},
+		{
+			" cough":           1,
+			" fever":           1,
+			"loss_taste_smell": 1,
+			"new_symptom_1":    1,
+		},
+		{},
+	}, distributions)
+}
+
+func (s *SymptomTestSuite) TestDailySymptomReporters() {
+	store := NewMongoStore(s.mongoClient, s.testDBName).(*mongoDB)
+
+	area := aggStageGeoProximity(consts.CORHORT_DISTANCE_RANGE, schema.Location{
+		Longitude: locationBitmark.Coordinates[0],
+		Latitude:  locationBitmark.Coordinates[1],
+	})
+	start := time.Date(2020, 5, 25, 0, 0, 0, 0, time.UTC).Unix()
+
+	reporters, err := store.dailySymptomReporters(area, start, 3)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{1, 2, 0}, reporters)
+}
+
+func (s *SymptomTestSuite) TestWeightedReportingUserCount() {
+	store := NewMongoStore(s.mongoClient, s.testDBName).(*mongoDB)
+
+	area := aggStageGeoProximity(consts.CORHORT_DISTANCE_RANGE, schema.Location{
+		Longitude: locationBitmark.Coordinates[0],
+		Latitude:  locationBitmark.Coordinates[1],
+	})
+	start := time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC).Unix()
+	end := time.Date(2020, 5, 27, 0, 0, 0, 0, time.UTC).Unix()
+
+	// every user weighs 1 without a half life
+	count, err := store.weightedReportingUserCount(schema.ReportTypeSymptom, area, start, end, 0)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2.0, count)
+
+	// a user weighs as the latest report: userA at 17:00 and userB at 09:00
+	count, err = store.weightedReportingUserCount(schema.ReportTypeSymptom, area, start, end, 12*time.Hour)
+	assert.NoError(s.T(), err)
+	assert.InDelta(s.T(), math.Pow(0.5, 7.0/12)+math.Pow(0.5, 15.0/12), count, 1e-9)
+}
+
 func TestSymptomTestSuite(t *testing.T) {
 	suite.Run(t, NewSymptomTestSuite("mongodb://127.0.0.1:27017/?compressors=disabled", "test-db"))
 }
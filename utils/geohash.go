@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashBox is the bounding box of a geohash cell
+type GeohashBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Center returns the latitude and longitude of the center of the box
+func (b GeohashBox) Center() (float64, float64) {
+	return (b.MinLatitude + b.MaxLatitude) / 2, (b.MinLongitude + b.MaxLongitude) / 2
+}
+
+// GeohashCellSize returns the height in latitude and the width in longitude
+// of the cells of a geohash precision
+func GeohashCellSize(precision int) (float64, float64) {
+	bits := precision * 5
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// GeohashEncode returns the geohash of a location in the given precision
+func GeohashEncode(latitude, longitude float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	var hash strings.Builder
+	bit, ch := 0, 0
+	even := true
+	for hash.Len() < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if longitude >= mid {
+				ch |= 1 << (4 - bit)
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if latitude >= mid {
+				ch |= 1 << (4 - bit)
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// GeohashDecode returns the bounding box of a geohash. It returns false if the geohash is invalid.
+func GeohashDecode(hash string) (GeohashBox, bool) {
+	box := GeohashBox{MinLatitude: -90, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180}
+	if hash == "" {
+		return box, false
+	}
+
+	even := true
+	for _, c := range hash {
+		index := strings.IndexRune(geohashBase32, c)
+		if index < 0 {
+			return box, false
+		}
+
+		for bit := 4; bit >= 0; bit-- {
+			on := index&(1<<bit) != 0
+			if even {
+				mid := (box.MinLongitude + box.MaxLongitude) / 2
+				if on {
+					box.MinLongitude = mid
+				} else {
+					box.MaxLongitude = mid
+				}
+			} else {
+				mid := (box.MinLatitude + box.MaxLatitude) / 2
+				if on {
+					box.MinLatitude = mid
+				} else {
+					box.MaxLatitude = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return box, true
+}
+
+// GeohashesInBox returns the geohashes of the given precision of the cells which overlap the box
+func GeohashesInBox(box GeohashBox, precision int) []string {
+	height, width := GeohashCellSize(precision)
+	rows, columns := int(math.Round(180/height)), int(math.Round(360/width))
+
+	cellIndex := func(v, origin, size float64, count int) int {
+		i := int(math.Floor((v - origin) / size))
+		if i < 0 {
+			return 0
+		}
+		if i >= count {
+			return count - 1
+		}
+		return i
+	}
+
+	minRow, maxRow := cellIndex(box.MinLatitude, -90, height, rows), cellIndex(box.MaxLatitude, -90, height, rows)
+	minColumn, maxColumn := cellIndex(box.MinLongitude, -180, width, columns), cellIndex(box.MaxLongitude, -180, width, columns)
+
+	geohashes := make([]string, 0, (maxRow-minRow+1)*(maxColumn-minColumn+1))
+	for row := minRow; row <= maxRow; row++ {
+		for column := minColumn; column <= maxColumn; column++ {
+			lat := -90 + (float64(row)+0.5)*height
+			lon := -180 + (float64(column)+0.5)*width
+			geohashes = append(geohashes, GeohashEncode(lat, lon, precision))
+		}
+	}
+
+	return geohashes
+}
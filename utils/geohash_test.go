@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohashEncode(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", GeohashEncode(57.64911, 10.40744, 11))
+	assert.Equal(t, "u4pruy", GeohashEncode(57.64911, 10.40744, 6))
+	assert.Equal(t, "ezs42", GeohashEncode(42.605, -5.603, 5))
+}
+
+func TestGeohashDecode(t *testing.T) {
+	box, ok := GeohashDecode("ezs42")
+	assert.True(t, ok)
+	assert.InDelta(t, 42.583, box.MinLatitude, 0.001)
+	assert.InDelta(t, 42.627, box.MaxLatitude, 0.001)
+	assert.InDelta(t, -5.625, box.MinLongitude, 0.001)
+	assert.InDelta(t, -5.581, box.MaxLongitude, 0.001)
+
+	lat, lon := box.Center()
+	assert.Equal(t, "ezs42", GeohashEncode(lat, lon, 5))
+
+	_, ok = GeohashDecode("")
+	assert.False(t, ok)
+	_, ok = GeohashDecode("ezs4a")
+	assert.False(t, ok)
+}
+
+func TestGeohashCellSize(t *testing.T) {
+	height, width := GeohashCellSize(5)
+	assert.InDelta(t, 0.0439453125, height, 1e-12)
+	assert.InDelta(t, 0.0439453125, width, 1e-12)
+
+	height, width = GeohashCellSize(6)
+	assert.InDelta(t, 0.0054931640625, height, 1e-12)
+	assert.InDelta(t, 0.010986328125, width, 1e-12)
+
+	box, _ := GeohashDecode("u4pruy")
+	assert.InDelta(t, height, box.MaxLatitude-box.MinLatitude, 1e-12)
+	assert.InDelta(t, width, box.MaxLongitude-box.MinLongitude, 1e-12)
+}
+
+func TestGeohashesInBox(t *testing.T) {
+	// a box inside a cell
+	box, _ := GeohashDecode("u4pruy")
+	lat, lon := box.Center()
+	assert.Equal(t, []string{"u4pruy"}, GeohashesInBox(GeohashBox{
+		MinLatitude: lat - 0.001, MinLongitude: lon - 0.001, MaxLatitude: lat + 0.001, MaxLongitude: lon + 0.001,
+	}, 6))
+
+	// a box across the edges of cells
+	geohashes := GeohashesInBox(GeohashBox{MinLatitude: -1, MinLongitude: -1, MaxLatitude: 1, MaxLongitude: 1}, 1)
+	assert.ElementsMatch(t, []string{"7", "k", "e", "s"}, geohashes)
+
+	// the whole world
+	geohashes = GeohashesInBox(GeohashBox{MinLatitude: -90, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180}, 1)
+	assert.Len(t, geohashes, 32)
+	assert.ElementsMatch(t, strings.Split(geohashBase32, ""), geohashes)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 )
@@ -37,3 +38,14 @@ func GetLocation(timezone string) *time.Location {
 	locations[timezone] = tz
 	return tz
 }
+
+// GetLocalLocation returns the location of a GMT-X format timezone. If the timezone is
+// not valid, the location is approximated by the nautical time zone of the longitude.
+func GetLocalLocation(timezone string, longitude float64) *time.Location {
+	if tz := GetLocation(timezone); tz != nil {
+		return tz
+	}
+
+	offset := int(math.Round(math.Max(-180, math.Min(180, longitude)) / 15))
+	return GetLocation(fmt.Sprintf("GMT%+d", offset))
+}
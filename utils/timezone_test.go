@@ -31,3 +31,14 @@ func TestGetLocation(t *testing.T) {
 	assert.NotNil(t, tz_945)
 	assert.Equal(t, "GMT-9:45", tz_945.String())
 }
+
+func TestGetLocalLocation(t *testing.T) {
+	assert.Equal(t, "GMT-9:45", GetLocalLocation("GMT-9:45", 121.5).String())
+
+	// Taipei
+	assert.Equal(t, "GMT+8", GetLocalLocation("", 121.5).String())
+	// New York
+	assert.Equal(t, "GMT-5", GetLocalLocation("unknown", -74).String())
+	assert.Equal(t, "GMT+0", GetLocalLocation("", -7.4).String())
+	assert.Equal(t, "GMT+12", GetLocalLocation("", 180).String())
+}